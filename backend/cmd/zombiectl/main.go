@@ -0,0 +1,169 @@
+// Command zombiectl runs operational tasks against the game database: schema
+// migrations, development fixtures and player administration. It reads the same
+// configuration as the server, from CONFIG_FILE, the environment and .env.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/joho/godotenv"
+	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/cache"
+	"zombie-car-game-backend/internal/config"
+	"zombie-car-game-backend/internal/database"
+	"zombie-car-game-backend/internal/logging"
+)
+
+// errUsage reports a command line mistake; the command's usage has already been printed
+var errUsage = errors.New("invalid usage")
+
+// env is what a command runs against
+type env struct {
+	cfg    *config.Config
+	db     *gorm.DB
+	jwt    *auth.JWTService
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command is a zombiectl subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(e *env, args []string) error
+}
+
+var commands = []command{
+	{"migrate", "Apply, roll back or list schema migrations (up, down, status)", runMigrate},
+	{"seed", "Create development players with vehicles, sessions and progress", runSeed},
+	{"grant-currency", "Credit or debit a player's wallet", runGrantCurrency},
+	{"reset-progress", "Put a player back at level 1 with no level progress", runResetProgress},
+	{"ban", "Ban a player, or suspend them with -duration", runBan},
+	{"rebuild-leaderboards", "Rebuild the leaderboards from players' level progress", runRebuildLeaderboards},
+}
+
+func main() {
+	flags := flag.NewFlagSet("zombiectl", flag.ExitOnError)
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "YAML configuration file")
+	flags.Usage = func() { usage(flags) }
+	flags.Parse(os.Args[1:])
+
+	if flags.NArg() == 0 {
+		usage(flags)
+		os.Exit(2)
+	}
+
+	name, args := flags.Arg(0), flags.Args()[1:]
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+
+		e, err := connect(*configFile)
+		if err != nil {
+			exit(err)
+		}
+		err = cmd.run(e, args)
+		if closeErr := database.Close(); closeErr != nil {
+			slog.Warn("Error closing database", "error", closeErr)
+		}
+		cache.Close()
+		exit(err)
+	}
+
+	fmt.Fprintf(os.Stderr, "zombiectl: unknown command %q\n\n", name)
+	usage(flags)
+	os.Exit(2)
+}
+
+// connect loads the configuration and opens the database. Redis is optional,
+// as it is for the server; it is only used to revoke access tokens.
+func connect(configFile string) (*env, error) {
+	godotenv.Load()
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Logs go to stderr so command output can be piped
+	logging.Setup(cfg.LoggingConfig(), os.Stderr)
+
+	keys, err := auth.LoadKeySet(cfg.KeyConfig())
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT key configuration: %w", err)
+	}
+
+	if err := database.Connect(cfg.DatabaseConfig()); err != nil {
+		return nil, err
+	}
+	if err := cache.Connect(cfg.CacheConfig()); err != nil {
+		slog.Warn("Failed to connect to Redis, continuing without it", "error", err)
+	}
+	jwtService := auth.NewJWTServiceWithKeys(keys, cfg.Auth.AccessTokenTTL, auth.NewDenylist(cache.GetClient()))
+
+	return &env{cfg: cfg, db: database.GetDB(), jwt: jwtService, stdout: os.Stdout, stderr: os.Stderr}, nil
+}
+
+func usage(flags *flag.FlagSet) {
+	out := flags.Output()
+	fmt.Fprintln(out, "Usage: zombiectl [-config file] <command> [flags]")
+	fmt.Fprintln(out, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-22s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(out, "\nRun zombiectl <command> -h for a command's flags.")
+	fmt.Fprintln(out, "\nGlobal flags:")
+	flags.PrintDefaults()
+}
+
+// exit ends the process, reporting err if there is one
+func exit(err error) {
+	os.Exit(exitCode(err, os.Stderr))
+}
+
+// exitCode returns the exit status for a command's result, reporting err to
+// stderr if there is one. Usage mistakes exit with 2, other failures with 1.
+func exitCode(err error, stderr io.Writer) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errUsage), errors.Is(err, flag.ErrHelp):
+		return 2
+	default:
+		fmt.Fprintf(stderr, "zombiectl: %v\n", err)
+		return 1
+	}
+}
+
+// parseFlags parses a command's flags, printing its usage on mistakes
+func (e *env) parseFlags(flags *flag.FlagSet, args []string) error {
+	flags.SetOutput(e.stderr)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(flags.Output(), "unexpected arguments: %v\n", flags.Args())
+		flags.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+// required checks that each named string flag was given
+func required(flags *flag.FlagSet, names ...string) error {
+	for _, name := range names {
+		if flags.Lookup(name).Value.String() == "" {
+			fmt.Fprintf(flags.Output(), "-%s is required\n", name)
+			flags.Usage()
+			return errUsage
+		}
+	}
+	return nil
+}
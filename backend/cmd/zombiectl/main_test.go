@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/config"
+	"zombie-car-game-backend/internal/database"
+	"zombie-car-game-backend/internal/database/dbtest"
+	"zombie-car-game-backend/internal/models"
+)
+
+// newTestEnv returns an environment on an empty database of its own, migrated
+// with zombiectl migrate up as an operator would
+func newTestEnv(t *testing.T) *env {
+	t.Helper()
+
+	db := dbtest.Open(t)
+	// migrate up brings the models' schema through the connected database
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+
+	e := &env{cfg: config.Default(), db: db, jwt: auth.NewJWTService()}
+	code, _, stderr := runZombiectl(e, "migrate", "up")
+	require.Equal(t, 0, code, stderr)
+	return e
+}
+
+// runZombiectl runs a command as main would and returns its exit code and output
+func runZombiectl(e *env, name string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	e.stdout, e.stderr = &stdout, &stderr
+
+	for _, cmd := range commands {
+		if cmd.name == name {
+			code := exitCode(cmd.run(e, args), &stderr)
+			return code, stdout.String(), stderr.String()
+		}
+	}
+	panic("unknown command " + name)
+}
+
+// createStaff creates a staff account commands can be attributed to
+func createStaff(t *testing.T, e *env, username, role string) *models.Player {
+	t.Helper()
+	staff := &models.Player{
+		Username:     username,
+		Email:        username + "@example.com",
+		PasswordHash: "hashedpassword",
+		Role:         role,
+	}
+	require.NoError(t, e.db.Create(staff).Error)
+	return staff
+}
+
+func TestRequiredFlags(t *testing.T) {
+	e := newTestEnv(t)
+
+	code, stdout, stderr := runZombiectl(e, "reset-progress", "-player", "someone")
+	assert.Equal(t, 2, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "-reason is required")
+
+	code, _, stderr = runZombiectl(e, "reset-progress", "-player", "someone", "extra")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "unexpected arguments: [extra]")
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"text/tabwriter"
+
+	"zombie-car-game-backend/internal/database"
+	"zombie-car-game-backend/migrations"
+)
+
+func runMigrate(e *env, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintln(e.stderr, "Usage: zombiectl migrate up|down|status [flags]")
+		return errUsage
+	}
+
+	// The SQL migrations are written for Postgres; SQLite only gets the models' schema
+	sqlMigrations := e.db.Dialector.Name() == "postgres"
+	if !sqlMigrations && args[0] != "up" {
+		return fmt.Errorf("migrate %s needs Postgres; SQLite databases are migrated from the models", args[0])
+	}
+
+	migrator := database.NewMigrator(e.db, migrations.Files)
+	switch args[0] {
+	case "up":
+		flags := flag.NewFlagSet("migrate up", flag.ContinueOnError)
+		if err := e.parseFlags(flags, args[1:]); err != nil {
+			return err
+		}
+
+		applied := 0
+		if sqlMigrations {
+			var err error
+			if applied, err = migrator.Up(); err != nil {
+				return err
+			}
+		}
+		// Bring the schema to where the server would leave it on startup
+		if err := database.AutoMigrate(); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "Applied %d migration(s)\n", applied)
+		return nil
+
+	case "down":
+		flags := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+		to := flags.String("to", "", "roll back every migration after this version")
+		steps := flags.Int("steps", 1, "number of migrations to roll back when -to is not given")
+		all := flags.Bool("all", false, "roll back every migration")
+		if err := e.parseFlags(flags, args[1:]); err != nil {
+			return err
+		}
+
+		target := *to
+		if target == "" && !*all {
+			var err error
+			if target, err = stepsTarget(migrator, *steps); err != nil {
+				return err
+			}
+		}
+
+		rolledBack, err := migrator.Down(target)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "Rolled back %d migration(s)\n", rolledBack)
+		return nil
+
+	case "status":
+		flags := flag.NewFlagSet("migrate status", flag.ContinueOnError)
+		if err := e.parseFlags(flags, args[1:]); err != nil {
+			return err
+		}
+
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(e.stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, status := range statuses {
+			state, appliedAt := "pending", ""
+			if status.Applied {
+				state = "applied"
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			switch {
+			case status.Missing:
+				state = "applied, file missing"
+			case status.Modified:
+				state = "applied, file modified"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+		}
+		return w.Flush()
+
+	default:
+		fmt.Fprintf(e.stderr, "unknown migrate subcommand %q; expected up, down or status\n", args[0])
+		return errUsage
+	}
+}
+
+// stepsTarget returns the version to roll back to in order to undo the last steps applied migrations
+func stepsTarget(migrator *database.Migrator, steps int) (string, error) {
+	if steps < 1 {
+		return "", fmt.Errorf("-steps must be at least 1")
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		return "", err
+	}
+	var applied []string
+	for _, status := range statuses {
+		if status.Applied {
+			applied = append(applied, status.Version)
+		}
+	}
+
+	if steps >= len(applied) {
+		return "", nil
+	}
+	return applied[len(applied)-steps-1], nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"zombie-car-game-backend/internal/models"
+)
+
+func TestMigrate(t *testing.T) {
+	e := newTestEnv(t)
+	assert.True(t, e.db.Migrator().HasTable(&models.Player{}))
+	assert.True(t, e.db.Migrator().HasTable(&models.WalletTransaction{}))
+
+	// Running up again applies nothing
+	code, stdout, stderr := runZombiectl(e, "migrate", "up")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "Applied 0 migration(s)\n", stdout)
+
+	code, stdout, stderr = runZombiectl(e, "migrate")
+	assert.Equal(t, 2, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "Usage: zombiectl migrate up|down|status")
+
+	code, stdout, stderr = runZombiectl(e, "migrate", "status")
+	if e.db.Dialector.Name() == "postgres" {
+		assert.Equal(t, 0, code, stderr)
+		assert.Contains(t, stdout, "initial_schema")
+		assert.NotContains(t, stdout, "pending")
+		return
+	}
+	// SQLite databases only get the models' schema
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "zombiectl: migrate status needs Postgres")
+}
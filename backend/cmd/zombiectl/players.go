@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+	"zombie-car-game-backend/internal/services"
+)
+
+func runGrantCurrency(e *env, args []string) error {
+	flags := flag.NewFlagSet("grant-currency", flag.ContinueOnError)
+	player := flags.String("player", "", "player ID or username")
+	amount := flags.Int("amount", 0, "amount to credit; negative amounts debit")
+	currency := flags.String("currency", string(models.CurrencySoft), "soft or premium")
+	reason := flags.String("reason", "", "reason recorded in the audit log")
+	actor := flags.String("actor", "", "username of the staff member making the change")
+	if err := e.parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := required(flags, "player", "reason", "actor"); err != nil {
+		return err
+	}
+	if *amount == 0 {
+		return fmt.Errorf("-amount must not be zero")
+	}
+	if *currency != string(models.CurrencySoft) && *currency != string(models.CurrencyPremium) {
+		return fmt.Errorf("-currency must be soft or premium")
+	}
+
+	staff, err := staffActor(e.db, *actor, auth.PermissionEditPlayers, "grant-currency")
+	if err != nil {
+		return err
+	}
+	playerID, err := resolvePlayer(e.db, *player)
+	if err != nil {
+		return err
+	}
+
+	balance, err := services.NewAdminService(e.db, e.jwt, e.cfg.Auth.RefreshTokenTTL).AdjustCurrency(staff, playerID, services.AdjustCurrencyRequest{
+		Currency: models.CurrencyType(*currency),
+		Amount:   *amount,
+		Reason:   *reason,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "Player %d now has %d %s currency\n", playerID, balance, *currency)
+	return nil
+}
+
+func runResetProgress(e *env, args []string) error {
+	flags := flag.NewFlagSet("reset-progress", flag.ContinueOnError)
+	player := flags.String("player", "", "player ID or username")
+	reason := flags.String("reason", "", "reason recorded in the audit log")
+	actor := flags.String("actor", "", "username of the staff member making the change")
+	if err := e.parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := required(flags, "player", "reason", "actor"); err != nil {
+		return err
+	}
+
+	staff, err := staffActor(e.db, *actor, auth.PermissionEditPlayers, "reset-progress")
+	if err != nil {
+		return err
+	}
+	playerID, err := resolvePlayer(e.db, *player)
+	if err != nil {
+		return err
+	}
+
+	reset, err := services.NewAdminService(e.db, e.jwt, e.cfg.Auth.RefreshTokenTTL).ResetProgress(staff, playerID, services.ResetProgressRequest{Reason: *reason})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "Reset progress of %s (player %d)\n", reset.Username, reset.ID)
+	return nil
+}
+
+func runBan(e *env, args []string) error {
+	flags := flag.NewFlagSet("ban", flag.ContinueOnError)
+	player := flags.String("player", "", "player ID or username")
+	reason := flags.String("reason", "", "reason shown to the player and recorded in the audit log")
+	duration := flags.Duration("duration", 0, "suspend for this long instead of banning permanently, e.g. 72h")
+	actor := flags.String("actor", "", "username of the staff member issuing the sanction")
+	if err := e.parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := required(flags, "player", "reason", "actor"); err != nil {
+		return err
+	}
+	if *duration < 0 {
+		return fmt.Errorf("-duration must not be negative")
+	}
+
+	staff, err := staffActor(e.db, *actor, auth.PermissionSanction, "ban")
+	if err != nil {
+		return err
+	}
+	playerID, err := resolvePlayer(e.db, *player)
+	if err != nil {
+		return err
+	}
+
+	req := services.IssueSanctionRequest{Type: models.SanctionBan, Reason: *reason}
+	if *duration > 0 {
+		expiresAt := time.Now().Add(*duration)
+		req.Type = models.SanctionSuspension
+		req.ExpiresAt = &expiresAt
+	}
+
+	sanction, err := services.NewSanctionService(e.db, e.jwt, e.cfg.Auth.RefreshTokenTTL).IssueSanction(staff, playerID, req)
+	if err != nil {
+		return err
+	}
+	if sanction.ExpiresAt != nil {
+		fmt.Fprintf(e.stdout, "Suspended player %d until %s (sanction %d)\n",
+			playerID, sanction.ExpiresAt.Format(time.RFC3339), sanction.ID)
+	} else {
+		fmt.Fprintf(e.stdout, "Banned player %d (sanction %d)\n", playerID, sanction.ID)
+	}
+	return nil
+}
+
+func runRebuildLeaderboards(e *env, args []string) error {
+	flags := flag.NewFlagSet("rebuild-leaderboards", flag.ContinueOnError)
+	if err := e.parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	entries, err := services.NewLeaderboardService(e.db).Rebuild()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "Rebuilt leaderboards with %d entries\n", entries)
+	return nil
+}
+
+// staffActor resolves the staff account a change is attributed to in the
+// audit log, and checks it holds the permission the admin API would require
+func staffActor(db *gorm.DB, username string, permission auth.Permission, command string) (services.AdminActor, error) {
+	var staff models.Player
+	if err := db.Where("LOWER(username) = ?", strings.ToLower(username)).First(&staff).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return services.AdminActor{}, fmt.Errorf("actor %q not found", username)
+		}
+		return services.AdminActor{}, fmt.Errorf("database error: %w", err)
+	}
+	if !auth.HasPermission(staff.Role, permission) {
+		return services.AdminActor{}, fmt.Errorf("actor %q (%s) lacks the %s permission", username, staff.Role, permission)
+	}
+
+	return services.AdminActor{PlayerID: staff.ID, RequestID: "zombiectl:" + command}, nil
+}
+
+// resolvePlayer looks a player up by ID or username
+func resolvePlayer(db *gorm.DB, ref string) (uint, error) {
+	var player models.Player
+	query := db.Where("LOWER(username) = ?", strings.ToLower(ref))
+	if id, err := strconv.ParseUint(ref, 10, 64); err == nil {
+		query = db.Where("id = ?", id)
+	}
+
+	if err := query.First(&player).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("player %q not found", ref)
+		}
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	return player.ID, nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+	"zombie-car-game-backend/internal/services"
+)
+
+// seedOne seeds a single player and returns it
+func seedOne(t *testing.T, e *env) *models.Player {
+	t.Helper()
+	code, _, stderr := runZombiectl(e, "seed", "-players", "1")
+	require.Equal(t, 0, code, stderr)
+
+	var player models.Player
+	require.NoError(t, e.db.Where("username = ?", "seed_player_01").First(&player).Error)
+	return &player
+}
+
+func TestGrantCurrency(t *testing.T) {
+	e := newTestEnv(t)
+	createStaff(t, e, "admin", auth.RoleAdmin)
+	createStaff(t, e, "helper", auth.RoleSupport)
+	player := seedOne(t, e)
+
+	code, stdout, stderr := runZombiectl(e, "grant-currency",
+		"-player", "seed_player_01", "-amount", "250", "-reason", "outage compensation", "-actor", "admin")
+	require.Equal(t, 0, code, stderr)
+	assert.Equal(t, fmt.Sprintf("Player %d now has %d soft currency\n", player.ID, player.Currency+250), stdout)
+
+	code, stdout, stderr = runZombiectl(e, "grant-currency",
+		"-player", fmt.Sprint(player.ID), "-amount", "5", "-currency", "premium", "-reason", "goodwill", "-actor", "admin")
+	require.Equal(t, 0, code, stderr)
+	assert.Equal(t, fmt.Sprintf("Player %d now has 5 premium currency\n", player.ID), stdout)
+
+	var entries int64
+	e.db.Model(&models.WalletTransaction{}).Where("player_id = ?", player.ID).Count(&entries)
+	assert.Equal(t, int64(2), entries)
+
+	for _, tc := range []struct {
+		args   []string
+		code   int
+		stderr string
+	}{
+		{[]string{"-player", "seed_player_01", "-amount", "5", "-actor", "admin"}, 2, "-reason is required"},
+		{[]string{"-player", "seed_player_01", "-amount", "0", "-reason", "r", "-actor", "admin"}, 1, "zombiectl: -amount must not be zero"},
+		{[]string{"-player", "seed_player_01", "-amount", "5", "-currency", "gems", "-reason", "r", "-actor", "admin"}, 1, "-currency must be soft or premium"},
+		{[]string{"-player", "seed_player_01", "-amount", "5", "-reason", "r", "-actor", "helper"}, 1, `actor "helper" (support) lacks the players:edit permission`},
+		{[]string{"-player", "seed_player_01", "-amount", "5", "-reason", "r", "-actor", "nobody"}, 1, `actor "nobody" not found`},
+		{[]string{"-player", "ghost", "-amount", "5", "-reason", "r", "-actor", "admin"}, 1, `player "ghost" not found`},
+		{[]string{"-player", "seed_player_01", "-amount", "-1000000", "-reason", "r", "-actor", "admin"}, 1, "insufficient funds"},
+	} {
+		code, stdout, stderr := runZombiectl(e, "grant-currency", tc.args...)
+		assert.Equal(t, tc.code, code, tc.args)
+		assert.Empty(t, stdout, tc.args)
+		assert.Contains(t, stderr, tc.stderr, tc.args)
+	}
+}
+
+func TestResetProgress(t *testing.T) {
+	e := newTestEnv(t)
+	createStaff(t, e, "moderator", auth.RoleModerator)
+	player := seedOne(t, e)
+
+	code, stdout, stderr := runZombiectl(e, "reset-progress", "-player", "seed_player_01", "-reason", "corrupted save", "-actor", "moderator")
+	require.Equal(t, 0, code, stderr)
+	assert.Equal(t, fmt.Sprintf("Reset progress of seed_player_01 (player %d)\n", player.ID), stdout)
+
+	var reset models.Player
+	require.NoError(t, e.db.First(&reset, player.ID).Error)
+	assert.Equal(t, 1, reset.Level)
+	assert.Zero(t, reset.TotalScore)
+	var progress int64
+	e.db.Model(&models.LevelProgress{}).Where("player_id = ?", player.ID).Count(&progress)
+	assert.Zero(t, progress)
+}
+
+func TestBan(t *testing.T) {
+	e := newTestEnv(t)
+	createStaff(t, e, "moderator", auth.RoleModerator)
+	playerService := services.NewPlayerService(e.db, e.jwt, services.AccountConfig{
+		RefreshTokenTTL: e.cfg.Auth.RefreshTokenTTL,
+		AppBaseURL:      e.cfg.Mail.AppBaseURL,
+		TOTPIssuer:      e.cfg.Auth.TOTPIssuer,
+	})
+	registered, err := playerService.CreatePlayer(services.CreatePlayerRequest{
+		Username: "cheater",
+		Email:    "cheater@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+	playerID := registered.Player.ID
+
+	code, stdout, stderr := runZombiectl(e, "ban", "-player", "cheater", "-reason", "speed hacking", "-actor", "moderator")
+	require.Equal(t, 0, code, stderr)
+	var ban models.Sanction
+	require.NoError(t, e.db.Where("player_id = ?", playerID).First(&ban).Error)
+	assert.Equal(t, models.SanctionBan, ban.Type)
+	assert.Equal(t, fmt.Sprintf("Banned player %d (sanction %d)\n", playerID, ban.ID), stdout)
+
+	// The player's access token stops working at once, not when it expires
+	_, err = e.jwt.ValidateToken(registered.Token)
+	assert.ErrorIs(t, err, auth.ErrRevokedToken)
+	_, err = playerService.RefreshToken(registered.RefreshToken, services.DeviceInfo{})
+	assert.ErrorIs(t, err, services.ErrInvalidRefreshToken)
+
+	code, stdout, stderr = runZombiectl(e, "ban", "-player", "cheater", "-reason", "griefing", "-duration", "72h", "-actor", "moderator")
+	require.Equal(t, 0, code, stderr)
+	assert.Regexp(t, fmt.Sprintf(`^Suspended player %d until \S+ \(sanction \d+\)\n$`, playerID), stdout)
+
+	code, _, stderr = runZombiectl(e, "ban", "-player", "cheater", "-reason", "r", "-duration", "-1h", "-actor", "moderator")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "-duration must not be negative")
+
+	code, _, stderr = runZombiectl(e, "ban", "-player", "moderator", "-reason", "r", "-actor", "moderator")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, services.ErrInvalidSanction.Error(), "staff cannot ban themselves")
+}
+
+func TestRebuildLeaderboards(t *testing.T) {
+	e := newTestEnv(t)
+	seedOne(t, e)
+
+	code, stdout, stderr := runZombiectl(e, "rebuild-leaderboards")
+	if e.db.Dialector.Name() != "postgres" {
+		assert.Equal(t, 1, code)
+		assert.Empty(t, stdout)
+		assert.Contains(t, stderr, "zombiectl: "+services.ErrLeaderboardsUnsupported.Error())
+		return
+	}
+
+	require.Equal(t, 0, code, stderr)
+	var progress int64
+	e.db.Model(&models.LevelProgress{}).Count(&progress)
+	assert.Equal(t, fmt.Sprintf("Rebuilt leaderboards with %d entries\n", progress), stdout)
+}
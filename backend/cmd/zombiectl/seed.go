@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+)
+
+// seedVehicles are handed out to seeded players in turn; every player gets the first
+var seedVehicles = []string{"sedan", "suv", "truck", "sports_car"}
+
+// seedLevels is how many levels seeded players have progress on, at most
+const seedLevels = 5
+
+func runSeed(e *env, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	players := flags.Int("players", 10, "number of players to create")
+	password := flags.String("password", "password123", "password given to every seeded player")
+	if err := e.parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *players < 1 {
+		return fmt.Errorf("-players must be at least 1")
+	}
+	if e.cfg.ReleaseMode() {
+		return fmt.Errorf("refusing to seed fixtures in %s mode", e.cfg.Server.Mode)
+	}
+
+	hash, err := auth.NewPasswordService().HashPassword(*password)
+	if err != nil {
+		return err
+	}
+
+	// A fixed seed gives the same fixtures on every run
+	rng := rand.New(rand.NewSource(1))
+	created := 0
+	for i := 1; i <= *players; i++ {
+		username := fmt.Sprintf("seed_player_%02d", i)
+		ok, err := seedPlayer(e.db, rng, i, username, hash)
+		if err != nil {
+			return fmt.Errorf("failed to seed %s: %w", username, err)
+		}
+		if ok {
+			created++
+		}
+	}
+
+	fmt.Fprintf(e.stdout, "Created %d player(s), %d already existed\n", created, *players-created)
+	return nil
+}
+
+// seedPlayer creates one player with vehicles, level progress and finished
+// sessions, leaving an existing player of that name untouched
+func seedPlayer(db *gorm.DB, rng *rand.Rand, n int, username, passwordHash string) (bool, error) {
+	err := db.Unscoped().Where("username = ?", username).First(&models.Player{}).Error
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, fmt.Errorf("database error: %w", err)
+	}
+
+	now := time.Now()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		player := models.Player{
+			Username:        username,
+			Email:           username + "@example.com",
+			PasswordHash:    passwordHash,
+			EmailVerifiedAt: &now,
+			Role:            auth.RolePlayer,
+			Currency:        500 + rng.Intn(5000),
+			LastActiveAt:    &now,
+		}
+		if err := tx.Create(&player).Error; err != nil {
+			return err
+		}
+
+		vehicles := []string{seedVehicles[0]}
+		if n%2 == 0 {
+			vehicles = append(vehicles, seedVehicles[1+(n/2)%(len(seedVehicles)-1)])
+		}
+		for _, vehicleType := range vehicles {
+			vehicle := models.OwnedVehicle{PlayerID: player.ID, VehicleType: vehicleType, PurchasedAt: now}
+			if err := tx.Create(&vehicle).Error; err != nil {
+				return err
+			}
+		}
+
+		levels := 1 + rng.Intn(seedLevels)
+		var totalScore int64
+		for level := 1; level <= levels; level++ {
+			levelID := fmt.Sprintf("level-%d", level)
+			score := 1000 + rng.Intn(9000)
+			startedAt := now.Add(-time.Duration(levels-level+1) * time.Hour)
+			endedAt := startedAt.Add(time.Duration(2+rng.Intn(10)) * time.Minute)
+
+			session := models.GameSession{
+				PlayerID:         player.ID,
+				LevelID:          levelID,
+				Score:            score,
+				ZombiesKilled:    score / 50,
+				DistanceTraveled: float64(score) * 1.5,
+				SessionState:     models.SessionStateCompleted,
+				StartedAt:        startedAt,
+				EndedAt:          &endedAt,
+			}
+			if err := tx.Create(&session).Error; err != nil {
+				return err
+			}
+
+			progress := models.LevelProgress{
+				PlayerID:    player.ID,
+				LevelID:     levelID,
+				BestScore:   score,
+				Completed:   true,
+				StarsEarned: 1 + rng.Intn(3),
+			}
+			if err := tx.Create(&progress).Error; err != nil {
+				return err
+			}
+			totalScore += int64(score)
+		}
+
+		return tx.Model(&player).Updates(map[string]interface{}{
+			"level":       levels + 1,
+			"total_score": totalScore,
+		}).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
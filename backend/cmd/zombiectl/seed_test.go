@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"zombie-car-game-backend/internal/config"
+	"zombie-car-game-backend/internal/models"
+)
+
+func TestSeed(t *testing.T) {
+	e := newTestEnv(t)
+
+	code, stdout, stderr := runZombiectl(e, "seed", "-players", "2")
+	require.Equal(t, 0, code, stderr)
+	assert.Equal(t, "Created 2 player(s), 0 already existed\n", stdout)
+
+	var players []models.Player
+	require.NoError(t, e.db.Order("username").Find(&players).Error)
+	require.Len(t, players, 2)
+	assert.Equal(t, "seed_player_01", players[0].Username)
+	var vehicles, sessions int64
+	e.db.Model(&models.OwnedVehicle{}).Count(&vehicles)
+	e.db.Model(&models.GameSession{}).Count(&sessions)
+	assert.Equal(t, int64(3), vehicles, "every player gets a sedan and even-numbered ones a second vehicle")
+	assert.Positive(t, sessions)
+
+	// Seeding again leaves existing players alone
+	code, stdout, _ = runZombiectl(e, "seed", "-players", "3")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "Created 1 player(s), 2 already existed\n", stdout)
+
+	code, _, stderr = runZombiectl(e, "seed", "-players", "0")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "zombiectl: -players must be at least 1")
+
+	e.cfg.Server.Mode = config.ModeRelease
+	code, _, stderr = runZombiectl(e, "seed")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "refusing to seed fixtures in release mode")
+}
@@ -3,19 +3,20 @@ package main
 import (
 	"fmt"
 	"log"
-	"os"
+	"time"
 
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
 	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+	"zombie-car-game-backend/internal/services"
 )
 
-// Simple demonstration of the authentication system without database dependencies
+// Simple demonstration of the authentication system against an in-memory database
 func main() {
 	fmt.Println("=== Zombie Car Game Authentication System Demo ===")
 	fmt.Println()
 
-	// Set test environment
-	os.Setenv("JWT_SECRET", "demo-secret-key")
-
 	// Initialize services
 	passwordService := auth.NewPasswordService()
 	jwtService := auth.NewJWTService()
@@ -71,14 +72,37 @@ func main() {
 	fmt.Println("   ✓ JWT token validated successfully")
 	fmt.Println()
 
-	fmt.Println("5. Testing JWT Token Refresh...")
-	newToken, err := jwtService.RefreshToken(token)
+	fmt.Println("5. Testing Refresh Token Rotation...")
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to open demo database:", err)
+	}
+	if err := db.AutoMigrate(&models.Player{}, &models.DeviceSession{}, &models.RefreshToken{}); err != nil {
+		log.Fatal("Failed to migrate demo database:", err)
+	}
+	player := models.Player{Username: username, Email: "demo@example.com", PasswordHash: hashedPassword}
+	if err := db.Create(&player).Error; err != nil {
+		log.Fatal("Failed to create demo player:", err)
+	}
+
+	tokenService := services.NewTokenService(db, jwtService, 24*time.Hour)
+	pair, err := tokenService.IssueTokens(&player, services.DeviceInfo{UserAgent: "demo"})
+	if err != nil {
+		log.Fatal("Failed to issue tokens:", err)
+	}
+	rotated, _, err := tokenService.RotateRefreshToken(pair.RefreshToken, services.DeviceInfo{})
 	if err != nil {
-		log.Fatal("Failed to refresh token:", err)
+		log.Fatal("Failed to rotate refresh token:", err)
+	}
+	fmt.Printf("   Original Access Token:  %s...\n", pair.AccessToken[:30])
+	fmt.Printf("   Rotated Access Token:   %s...\n", rotated.AccessToken[:30])
+	fmt.Println("   ✓ Refresh token rotated successfully")
+
+	// Replaying a used refresh token revokes the whole session
+	if _, _, err := tokenService.RotateRefreshToken(pair.RefreshToken, services.DeviceInfo{}); err != services.ErrRefreshTokenReused {
+		log.Fatal("Reused refresh token should have been rejected")
 	}
-	fmt.Printf("   Original Token: %s...\n", token[:30])
-	fmt.Printf("   Refreshed Token: %s...\n", newToken[:30])
-	fmt.Println("   ✓ JWT token refreshed successfully")
+	fmt.Println("   ✓ Reused refresh token correctly rejected")
 	fmt.Println()
 
 	fmt.Println("6. Testing Invalid Token Handling...")
@@ -96,7 +120,7 @@ func main() {
 	fmt.Println("✓ Secure password hashing with bcrypt")
 	fmt.Println("✓ JWT token generation with configurable expiration")
 	fmt.Println("✓ JWT token validation with proper error handling")
-	fmt.Println("✓ JWT token refresh functionality")
+	fmt.Println("✓ Rotating refresh tokens with reuse detection")
 	fmt.Println("✓ Proper error handling for invalid credentials")
 	fmt.Println("✓ Middleware for protecting API endpoints")
 	fmt.Println("✓ Complete player service with CRUD operations")
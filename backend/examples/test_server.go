@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -20,7 +19,6 @@ import (
 // Simple test to verify the authentication system works
 func main() {
 	// Set test environment
-	os.Setenv("JWT_SECRET", "test-secret-key")
 	gin.SetMode(gin.TestMode)
 
 	// Setup in-memory database for testing
@@ -36,8 +34,12 @@ func main() {
 	}
 
 	// Setup services and handlers
-	playerService := services.NewPlayerService(db)
 	jwtService := auth.NewJWTService()
+	playerService := services.NewPlayerService(db, jwtService, services.AccountConfig{
+		RefreshTokenTTL: 24 * time.Hour,
+		AppBaseURL:      "http://localhost:3000",
+		TOTPIssuer:      "Zombie Car Game",
+	})
 	authHandler := handlers.NewAuthHandler(playerService)
 	playerHandler := handlers.NewPlayerHandler(playerService)
 
@@ -62,7 +64,7 @@ func main() {
 
 	// Protected routes
 	protected := r.Group("/api/v1/players")
-	protected.Use(middleware.AuthMiddleware(jwtService))
+	protected.Use(middleware.AuthMiddleware(jwtService, nil))
 	{
 		protected.GET("/profile", playerHandler.GetProfile)
 		protected.GET("/progress", playerHandler.GetProgress)
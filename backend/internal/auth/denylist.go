@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	denylistKeyPrefix      = "auth:denylist:"
+	revokedBeforeKeyPrefix = "auth:revoked_before:"
+)
+
+// Denylist records revoked access tokens by their jti (or a whole device session by
+// its session ID) until they would have expired. It can also revoke every token of a
+// player issued before a cutoff, which is used when a password reset has to end all
+// existing sessions.
+type Denylist interface {
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	RevokeAllBefore(ctx context.Context, playerID uint, cutoff time.Time, ttl time.Duration) error
+	RevokedBefore(ctx context.Context, playerID uint) (time.Time, error)
+}
+
+// NewDenylist returns a Redis-backed denylist, or an in-memory one when client is nil
+func NewDenylist(client *redis.Client) Denylist {
+	if client == nil {
+		return NewMemoryDenylist()
+	}
+	return NewRedisDenylist(client)
+}
+
+// RedisDenylist stores revoked token IDs in Redis so all replicas share them
+type RedisDenylist struct {
+	client *redis.Client
+}
+
+// NewRedisDenylist creates a Redis-backed denylist
+func NewRedisDenylist(client *redis.Client) *RedisDenylist {
+	return &RedisDenylist{client: client}
+}
+
+// Revoke adds a token ID to the denylist until expiresAt
+func (d *RedisDenylist) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return d.client.Set(ctx, denylistKeyPrefix+jti, 1, ttl).Err()
+}
+
+// IsRevoked reports whether a token ID has been revoked
+func (d *RedisDenylist) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	n, err := d.client.Exists(ctx, denylistKeyPrefix+jti).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// RevokeAllBefore revokes every token of a player issued before cutoff; ttl should cover the token lifetime
+func (d *RedisDenylist) RevokeAllBefore(ctx context.Context, playerID uint, cutoff time.Time, ttl time.Duration) error {
+	return d.client.Set(ctx, revokedBeforeKey(playerID), cutoff.UnixMilli(), ttl).Err()
+}
+
+// RevokedBefore returns the player's cutoff, or the zero time if none is set
+func (d *RedisDenylist) RevokedBefore(ctx context.Context, playerID uint) (time.Time, error) {
+	value, err := d.client.Get(ctx, revokedBeforeKey(playerID)).Int64()
+	if err == redis.Nil {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(value), nil
+}
+
+func revokedBeforeKey(playerID uint) string {
+	return revokedBeforeKeyPrefix + strconv.FormatUint(uint64(playerID), 10)
+}
+
+// MemoryDenylist keeps revoked token IDs in process memory.
+// It is used when Redis is not available and only covers a single instance.
+type MemoryDenylist struct {
+	mu      sync.RWMutex
+	entries map[string]time.Time
+	cutoffs map[uint]memoryCutoff
+}
+
+type memoryCutoff struct {
+	cutoff    time.Time
+	expiresAt time.Time
+}
+
+// NewMemoryDenylist creates an in-memory denylist
+func NewMemoryDenylist() *MemoryDenylist {
+	return &MemoryDenylist{
+		entries: make(map[string]time.Time),
+		cutoffs: make(map[uint]memoryCutoff),
+	}
+}
+
+// Revoke adds a token ID to the denylist until expiresAt
+func (d *MemoryDenylist) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for id, exp := range d.entries {
+		if now.After(exp) {
+			delete(d.entries, id)
+		}
+	}
+
+	if expiresAt.After(now) {
+		d.entries[jti] = expiresAt
+	}
+	return nil
+}
+
+// IsRevoked reports whether a token ID has been revoked
+func (d *MemoryDenylist) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	exp, ok := d.entries[jti]
+	return ok && time.Now().Before(exp), nil
+}
+
+// RevokeAllBefore revokes every token of a player issued before cutoff; ttl should cover the token lifetime
+func (d *MemoryDenylist) RevokeAllBefore(ctx context.Context, playerID uint, cutoff time.Time, ttl time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.cutoffs[playerID] = memoryCutoff{cutoff: cutoff, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// RevokedBefore returns the player's cutoff, or the zero time if none is set
+func (d *MemoryDenylist) RevokedBefore(ctx context.Context, playerID uint) (time.Time, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	entry, ok := d.cutoffs[playerID]
+	if !ok || !time.Now().Before(entry.expiresAt) {
+		return time.Time{}, nil
+	}
+	return entry.cutoff, nil
+}
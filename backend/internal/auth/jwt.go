@@ -1,68 +1,132 @@
 package auth
 
 import (
+	"context"
 	"errors"
-	"os"
+	"log/slog"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 var (
 	ErrInvalidToken = errors.New("invalid token")
 	ErrExpiredToken = errors.New("token has expired")
+	ErrRevokedToken = errors.New("token has been revoked")
 )
 
+// DefaultAccessTokenTTL keeps access tokens short-lived; clients renew them with a refresh token
+const DefaultAccessTokenTTL = 15 * time.Minute
+
+// denylistTimeout bounds the revocation lookup done on every authenticated request
+const denylistTimeout = 500 * time.Millisecond
+
 // Claims represents the JWT claims
 type Claims struct {
 	PlayerID uint   `json:"player_id"`
 	Username string `json:"username"`
+	// Role is the account role at the time the token was issued
+	Role string `json:"role,omitempty"`
+	// SessionID ties the token to the device session (login) that issued it
+	SessionID string `json:"sid,omitempty"`
+	// IssuedAtMilli is iat in milliseconds; iat alone cannot order a token against a
+	// revocation cutoff from the same second
+	IssuedAtMilli int64 `json:"iat_ms,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // JWTService handles JWT token operations
 type JWTService struct {
-	secretKey []byte
+	keys      *KeySet
+	accessTTL time.Duration
+	denylist  Denylist
 }
 
-// NewJWTService creates a new JWT service
+// NewJWTService creates a JWT service that signs with the development key and
+// keeps revocations in memory, for tests and examples. The server and tools
+// build one with NewJWTServiceWithKeys from their configuration and hand it to
+// every service, so they all see each other's revocations.
 func NewJWTService() *JWTService {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "your-secret-key-change-in-production" // Default for development
+	// Without a secret or keys file LoadKeySet cannot fail
+	keys, _ := LoadKeySet(KeyConfig{GracePeriod: DefaultKeyGracePeriod})
+	return NewJWTServiceWithKeys(keys, DefaultAccessTokenTTL, NewMemoryDenylist())
+}
+
+// NewJWTServiceWithKeys creates a JWT service around an explicit keyset that
+// issues access tokens valid for accessTTL and records revocations in denylist
+func NewJWTServiceWithKeys(keys *KeySet, accessTTL time.Duration, denylist Denylist) *JWTService {
+	if accessTTL <= 0 {
+		accessTTL = DefaultAccessTokenTTL
 	}
-	
+
 	return &JWTService{
-		secretKey: []byte(secret),
+		keys:      keys,
+		accessTTL: accessTTL,
+		denylist:  denylist,
 	}
 }
 
-// GenerateToken generates a new JWT token for a player
+// Keys returns the keyset used to sign and verify tokens
+func (j *JWTService) Keys() *KeySet {
+	return j.keys
+}
+
+// AccessTokenTTL returns the lifetime of issued access tokens
+func (j *JWTService) AccessTokenTTL() time.Duration {
+	return j.accessTTL
+}
+
+// GenerateToken generates a new JWT access token for a player
 func (j *JWTService) GenerateToken(playerID uint, username string) (string, error) {
+	return j.GenerateSessionToken(playerID, username, RolePlayer, "")
+}
+
+// GenerateSessionToken generates an access token bound to a device session,
+// so revoking the session also rejects the token
+func (j *JWTService) GenerateSessionToken(playerID uint, username, role, sessionID string) (string, error) {
+	now := time.Now()
 	claims := Claims{
-		PlayerID: playerID,
-		Username: username,
+		PlayerID:  playerID,
+		Username:  username,
+		Role:      role,
+		SessionID: sessionID,
+		// Set from the same instant as iat
+		IssuedAtMilli: now.UnixMilli(),
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // 24 hours
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.accessTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "zombie-car-game",
 			Subject:   username,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(j.secretKey)
+	key, err := j.keys.Active()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.signKey)
 }
 
 // ValidateToken validates a JWT token and returns the claims
 func (j *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		key, err := j.keys.Lookup(kid)
+		if err != nil {
+			return nil, err
+		}
+
+		// The algorithm is pinned by the key, never chosen by the token
+		if token.Method.Alg() != key.Algorithm {
 			return nil, errors.New("unexpected signing method")
 		}
-		return j.secretKey, nil
+		return key.verifyKey, nil
 	})
 
 	if err != nil {
@@ -72,20 +136,88 @@ func (j *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 		return nil, ErrInvalidToken
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	if j.isRevoked(claims) {
+		return nil, ErrRevokedToken
 	}
 
-	return nil, ErrInvalidToken
+	return claims, nil
 }
 
-// RefreshToken generates a new token with extended expiration
-func (j *JWTService) RefreshToken(tokenString string) (string, error) {
-	claims, err := j.ValidateToken(tokenString)
+// isRevoked checks the denylist for the token's jti, its device session and the player's
+// session cutoff. Lookups fail open: access tokens are short-lived and Redis is an optional dependency.
+func (j *JWTService) isRevoked(claims *Claims) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), denylistTimeout)
+	defer cancel()
+
+	for _, id := range []string{claims.ID, sessionDenylistID(claims.SessionID)} {
+		if id == "" {
+			continue
+		}
+		revoked, err := j.denylist.IsRevoked(ctx, id)
+		if err != nil {
+			slog.Warn("Token denylist lookup failed", "error", err)
+		} else if revoked {
+			return true
+		}
+	}
+
+	cutoff, err := j.denylist.RevokedBefore(ctx, claims.PlayerID)
 	if err != nil {
-		return "", err
+		slog.Warn("Token denylist lookup failed", "error", err)
+		return false
+	}
+	if cutoff.IsZero() {
+		return false
+	}
+	if claims.IssuedAtMilli > 0 {
+		return claims.IssuedAtMilli <= cutoff.UnixMilli()
+	}
+	// Tokens without iat_ms only carry whole seconds, so one from the cutoff's second is revoked too
+	return claims.IssuedAt == nil || !claims.IssuedAt.Time.After(cutoff)
+}
+
+// RevokeToken denylists an access token until it expires
+func (j *JWTService) RevokeToken(claims *Claims) error {
+	if claims == nil || claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), denylistTimeout)
+	defer cancel()
+
+	return j.denylist.Revoke(ctx, claims.ID, claims.ExpiresAt.Time)
+}
+
+// RevokeAllForPlayer revokes every access token issued to a player up to now
+func (j *JWTService) RevokeAllForPlayer(playerID uint) error {
+	ctx, cancel := context.WithTimeout(context.Background(), denylistTimeout)
+	defer cancel()
+
+	return j.denylist.RevokeAllBefore(ctx, playerID, time.Now(), j.accessTTL)
+}
+
+// RevokeSession rejects every access token issued for a device session
+func (j *JWTService) RevokeSession(sessionID string) error {
+	if sessionID == "" {
+		return nil
 	}
 
-	// Generate new token with same claims but extended expiration
-	return j.GenerateToken(claims.PlayerID, claims.Username)
-}
\ No newline at end of file
+	ctx, cancel := context.WithTimeout(context.Background(), denylistTimeout)
+	defer cancel()
+
+	// Tokens issued for the session expire within one access token lifetime from now
+	return j.denylist.Revoke(ctx, sessionDenylistID(sessionID), time.Now().Add(j.accessTTL))
+}
+
+// sessionDenylistID keeps session entries apart from jti entries in the denylist
+func sessionDenylistID(sessionID string) string {
+	if sessionID == "" {
+		return ""
+	}
+	return "session:" + sessionID
+}
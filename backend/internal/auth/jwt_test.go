@@ -1,18 +1,15 @@
 package auth
 
 import (
-	"os"
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestJWTService_GenerateToken(t *testing.T) {
-	// Set test secret
-	os.Setenv("JWT_SECRET", "test-secret-key")
-	defer os.Unsetenv("JWT_SECRET")
-
 	jwtService := NewJWTService()
 
 	token, err := jwtService.GenerateToken(1, "testuser")
@@ -21,8 +18,6 @@ func TestJWTService_GenerateToken(t *testing.T) {
 }
 
 func TestJWTService_ValidateToken(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret-key")
-	defer os.Unsetenv("JWT_SECRET")
 
 	jwtService := NewJWTService()
 
@@ -38,8 +33,6 @@ func TestJWTService_ValidateToken(t *testing.T) {
 }
 
 func TestJWTService_ValidateToken_InvalidToken(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret-key")
-	defer os.Unsetenv("JWT_SECRET")
 
 	jwtService := NewJWTService()
 
@@ -50,8 +43,6 @@ func TestJWTService_ValidateToken_InvalidToken(t *testing.T) {
 }
 
 func TestJWTService_ValidateToken_ExpiredToken(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret-key")
-	defer os.Unsetenv("JWT_SECRET")
 
 	jwtService := NewJWTService()
 
@@ -70,37 +61,109 @@ func TestJWTService_ValidateToken_ExpiredToken(t *testing.T) {
 	assert.Equal(t, uint(1), claims.PlayerID)
 }
 
-func TestJWTService_RefreshToken(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret-key")
-	defer os.Unsetenv("JWT_SECRET")
+func TestJWTService_GenerateToken_UniqueID(t *testing.T) {
 
 	jwtService := NewJWTService()
 
-	// Generate original token
-	originalToken, err := jwtService.GenerateToken(1, "testuser")
+	first, err := jwtService.GenerateToken(1, "testuser")
+	require.NoError(t, err)
+	second, err := jwtService.GenerateToken(1, "testuser")
 	require.NoError(t, err)
 
-	// Refresh the token
-	newToken, err := jwtService.RefreshToken(originalToken)
+	firstClaims, err := jwtService.ValidateToken(first)
+	require.NoError(t, err)
+	secondClaims, err := jwtService.ValidateToken(second)
 	require.NoError(t, err)
-	assert.NotEmpty(t, newToken)
-	// Note: tokens might be the same if generated within the same second
-	// The important thing is that refresh works without error
 
-	// Validate the new token
-	claims, err := jwtService.ValidateToken(newToken)
+	// Every token carries its own jti so it can be revoked individually
+	assert.NotEmpty(t, firstClaims.ID)
+	assert.NotEqual(t, firstClaims.ID, secondClaims.ID)
+	assert.WithinDuration(t, time.Now().Add(DefaultAccessTokenTTL), firstClaims.ExpiresAt.Time, 5*time.Second)
+}
+
+func TestJWTService_RevokeToken(t *testing.T) {
+	keys := NewKeySet(NewHMACKey("test", []byte("test-secret-key")), time.Hour)
+	denylist := NewMemoryDenylist()
+	jwtService := NewJWTServiceWithKeys(keys, DefaultAccessTokenTTL, denylist)
+
+	token, err := jwtService.GenerateToken(1, "testuser")
 	require.NoError(t, err)
-	assert.Equal(t, uint(1), claims.PlayerID)
-	assert.Equal(t, "testuser", claims.Username)
+
+	claims, err := jwtService.ValidateToken(token)
+	require.NoError(t, err)
+
+	require.NoError(t, jwtService.RevokeToken(claims))
+
+	// Revocation is visible to other service instances sharing the denylist
+	_, err = NewJWTServiceWithKeys(keys, DefaultAccessTokenTTL, denylist).ValidateToken(token)
+	assert.Equal(t, ErrRevokedToken, err)
+
+	// A service with a denylist of its own does not see it
+	_, err = NewJWTServiceWithKeys(keys, DefaultAccessTokenTTL, NewMemoryDenylist()).ValidateToken(token)
+	assert.NoError(t, err)
 }
 
-func TestJWTService_RefreshToken_InvalidToken(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret-key")
-	defer os.Unsetenv("JWT_SECRET")
+func TestMemoryDenylist(t *testing.T) {
+	denylist := NewMemoryDenylist()
+	ctx := context.Background()
 
-	jwtService := NewJWTService()
+	require.NoError(t, denylist.Revoke(ctx, "live", time.Now().Add(time.Minute)))
+	require.NoError(t, denylist.Revoke(ctx, "expired", time.Now().Add(-time.Minute)))
 
-	// Try to refresh invalid token
-	_, err := jwtService.RefreshToken("invalid-token")
-	assert.Error(t, err)
-}
\ No newline at end of file
+	revoked, err := denylist.IsRevoked(ctx, "live")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	revoked, err = denylist.IsRevoked(ctx, "expired")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestJWTService_RevokeAllForPlayer(t *testing.T) {
+	jwtService := NewJWTServiceWithKeys(NewKeySet(NewHMACKey("test", []byte("test-secret-key")), time.Hour),
+		DefaultAccessTokenTTL, NewMemoryDenylist())
+
+	token, err := jwtService.GenerateToken(42, "resetter")
+	require.NoError(t, err)
+	otherToken, err := jwtService.GenerateToken(43, "bystander")
+	require.NoError(t, err)
+
+	require.NoError(t, jwtService.RevokeAllForPlayer(42))
+
+	_, err = jwtService.ValidateToken(token)
+	assert.Equal(t, ErrRevokedToken, err)
+
+	// A token issued after the cutoff stays valid, even within the same second
+	time.Sleep(2 * time.Millisecond)
+	fresh, err := jwtService.GenerateToken(42, "resetter")
+	require.NoError(t, err)
+	_, err = jwtService.ValidateToken(fresh)
+	assert.NoError(t, err)
+
+	// Other players are unaffected
+	_, err = jwtService.ValidateToken(otherToken)
+	assert.NoError(t, err)
+}
+
+func TestJWTService_RevokeSession(t *testing.T) {
+	jwtService := NewJWTServiceWithKeys(NewKeySet(NewHMACKey("test", []byte("test-secret-key")), time.Hour),
+		DefaultAccessTokenTTL, NewMemoryDenylist())
+
+	laptop, err := jwtService.GenerateSessionToken(7, "driver", RolePlayer, "session-laptop")
+	require.NoError(t, err)
+	phone, err := jwtService.GenerateSessionToken(7, "driver", RolePlayer, "session-phone")
+	require.NoError(t, err)
+
+	claims, err := jwtService.ValidateToken(laptop)
+	require.NoError(t, err)
+	assert.Equal(t, "session-laptop", claims.SessionID)
+
+	require.NoError(t, jwtService.RevokeSession("session-laptop"))
+
+	_, err = jwtService.ValidateToken(laptop)
+	assert.Equal(t, ErrRevokedToken, err)
+
+	// The player's other devices stay signed in
+	_, err = jwtService.ValidateToken(phone)
+	assert.NoError(t, err)
+}
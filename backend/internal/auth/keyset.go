@@ -0,0 +1,448 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// defaultSecret is the development-only HMAC secret used when nothing is configured
+const defaultSecret = "your-secret-key-change-in-production"
+
+// DefaultKeyGracePeriod is how long a retired key keeps verifying tokens
+const DefaultKeyGracePeriod = 24 * time.Hour
+
+var (
+	ErrUnknownKey         = errors.New("unknown signing key")
+	ErrNoSigningKey       = errors.New("no active signing key")
+	ErrUnsupportedKeyAlg  = errors.New("unsupported signing algorithm")
+	ErrDefaultSecretInUse = errors.New("JWT_SECRET is not set; refusing to use the default secret in release mode")
+)
+
+// SigningKey is a single key in the keyset, identified by its kid
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	// RetiredAt is set once the key stops signing; it still verifies during the grace period
+	RetiredAt *time.Time
+
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// NewHMACKey creates a symmetric HS256 key. Its secret is never published.
+func NewHMACKey(id string, secret []byte) *SigningKey {
+	return &SigningKey{ID: id, Algorithm: AlgHS256, signKey: secret, verifyKey: secret}
+}
+
+// NewRSAKey creates an RS256 signing key
+func NewRSAKey(id string, key *rsa.PrivateKey) *SigningKey {
+	return &SigningKey{ID: id, Algorithm: AlgRS256, signKey: key, verifyKey: &key.PublicKey}
+}
+
+// NewEd25519Key creates an EdDSA signing key
+func NewEd25519Key(id string, key ed25519.PrivateKey) *SigningKey {
+	return &SigningKey{ID: id, Algorithm: AlgEdDSA, signKey: key, verifyKey: key.Public()}
+}
+
+// CanSign reports whether the key holds private material
+func (k *SigningKey) CanSign() bool {
+	return k.signKey != nil
+}
+
+// method returns the jwt signing method for the key's algorithm
+func (k *SigningKey) method() jwt.SigningMethod {
+	switch k.Algorithm {
+	case AlgRS256:
+		return jwt.SigningMethodRS256
+	case AlgEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// KeySet holds the active signing key and the keys still accepted for verification.
+//
+// Rotating moves the current key into retirement instead of dropping it, so
+// tokens signed just before a rotation stay valid until the grace period ends.
+type KeySet struct {
+	mu     sync.RWMutex
+	active *SigningKey
+	keys   map[string]*SigningKey
+	// legacy verifies tokens issued before kids were introduced
+	legacy *SigningKey
+	grace  time.Duration
+}
+
+// NewKeySet creates a keyset signing with the given active key
+func NewKeySet(active *SigningKey, grace time.Duration) *KeySet {
+	ks := &KeySet{
+		keys:  make(map[string]*SigningKey),
+		grace: grace,
+	}
+	if active != nil {
+		ks.keys[active.ID] = active
+		ks.active = active
+	}
+	return ks
+}
+
+// Add registers an additional verification key
+func (ks *KeySet) Add(key *SigningKey) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys[key.ID] = key
+}
+
+// Rotate makes key the active signing key and retires the previous one
+func (ks *KeySet) Rotate(key *SigningKey) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if ks.active != nil && ks.active.ID != key.ID {
+		now := time.Now()
+		ks.active.RetiredAt = &now
+	}
+	ks.keys[key.ID] = key
+	ks.active = key
+}
+
+// Active returns the key new tokens are signed with
+func (ks *KeySet) Active() (*SigningKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	if ks.active == nil || !ks.active.CanSign() {
+		return nil, ErrNoSigningKey
+	}
+	return ks.active, nil
+}
+
+// Lookup returns the verification key for a kid, honouring the retirement grace period
+func (ks *KeySet) Lookup(kid string) (*SigningKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	if kid == "" {
+		if ks.legacy == nil {
+			return nil, ErrUnknownKey
+		}
+		return ks.legacy, nil
+	}
+
+	key, ok := ks.keys[kid]
+	if !ok || !ks.usable(key, time.Now()) {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// usable reports whether a key still verifies tokens at the given time
+func (ks *KeySet) usable(key *SigningKey, now time.Time) bool {
+	return key.RetiredAt == nil || now.Before(key.RetiredAt.Add(ks.grace))
+}
+
+// usesDefaultSecret reports whether any HMAC key is the development default
+func (ks *KeySet) usesDefaultSecret() bool {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	for _, key := range ks.keys {
+		if secret, ok := key.verifyKey.([]byte); ok && string(secret) == defaultSecret {
+			return true
+		}
+	}
+	return false
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+// JWKS is the document served at /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWKS returns the public half of every asymmetric key that still verifies tokens
+func (ks *KeySet) PublicJWKS() JWKS {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	now := time.Now()
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range ks.keys {
+		if !ks.usable(key, now) {
+			continue
+		}
+		switch pub := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				KeyType:   "RSA",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: AlgRS256,
+				N:         base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				KeyType:   "OKP",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: AlgEdDSA,
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].KeyID < jwks.Keys[j].KeyID })
+	return jwks
+}
+
+// keyFile is the on-disk format of JWT_KEYS_FILE
+type keyFile struct {
+	Active string         `json:"active"`
+	Keys   []keyFileEntry `json:"keys"`
+}
+
+type keyFileEntry struct {
+	ID             string     `json:"kid"`
+	Algorithm      string     `json:"alg"`
+	Secret         string     `json:"secret,omitempty"`
+	PrivateKeyFile string     `json:"private_key_file,omitempty"`
+	PublicKeyFile  string     `json:"public_key_file,omitempty"`
+	RetiredAt      *time.Time `json:"retired_at,omitempty"`
+}
+
+// KeyConfig describes where the signing keys come from
+type KeyConfig struct {
+	// Secret derives a single HS256 key when KeysFile is not set
+	Secret string
+	// KeysFile is a JSON document listing every key and naming the active one
+	KeysFile string
+	// GracePeriod is how long retired keys keep verifying tokens
+	GracePeriod time.Duration
+}
+
+// LoadKeySet builds the keyset described by cfg.
+//
+// When KeysFile is set it points at a JSON document listing every key and
+// naming the active one; retired keys carry a retired_at timestamp and keep
+// verifying for GracePeriod. Otherwise a single HS256 key is derived from
+// Secret, which also accepts tokens issued before kids existed.
+func LoadKeySet(cfg KeyConfig) (*KeySet, error) {
+	if cfg.KeysFile != "" {
+		return loadKeyFile(cfg.KeysFile, cfg.GracePeriod)
+	}
+
+	secret := cfg.Secret
+	if secret == "" {
+		secret = defaultSecret // Default for development
+	}
+
+	key := NewHMACKey(hmacKeyID(secret), []byte(secret))
+	ks := NewKeySet(key, cfg.GracePeriod)
+	ks.legacy = key
+	return ks, nil
+}
+
+// CheckKeySet checks that ks can sign tokens, and in release mode that it does
+// not use the development secret
+func CheckKeySet(ks *KeySet, releaseMode bool) error {
+	if _, err := ks.Active(); err != nil {
+		return err
+	}
+	if releaseMode && ks.usesDefaultSecret() {
+		return ErrDefaultSecretInUse
+	}
+	return nil
+}
+
+// loadKeyFile reads a JSON keyset document; key paths are relative to the document
+func loadKeyFile(path string, grace time.Duration) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT keys file: %w", err)
+	}
+
+	var doc keyFile
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse JWT keys file: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	ks := NewKeySet(nil, grace)
+	for _, entry := range doc.Keys {
+		key, err := entry.load(dir)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", entry.ID, err)
+		}
+		if _, exists := ks.keys[key.ID]; exists {
+			return nil, fmt.Errorf("key %q: duplicate kid", entry.ID)
+		}
+		ks.keys[key.ID] = key
+	}
+
+	active, ok := ks.keys[doc.Active]
+	if !ok {
+		return nil, fmt.Errorf("active key %q is not defined", doc.Active)
+	}
+	if !active.CanSign() || active.RetiredAt != nil {
+		return nil, fmt.Errorf("active key %q must have a private key and must not be retired", doc.Active)
+	}
+	ks.active = active
+
+	return ks, nil
+}
+
+// load resolves a key file entry into a SigningKey
+func (e keyFileEntry) load(dir string) (*SigningKey, error) {
+	if e.ID == "" {
+		return nil, errors.New("kid is required")
+	}
+
+	var key *SigningKey
+	switch e.Algorithm {
+	case AlgHS256:
+		if e.Secret == "" {
+			return nil, errors.New("HS256 keys require a secret")
+		}
+		key = NewHMACKey(e.ID, []byte(e.Secret))
+	case AlgRS256, AlgEdDSA:
+		var err error
+		switch {
+		case e.PrivateKeyFile != "":
+			key, err = loadPrivateKey(e.ID, e.Algorithm, resolvePath(dir, e.PrivateKeyFile))
+		case e.PublicKeyFile != "":
+			key, err = loadPublicKey(e.ID, e.Algorithm, resolvePath(dir, e.PublicKeyFile))
+		default:
+			err = errors.New("private_key_file or public_key_file is required")
+		}
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedKeyAlg, e.Algorithm)
+	}
+
+	key.RetiredAt = e.RetiredAt
+	return key, nil
+}
+
+// loadPrivateKey reads a PKCS#8 (or PKCS#1 RSA) PEM private key
+func loadPrivateKey(id, alg, path string) (*SigningKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed interface{}
+	if block.Type == "RSA PRIVATE KEY" {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		if alg == AlgRS256 {
+			return NewRSAKey(id, k), nil
+		}
+	case ed25519.PrivateKey:
+		if alg == AlgEdDSA {
+			return NewEd25519Key(id, k), nil
+		}
+	}
+	return nil, fmt.Errorf("private key does not match algorithm %s", alg)
+}
+
+// loadPublicKey reads a PKIX PEM public key for a verification-only key
+func loadPublicKey(id, alg, path string) (*SigningKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	var pub crypto.PublicKey
+	switch k := parsed.(type) {
+	case *rsa.PublicKey:
+		if alg == AlgRS256 {
+			pub = k
+		}
+	case ed25519.PublicKey:
+		if alg == AlgEdDSA {
+			pub = k
+		}
+	}
+	if pub == nil {
+		return nil, fmt.Errorf("public key does not match algorithm %s", alg)
+	}
+	return &SigningKey{ID: id, Algorithm: alg, verifyKey: pub}, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in %s", path)
+	}
+	return block, nil
+}
+
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// hmacKeyID derives a stable kid from a secret so every replica agrees on it
+func hmacKeyID(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return "hs-" + hex.EncodeToString(sum[:4])
+}
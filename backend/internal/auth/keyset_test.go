@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEd25519Key(t *testing.T, id string) *SigningKey {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return NewEd25519Key(id, priv)
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.NoError(t, os.WriteFile(path, data, 0600))
+}
+
+func TestJWTService_AsymmetricKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	for _, key := range []*SigningKey{NewRSAKey("rsa-1", rsaKey), newTestEd25519Key(t, "ed-1")} {
+		t.Run(key.Algorithm, func(t *testing.T) {
+			jwtService := NewJWTServiceWithKeys(NewKeySet(key, time.Hour), DefaultAccessTokenTTL, NewMemoryDenylist())
+
+			token, err := jwtService.GenerateToken(7, "signer")
+			require.NoError(t, err)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+			require.NoError(t, err)
+			assert.Equal(t, key.ID, parsed.Header["kid"])
+			assert.Equal(t, key.Algorithm, parsed.Header["alg"])
+
+			claims, err := jwtService.ValidateToken(token)
+			require.NoError(t, err)
+			assert.Equal(t, uint(7), claims.PlayerID)
+		})
+	}
+}
+
+func TestKeySet_RotationGracePeriod(t *testing.T) {
+	oldKey := newTestEd25519Key(t, "old")
+	keys := NewKeySet(oldKey, time.Hour)
+	jwtService := NewJWTServiceWithKeys(keys, DefaultAccessTokenTTL, NewMemoryDenylist())
+
+	oldToken, err := jwtService.GenerateToken(1, "testuser")
+	require.NoError(t, err)
+
+	keys.Rotate(newTestEd25519Key(t, "new"))
+
+	// Tokens signed before the rotation stay valid during the grace period
+	_, err = jwtService.ValidateToken(oldToken)
+	require.NoError(t, err)
+	assert.Len(t, keys.PublicJWKS().Keys, 2)
+
+	newToken, err := jwtService.GenerateToken(1, "testuser")
+	require.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &Claims{})
+	require.NoError(t, err)
+	assert.Equal(t, "new", parsed.Header["kid"])
+
+	// Once the grace period is over the old key is gone
+	expired := time.Now().Add(-2 * time.Hour)
+	oldKey.RetiredAt = &expired
+
+	_, err = jwtService.ValidateToken(oldToken)
+	assert.Equal(t, ErrInvalidToken, err)
+	require.Len(t, keys.PublicJWKS().Keys, 1)
+	assert.Equal(t, "new", keys.PublicJWKS().Keys[0].KeyID)
+}
+
+func TestJWTService_RejectsAlgorithmMismatch(t *testing.T) {
+	secret := []byte("shared-secret")
+	keys := NewKeySet(newTestEd25519Key(t, "ed-1"), time.Hour)
+	jwtService := NewJWTServiceWithKeys(keys, DefaultAccessTokenTTL, NewMemoryDenylist())
+
+	// A token claiming the asymmetric kid but signed with HMAC must not verify
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{PlayerID: 1})
+	token.Header["kid"] = "ed-1"
+	signed, err := token.SignedString(secret)
+	require.NoError(t, err)
+
+	_, err = jwtService.ValidateToken(signed)
+	assert.Equal(t, ErrInvalidToken, err)
+}
+
+func TestKeySet_PublicJWKS_HidesSecrets(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	keys := NewKeySet(NewRSAKey("rsa-1", rsaKey), time.Hour)
+	keys.Add(NewHMACKey("hs-1", []byte("secret")))
+
+	jwks := keys.PublicJWKS()
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "RSA", jwks.Keys[0].KeyType)
+	assert.Equal(t, "rsa-1", jwks.Keys[0].KeyID)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+	assert.NotEmpty(t, jwks.Keys[0].N)
+}
+
+func TestLoadKeySet_KeysFile(t *testing.T) {
+	dir := t.TempDir()
+
+	_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(edPriv)
+	require.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "current.pem"), "PRIVATE KEY", der)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "previous.pub"), "PUBLIC KEY", pubDER)
+
+	retiredAt := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	doc := `{"active":"2026-10","keys":[` +
+		`{"kid":"2026-10","alg":"EdDSA","private_key_file":"current.pem"},` +
+		`{"kid":"2026-07","alg":"RS256","public_key_file":"previous.pub","retired_at":"` + retiredAt + `"}]}`
+	keysFile := filepath.Join(dir, "keys.json")
+	require.NoError(t, os.WriteFile(keysFile, []byte(doc), 0600))
+
+	keys, err := LoadKeySet(KeyConfig{KeysFile: keysFile, GracePeriod: time.Hour})
+	require.NoError(t, err)
+
+	active, err := keys.Active()
+	require.NoError(t, err)
+	assert.Equal(t, "2026-10", active.ID)
+	assert.Equal(t, AlgEdDSA, active.Algorithm)
+
+	// The retired key is still inside its grace period
+	_, err = keys.Lookup("2026-07")
+	assert.NoError(t, err)
+	assert.Len(t, keys.PublicJWKS().Keys, 2)
+
+	// Kid-less tokens are only accepted for the legacy JWT_SECRET setup
+	_, err = keys.Lookup("")
+	assert.Equal(t, ErrUnknownKey, err)
+
+	require.NoError(t, CheckKeySet(keys, true))
+}
+
+func TestLoadKeySet_InvalidKeysFile(t *testing.T) {
+	dir := t.TempDir()
+	keysFile := filepath.Join(dir, "keys.json")
+	require.NoError(t, os.WriteFile(keysFile, []byte(`{"active":"missing","keys":[]}`), 0600))
+
+	_, err := LoadKeySet(KeyConfig{KeysFile: keysFile})
+	assert.Error(t, err)
+}
+
+func TestCheckKeySet_DefaultSecret(t *testing.T) {
+	keys, err := LoadKeySet(KeyConfig{})
+	require.NoError(t, err)
+	assert.NoError(t, CheckKeySet(keys, false))
+	assert.Equal(t, ErrDefaultSecretInUse, CheckKeySet(keys, true))
+
+	keys, err = LoadKeySet(KeyConfig{Secret: "a-real-secret"})
+	require.NoError(t, err)
+	assert.NoError(t, CheckKeySet(keys, true))
+}
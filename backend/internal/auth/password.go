@@ -1,31 +1,158 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// PasswordService handles password hashing and verification
+var (
+	ErrPasswordMismatch  = errors.New("password does not match")
+	ErrUnsupportedHash   = errors.New("unsupported password hash format")
+	ErrMalformedArgon2id = errors.New("malformed argon2id hash")
+)
+
+// Argon2Params are the argon2id cost parameters. They are stored in every hash,
+// so raising them later only affects new hashes and NeedsRehash picks up old ones.
+type Argon2Params struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params follows the RFC 9106 recommendation for memory-constrained environments
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// PasswordService handles password hashing and verification.
+//
+// New hashes use argon2id in the PHC string format
+// ($argon2id$v=19$m=65536,t=3,p=2$salt$hash). Legacy bcrypt hashes still verify
+// and are reported by NeedsRehash so they can be upgraded on the next login.
 type PasswordService struct {
-	cost int
+	params Argon2Params
 }
 
 // NewPasswordService creates a new password service
 func NewPasswordService() *PasswordService {
+	return NewPasswordServiceWithParams(DefaultArgon2Params)
+}
+
+// NewPasswordServiceWithParams creates a password service with custom argon2id costs
+func NewPasswordServiceWithParams(params Argon2Params) *PasswordService {
 	return &PasswordService{
-		cost: bcrypt.DefaultCost,
+		params: params,
 	}
 }
 
 // HashPassword hashes a plain text password
 func (p *PasswordService) HashPassword(password string) (string, error) {
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), p.cost)
-	if err != nil {
+	salt := make([]byte, p.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
 		return "", err
 	}
-	return string(hashedBytes), nil
+
+	key := argon2.IDKey([]byte(password), salt, p.params.Iterations, p.params.Memory, p.params.Parallelism, p.params.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.params.Memory, p.params.Iterations, p.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
 }
 
 // VerifyPassword verifies a password against its hash
 func (p *PasswordService) VerifyPassword(hashedPassword, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
-}
\ No newline at end of file
+	switch {
+	case strings.HasPrefix(hashedPassword, "$argon2id$"):
+		params, salt, key, err := decodeArgon2id(hashedPassword)
+		if err != nil {
+			return err
+		}
+		candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+		if subtle.ConstantTimeCompare(key, candidate) != 1 {
+			return ErrPasswordMismatch
+		}
+		return nil
+	case isBcryptHash(hashedPassword):
+		if err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password)); err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return ErrPasswordMismatch
+			}
+			return err
+		}
+		return nil
+	default:
+		return ErrUnsupportedHash
+	}
+}
+
+// NeedsRehash reports whether a hash uses an outdated algorithm or weaker parameters
+func (p *PasswordService) NeedsRehash(hashedPassword string) bool {
+	if !strings.HasPrefix(hashedPassword, "$argon2id$") {
+		return true
+	}
+
+	params, _, _, err := decodeArgon2id(hashedPassword)
+	if err != nil {
+		return true
+	}
+	return params.Memory != p.params.Memory ||
+		params.Iterations != p.params.Iterations ||
+		params.Parallelism != p.params.Parallelism ||
+		params.KeyLength != p.params.KeyLength
+}
+
+// decodeArgon2id parses a PHC-formatted argon2id hash
+func decodeArgon2id(encoded string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, key
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, ErrMalformedArgon2id
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, ErrMalformedArgon2id
+	}
+	if version != argon2.Version {
+		return params, nil, nil, ErrUnsupportedHash
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, ErrMalformedArgon2id
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrMalformedArgon2id
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrMalformedArgon2id
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
+
+// isBcryptHash recognises the $2a$/$2b$/$2y$ bcrypt prefixes
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestPasswordService_HashPassword(t *testing.T) {
@@ -58,4 +59,49 @@ func TestPasswordService_HashPassword_EmptyPassword(t *testing.T) {
 	// Verify empty password works
 	err = passwordService.VerifyPassword(hashedPassword, "")
 	assert.NoError(t, err)
-}
\ No newline at end of file
+}
+func TestPasswordService_HashPassword_Argon2idFormat(t *testing.T) {
+	passwordService := NewPasswordService()
+
+	hashedPassword, err := passwordService.HashPassword("testpassword123")
+	require.NoError(t, err)
+	assert.Regexp(t, `^\$argon2id\$v=19\$m=65536,t=3,p=2\$[A-Za-z0-9+/]+\$[A-Za-z0-9+/]+$`, hashedPassword)
+	assert.False(t, passwordService.NeedsRehash(hashedPassword))
+
+	// Every hash gets its own salt
+	other, err := passwordService.HashPassword("testpassword123")
+	require.NoError(t, err)
+	assert.NotEqual(t, hashedPassword, other)
+}
+
+func TestPasswordService_VerifyPassword_LegacyBcrypt(t *testing.T) {
+	passwordService := NewPasswordService()
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("testpassword123"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	assert.NoError(t, passwordService.VerifyPassword(string(legacy), "testpassword123"))
+	assert.Equal(t, ErrPasswordMismatch, passwordService.VerifyPassword(string(legacy), "wrongpassword"))
+	assert.True(t, passwordService.NeedsRehash(string(legacy)))
+}
+
+func TestPasswordService_NeedsRehash_ChangedParams(t *testing.T) {
+	weak := NewPasswordServiceWithParams(Argon2Params{Memory: 8 * 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+
+	hashedPassword, err := weak.HashPassword("testpassword123")
+	require.NoError(t, err)
+	assert.False(t, weak.NeedsRehash(hashedPassword))
+
+	// Hashes made with weaker parameters still verify but are flagged for upgrade
+	passwordService := NewPasswordService()
+	assert.NoError(t, passwordService.VerifyPassword(hashedPassword, "testpassword123"))
+	assert.True(t, passwordService.NeedsRehash(hashedPassword))
+}
+
+func TestPasswordService_VerifyPassword_UnsupportedHash(t *testing.T) {
+	passwordService := NewPasswordService()
+
+	assert.Equal(t, ErrUnsupportedHash, passwordService.VerifyPassword("plaintext", "plaintext"))
+	assert.Equal(t, ErrMalformedArgon2id, passwordService.VerifyPassword("$argon2id$v=19$garbage", "x"))
+	assert.True(t, passwordService.NeedsRehash("plaintext"))
+}
@@ -0,0 +1,71 @@
+package auth
+
+// Roles a player account can hold, from least to most privileged
+const (
+	RolePlayer    = "player"
+	RoleSupport   = "support"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// Permission names a single admin capability
+type Permission string
+
+// Admin permissions
+const (
+	PermissionViewPlayers    Permission = "players:view"
+	PermissionEditPlayers    Permission = "players:edit"
+	PermissionManageRoles    Permission = "players:roles"
+	PermissionSanction       Permission = "players:sanction"
+	PermissionManagePromos   Permission = "promos:manage"
+	PermissionRefundPayments Permission = "payments:refund"
+	PermissionViewAudit      Permission = "audit:view"
+	PermissionViewConfig     Permission = "config:view"
+)
+
+// rolePermissions lists what each staff role may do. Players have no admin permissions.
+var rolePermissions = map[string][]Permission{
+	RoleSupport: {
+		PermissionViewPlayers,
+	},
+	RoleModerator: {
+		PermissionViewPlayers,
+		PermissionEditPlayers,
+		PermissionSanction,
+	},
+	RoleAdmin: {
+		PermissionViewPlayers,
+		PermissionEditPlayers,
+		PermissionSanction,
+		PermissionManageRoles,
+		PermissionManagePromos,
+		PermissionRefundPayments,
+		PermissionViewAudit,
+		PermissionViewConfig,
+	},
+}
+
+// ValidRole reports whether role is one of the known roles
+func ValidRole(role string) bool {
+	switch role {
+	case RolePlayer, RoleSupport, RoleModerator, RoleAdmin:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsStaff reports whether role grants access to the admin API at all
+func IsStaff(role string) bool {
+	return len(rolePermissions[role]) > 0
+}
+
+// HasPermission reports whether role grants permission
+func HasPermission(role string, permission Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -3,9 +3,7 @@ package cache
 import (
 	"context"
 	"fmt"
-	"log"
-	"os"
-	"strconv"
+	"log/slog"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -21,27 +19,8 @@ type Config struct {
 	DB       int
 }
 
-// LoadConfig loads Redis configuration from environment variables
-func LoadConfig() *Config {
-	dbNum := 0
-	if dbStr := os.Getenv("REDIS_DB"); dbStr != "" {
-		if num, err := strconv.Atoi(dbStr); err == nil {
-			dbNum = num
-		}
-	}
-
-	return &Config{
-		Host:     getEnv("REDIS_HOST", "localhost"),
-		Port:     getEnv("REDIS_PORT", "6379"),
-		Password: getEnv("REDIS_PASSWORD", ""),
-		DB:       dbNum,
-	}
-}
-
-// Connect establishes a connection to Redis
-func Connect() error {
-	config := LoadConfig()
-	
+// Connect establishes a connection to the Redis server described by config
+func Connect(config *Config) error {
 	RedisClient = redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%s", config.Host, config.Port),
 		Password: config.Password,
@@ -57,7 +36,7 @@ func Connect() error {
 		return fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
-	log.Println("Redis connection established successfully")
+	slog.Info("Redis connection established")
 	return nil
 }
 
@@ -106,11 +85,3 @@ func Exists(ctx context.Context, key string) (bool, error) {
 	result, err := RedisClient.Exists(ctx, key).Result()
 	return result > 0, err
 }
-
-// getEnv gets environment variable with fallback
-func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return fallback
-}
\ No newline at end of file
@@ -2,7 +2,6 @@ package cache
 
 import (
 	"context"
-	"os"
 	"testing"
 	"time"
 
@@ -20,42 +19,6 @@ func mockRedisClient() *redis.Client {
 	})
 }
 
-func TestRedisConfig(t *testing.T) {
-	t.Run("Load Config with Environment Variables", func(t *testing.T) {
-		os.Setenv("REDIS_HOST", "testhost")
-		os.Setenv("REDIS_PORT", "6380")
-		os.Setenv("REDIS_PASSWORD", "testpass")
-		os.Setenv("REDIS_DB", "5")
-
-		config := LoadConfig()
-		assert.Equal(t, "testhost", config.Host)
-		assert.Equal(t, "6380", config.Port)
-		assert.Equal(t, "testpass", config.Password)
-		assert.Equal(t, 5, config.DB)
-
-		// Clean up
-		os.Unsetenv("REDIS_HOST")
-		os.Unsetenv("REDIS_PORT")
-		os.Unsetenv("REDIS_PASSWORD")
-		os.Unsetenv("REDIS_DB")
-	})
-
-	t.Run("Load Config with Defaults", func(t *testing.T) {
-		config := LoadConfig()
-		assert.Equal(t, "localhost", config.Host)
-		assert.Equal(t, "6379", config.Port)
-		assert.Equal(t, "", config.Password)
-		assert.Equal(t, 0, config.DB)
-	})
-
-	t.Run("Load Config with Invalid DB Number", func(t *testing.T) {
-		os.Setenv("REDIS_DB", "invalid")
-		config := LoadConfig()
-		assert.Equal(t, 0, config.DB) // Should default to 0 for invalid input
-		os.Unsetenv("REDIS_DB")
-	})
-}
-
 func TestRedisCacheOperations(t *testing.T) {
 	// Skip Redis tests if Redis is not available
 	// In a real environment, you would set up a test Redis instance
@@ -194,25 +157,6 @@ func TestRedisConnectionHandling(t *testing.T) {
 		t.Skip("Skipping Redis connection tests in short mode")
 	}
 
-	t.Run("Connect with Valid Configuration", func(t *testing.T) {
-		// Set test environment
-		os.Setenv("REDIS_HOST", "localhost")
-		os.Setenv("REDIS_PORT", "6379")
-		os.Setenv("REDIS_DB", "15")
-
-		// This would normally test actual connection
-		// In a real test environment, you'd have a test Redis instance
-		config := LoadConfig()
-		assert.Equal(t, "localhost", config.Host)
-		assert.Equal(t, "6379", config.Port)
-		assert.Equal(t, 15, config.DB)
-
-		// Clean up
-		os.Unsetenv("REDIS_HOST")
-		os.Unsetenv("REDIS_PORT")
-		os.Unsetenv("REDIS_DB")
-	})
-
 	t.Run("GetClient Returns Client", func(t *testing.T) {
 		originalClient := RedisClient
 		testClient := mockRedisClient()
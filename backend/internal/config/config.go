@@ -0,0 +1,742 @@
+// Package config loads the server's configuration from a YAML file,
+// environment variables and secret files, and validates it at startup.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/cache"
+	"zombie-car-game-backend/internal/database"
+	"zombie-car-game-backend/internal/lifecycle"
+	"zombie-car-game-backend/internal/logging"
+	"zombie-car-game-backend/internal/mail"
+	"zombie-car-game-backend/internal/middleware"
+	"zombie-car-game-backend/internal/offline"
+	"zombie-car-game-backend/internal/payments"
+	"zombie-car-game-backend/internal/tracing"
+)
+
+// RedactedValue replaces secret values in Redacted
+const RedactedValue = "[REDACTED]"
+
+// Gin modes accepted for server.mode
+const (
+	ModeDebug   = "debug"
+	ModeRelease = "release"
+	ModeTest    = "test"
+)
+
+// Config is the server's configuration.
+//
+// Every field has a default, may be set in the YAML file named by CONFIG_FILE
+// under its yaml path, and may be overridden by the environment variable in its
+// env tag. Secrets can also be read from the file named by <VAR>_FILE, as
+// Docker and Kubernetes mount them.
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Redis     RedisConfig     `yaml:"redis"`
+	Auth      AuthConfig      `yaml:"auth"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Logging   LoggingConfig   `yaml:"logging"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Retention RetentionConfig `yaml:"retention"`
+	Mail      MailConfig      `yaml:"mail"`
+	Payments  PaymentsConfig  `yaml:"payments"`
+	Offline   OfflineConfig   `yaml:"offline"`
+}
+
+// ServerConfig configures the HTTP server
+type ServerConfig struct {
+	// Host is the address to listen on; empty listens on every interface
+	Host              string        `yaml:"host" env:"HOST"`
+	Port              string        `yaml:"port" env:"PORT"`
+	Mode              string        `yaml:"mode" env:"GIN_MODE"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	DrainDelay        time.Duration `yaml:"drain_delay" env:"SHUTDOWN_DRAIN_DELAY"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	// TrustedProxies are the addresses or CIDRs of the load balancers in front of
+	// the server, whose X-Forwarded-For is believed for the client IP. Leave it
+	// empty when clients connect directly.
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+}
+
+// DatabaseConfig configures the database connection. The host settings are
+// for Postgres; SQLite only needs a path.
+type DatabaseConfig struct {
+	Driver             string        `yaml:"driver" env:"DB_DRIVER"`
+	Path               string        `yaml:"path" env:"DB_PATH"`
+	Host               string        `yaml:"host" env:"DB_HOST"`
+	Port               string        `yaml:"port" env:"DB_PORT"`
+	User               string        `yaml:"user" env:"DB_USER"`
+	Password           string        `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name               string        `yaml:"name" env:"DB_NAME"`
+	SSLMode            string        `yaml:"sslmode" env:"DB_SSLMODE"`
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" env:"DB_SLOW_QUERY_THRESHOLD"`
+}
+
+// RedisConfig configures the Redis connection
+type RedisConfig struct {
+	Host     string `yaml:"host" env:"REDIS_HOST"`
+	Port     string `yaml:"port" env:"REDIS_PORT"`
+	Password string `yaml:"password" env:"REDIS_PASSWORD" secret:"true"`
+	DB       int    `yaml:"db" env:"REDIS_DB"`
+}
+
+// AuthConfig configures token signing
+type AuthConfig struct {
+	JWTSecret      string        `yaml:"jwt_secret" env:"JWT_SECRET" secret:"true"`
+	JWTKeysFile    string        `yaml:"jwt_keys_file" env:"JWT_KEYS_FILE"`
+	KeyGracePeriod time.Duration `yaml:"key_grace_period" env:"JWT_KEY_GRACE_PERIOD"`
+	AccessTokenTTL time.Duration `yaml:"access_token_ttl" env:"JWT_ACCESS_TOKEN_TTL"`
+	// RefreshTokenTTL is how long a login stays alive without activity
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl" env:"JWT_REFRESH_TOKEN_TTL"`
+	// TOTPIssuer is the account name shown in authenticator apps
+	TOTPIssuer string `yaml:"totp_issuer" env:"TOTP_ISSUER"`
+}
+
+// RateLimitConfig overrides the default rate limit policies, each written as
+// <limit>/<window>, e.g. 20/1m. Empty keeps the default.
+type RateLimitConfig struct {
+	Auth     string `yaml:"auth" env:"RATE_LIMIT_AUTH"`
+	Register string `yaml:"register" env:"RATE_LIMIT_REGISTER"`
+	Read     string `yaml:"read" env:"RATE_LIMIT_READ"`
+	Write    string `yaml:"write" env:"RATE_LIMIT_WRITE"`
+	Score    string `yaml:"score" env:"RATE_LIMIT_SCORE"`
+}
+
+// LoggingConfig configures structured logging
+type LoggingConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL"`
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+	File   string `yaml:"file" env:"LOG_FILE"`
+}
+
+// TracingConfig configures OpenTelemetry tracing. The OTLP exporter reads its
+// endpoint and headers from the standard OTEL_EXPORTER_OTLP_* variables.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"OTEL_TRACES_EXPORTER"`
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+	File        string  `yaml:"file" env:"OTEL_TRACES_FILE"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"OTEL_TRACES_SAMPLER_ARG"`
+}
+
+// RetentionConfig sets how long data is kept. Zero keeps it forever.
+type RetentionConfig struct {
+	GuestInactivity     time.Duration `yaml:"guest_inactivity" env:"GUEST_INACTIVITY_PERIOD"`
+	DeletionGracePeriod time.Duration `yaml:"deletion_grace_period" env:"ACCOUNT_DELETION_GRACE_PERIOD"`
+	Audit               time.Duration `yaml:"audit" env:"AUDIT_RETENTION_PERIOD"`
+}
+
+// MailConfig configures outgoing email
+type MailConfig struct {
+	Sender       string `yaml:"sender" env:"MAIL_SENDER"`
+	From         string `yaml:"from" env:"MAIL_FROM"`
+	SMTPHost     string `yaml:"smtp_host" env:"SMTP_HOST"`
+	SMTPPort     string `yaml:"smtp_port" env:"SMTP_PORT"`
+	SMTPUsername string `yaml:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword string `yaml:"smtp_password" env:"SMTP_PASSWORD" secret:"true"`
+	FileDir      string `yaml:"file_dir" env:"MAIL_FILE_DIR"`
+	// AppBaseURL is the frontend that links in account emails point to
+	AppBaseURL string `yaml:"app_base_url" env:"APP_BASE_URL"`
+}
+
+// PaymentsConfig configures store receipt verification
+type PaymentsConfig struct {
+	SteamWebAPIKey  string `yaml:"steam_web_api_key" env:"STEAM_WEB_API_KEY" secret:"true"`
+	SteamAppID      string `yaml:"steam_app_id" env:"STEAM_APP_ID"`
+	SteamUseSandbox bool   `yaml:"steam_use_sandbox" env:"STEAM_USE_SANDBOX"`
+	FakeVerifier    bool   `yaml:"fake_verifier" env:"PAYMENTS_FAKE_VERIFIER"`
+}
+
+// OfflineConfig runs the server embedded in the desktop build. Enabling it
+// binds the server to localhost and keeps a SQLite database and a signing
+// secret of its own in the data directory, replacing the database settings.
+type OfflineConfig struct {
+	Enabled bool `yaml:"enabled" env:"OFFLINE_MODE"`
+	// DataDir defaults to the app's directory under the user's app-data directory
+	DataDir string `yaml:"data_dir" env:"OFFLINE_DATA_DIR"`
+	// StaticDir holds the built frontend, served for paths outside the API
+	StaticDir string `yaml:"static_dir" env:"OFFLINE_STATIC_DIR"`
+	// SyncURL is the online server that locally played sessions are synced to
+	SyncURL string `yaml:"sync_url" env:"OFFLINE_SYNC_URL"`
+}
+
+// Default returns the configuration used when nothing is set, suitable for local development
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:              "8080",
+			Mode:              ModeDebug,
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			DrainDelay:        5 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Driver:             database.DriverPostgres,
+			Path:               "zombie_game.db",
+			Host:               "localhost",
+			Port:               "5432",
+			User:               "gameuser",
+			Password:           "gamepass",
+			Name:               "zombie_game",
+			SSLMode:            "disable",
+			SlowQueryThreshold: logging.DefaultSlowQueryThreshold,
+		},
+		Redis: RedisConfig{
+			Host: "localhost",
+			Port: "6379",
+		},
+		Auth: AuthConfig{
+			KeyGracePeriod:  auth.DefaultKeyGracePeriod,
+			AccessTokenTTL:  auth.DefaultAccessTokenTTL,
+			RefreshTokenTTL: 30 * 24 * time.Hour,
+			TOTPIssuer:      "Zombie Car Game",
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: TracingConfig{
+			Exporter:    tracing.ExporterNone,
+			ServiceName: "zombie-car-game-backend",
+			File:        "traces.jsonl",
+			SampleRatio: 1,
+		},
+		Retention: RetentionConfig{
+			GuestInactivity:     30 * 24 * time.Hour,
+			DeletionGracePeriod: 30 * 24 * time.Hour,
+			Audit:               2 * 365 * 24 * time.Hour,
+		},
+		Mail: MailConfig{
+			Sender:     "log",
+			From:       "Zombie Car Game <no-reply@localhost>",
+			SMTPPort:   "587",
+			FileDir:    "tmp/mail",
+			AppBaseURL: "http://localhost:3000",
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, then the YAML file at path
+// (skipped when path is empty), then the environment, and validates it. All
+// problems found are reported together.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+	cfg.normalize()
+	if cfg.Offline.Enabled {
+		if err := cfg.applyOffline(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadFile reads the YAML file at path over cfg. Unknown keys are rejected so
+// typos do not silently fall back to defaults.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv applies environment variables, and the <VAR>_FILE variants of secrets
+func (c *Config) loadEnv() error {
+	var errs []error
+	for _, f := range c.fields() {
+		value, set, err := lookupEnv(f)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !set {
+			continue
+		}
+		if err := setField(f.value, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.env, err))
+		}
+	}
+	return joinErrors(errs)
+}
+
+// lookupEnv returns the value of f's environment variable, or for a secret the
+// contents of the file named by <VAR>_FILE. Setting both is an error, since one
+// of them would be silently ignored.
+func lookupEnv(f field) (string, bool, error) {
+	value, set := os.LookupEnv(f.env)
+	if set && value == "" {
+		// An empty variable, as .env files commonly leave them, does not override
+		set = false
+	}
+	if !f.secret {
+		return value, set, nil
+	}
+
+	path := os.Getenv(f.env + "_FILE")
+	if path == "" {
+		return value, set, nil
+	}
+	if set {
+		return "", false, fmt.Errorf("%s and %s_FILE are both set", f.env, f.env)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("%s_FILE: %w", f.env, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+// setField parses value into the field v
+func setField(v reflect.Value, value string) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		v.SetBool(b)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		// A comma-separated list; an empty value clears it
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// normalize accepts the spellings the previous environment handling allowed
+func (c *Config) normalize() {
+	c.Database.Driver = strings.ToLower(c.Database.Driver)
+	c.Logging.Format = strings.ToLower(c.Logging.Format)
+	c.Tracing.Exporter = strings.ToLower(c.Tracing.Exporter)
+	if c.Tracing.Exporter == "console" {
+		c.Tracing.Exporter = tracing.ExporterStdout
+	}
+}
+
+// applyOffline sets up the embedded desktop server: localhost only, SQLite and
+// a per-install signing secret in the data directory, and no guest cleanup, as
+// a local guest's progress must survive until it is synced
+func (c *Config) applyOffline() error {
+	if c.Offline.DataDir == "" {
+		dir, err := offline.DefaultDataDir()
+		if err != nil {
+			return err
+		}
+		c.Offline.DataDir = dir
+	}
+
+	if c.Server.Host == "" {
+		c.Server.Host = "127.0.0.1"
+	}
+	c.Database.Driver = database.DriverSQLite
+	c.Database.Path = filepath.Join(c.Offline.DataDir, "zombie_game.db")
+	c.Retention.GuestInactivity = 0
+
+	if c.Auth.JWTSecret == "" && c.Auth.JWTKeysFile == "" {
+		secret, err := offline.LoadSecret(c.Offline.DataDir)
+		if err != nil {
+			return err
+		}
+		c.Auth.JWTSecret = secret
+	}
+	return nil
+}
+
+// Validate checks every setting and reports all invalid ones, naming each by
+// its YAML path and environment variable
+func (c *Config) Validate() error {
+	v := &validator{fields: c.fields()}
+
+	v.check("server.port", validPort(c.Server.Port), "must be a port number, got %q", c.Server.Port)
+	v.check("server.mode", oneOf(c.Server.Mode, ModeDebug, ModeRelease, ModeTest), "must be debug, release or test, got %q", c.Server.Mode)
+	for _, proxy := range c.Server.TrustedProxies {
+		v.check("server.trusted_proxies", validIPOrCIDR(proxy), "must be IP addresses or CIDRs, got %q", proxy)
+	}
+	durations := []struct {
+		path  string
+		value time.Duration
+	}{
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.drain_delay", c.Server.DrainDelay},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+		{"database.slow_query_threshold", c.Database.SlowQueryThreshold},
+		{"auth.key_grace_period", c.Auth.KeyGracePeriod},
+		{"retention.guest_inactivity", c.Retention.GuestInactivity},
+		{"retention.deletion_grace_period", c.Retention.DeletionGracePeriod},
+		{"retention.audit", c.Retention.Audit},
+	}
+	for _, d := range durations {
+		v.check(d.path, d.value >= 0, "must not be negative, got %s", d.value)
+	}
+
+	v.check("database.driver", oneOf(c.Database.Driver, database.DriverPostgres, database.DriverSQLite),
+		"must be postgres or sqlite, got %q", c.Database.Driver)
+	switch c.Database.Driver {
+	case database.DriverPostgres:
+		v.check("database.host", c.Database.Host != "", "is required")
+		v.check("database.port", validPort(c.Database.Port), "must be a port number, got %q", c.Database.Port)
+		v.check("database.user", c.Database.User != "", "is required")
+		v.check("database.name", c.Database.Name != "", "is required")
+		v.check("database.sslmode", oneOf(c.Database.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full"),
+			"must be a libpq sslmode, got %q", c.Database.SSLMode)
+	case database.DriverSQLite:
+		v.check("database.path", c.Database.Path != "", "is required")
+	}
+
+	v.check("redis.port", validPort(c.Redis.Port), "must be a port number, got %q", c.Redis.Port)
+	v.check("redis.db", c.Redis.DB >= 0, "must not be negative, got %d", c.Redis.DB)
+
+	v.check("auth.access_token_ttl", c.Auth.AccessTokenTTL > 0, "must be positive, got %s", c.Auth.AccessTokenTTL)
+	v.check("auth.refresh_token_ttl", c.Auth.RefreshTokenTTL > 0, "must be positive, got %s", c.Auth.RefreshTokenTTL)
+	v.check("auth.totp_issuer", c.Auth.TOTPIssuer != "", "is required")
+	if c.Auth.JWTKeysFile != "" {
+		_, err := os.Stat(c.Auth.JWTKeysFile)
+		v.check("auth.jwt_keys_file", err == nil, "cannot be read: %v", err)
+	}
+
+	for _, p := range c.rateLimitPolicies() {
+		_, err := middleware.ParseRateLimitPolicy(p.policy, p.value)
+		v.check("rate_limit."+p.policy.Name, err == nil, "must be <limit>/<window> such as 20/1m, got %q", p.value)
+	}
+
+	var level slog.Level
+	v.check("logging.level", level.UnmarshalText([]byte(c.Logging.Level)) == nil, "must be debug, info, warn or error, got %q", c.Logging.Level)
+	v.check("logging.format", oneOf(c.Logging.Format, "json", "text"), "must be json or text, got %q", c.Logging.Format)
+
+	v.check("tracing.exporter", oneOf(c.Tracing.Exporter, tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterFile, tracing.ExporterOTLP),
+		"must be none, stdout, file or otlp, got %q", c.Tracing.Exporter)
+	v.check("tracing.service_name", c.Tracing.ServiceName != "", "is required")
+	v.check("tracing.sample_ratio", c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+
+	v.check("mail.sender", oneOf(c.Mail.Sender, "log", "file", "smtp"), "must be log, file or smtp, got %q", c.Mail.Sender)
+	u, err := url.Parse(c.Mail.AppBaseURL)
+	v.check("mail.app_base_url", err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+		"must be an http or https URL, got %q", c.Mail.AppBaseURL)
+	if c.Mail.Sender == "smtp" {
+		v.check("mail.smtp_host", c.Mail.SMTPHost != "", "is required for the smtp mail sender")
+		v.check("mail.smtp_port", validPort(c.Mail.SMTPPort), "must be a port number, got %q", c.Mail.SMTPPort)
+	}
+
+	v.check("payments.steam_app_id", (c.Payments.SteamWebAPIKey == "") == (c.Payments.SteamAppID == ""),
+		"must be set together with payments.steam_web_api_key")
+
+	if c.Offline.Enabled {
+		v.check("server.host", offline.IsLoopback(c.Server.Host), "must be a loopback address in offline mode, got %q", c.Server.Host)
+		if c.Offline.StaticDir != "" {
+			info, err := os.Stat(c.Offline.StaticDir)
+			v.check("offline.static_dir", err == nil && info.IsDir(), "must be a directory holding the built frontend")
+		}
+	}
+	if c.Offline.SyncURL != "" {
+		u, err := url.Parse(c.Offline.SyncURL)
+		v.check("offline.sync_url", err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"must be an http or https URL, got %q", c.Offline.SyncURL)
+	}
+
+	return joinErrors(v.errs)
+}
+
+// ReleaseMode reports whether the server runs in Gin's release mode
+func (c *Config) ReleaseMode() bool {
+	return c.Server.Mode == ModeRelease
+}
+
+// Addr returns the address the HTTP server listens on
+func (c *Config) Addr() string {
+	return net.JoinHostPort(c.Server.Host, c.Server.Port)
+}
+
+// ServerConfig returns the HTTP server's timeouts
+func (c *Config) ServerConfig() lifecycle.ServerConfig {
+	return lifecycle.ServerConfig{
+		ReadTimeout:       c.Server.ReadTimeout,
+		ReadHeaderTimeout: c.Server.ReadHeaderTimeout,
+		WriteTimeout:      c.Server.WriteTimeout,
+		IdleTimeout:       c.Server.IdleTimeout,
+		DrainDelay:        c.Server.DrainDelay,
+		ShutdownTimeout:   c.Server.ShutdownTimeout,
+	}
+}
+
+// DatabaseConfig returns the database connection settings
+func (c *Config) DatabaseConfig() *database.Config {
+	return &database.Config{
+		Driver:             c.Database.Driver,
+		Path:               c.Database.Path,
+		Host:               c.Database.Host,
+		Port:               c.Database.Port,
+		User:               c.Database.User,
+		Password:           c.Database.Password,
+		DBName:             c.Database.Name,
+		SSLMode:            c.Database.SSLMode,
+		SlowQueryThreshold: c.Database.SlowQueryThreshold,
+	}
+}
+
+// CacheConfig returns the Redis connection settings
+func (c *Config) CacheConfig() *cache.Config {
+	return &cache.Config{
+		Host:     c.Redis.Host,
+		Port:     c.Redis.Port,
+		Password: c.Redis.Password,
+		DB:       c.Redis.DB,
+	}
+}
+
+// KeyConfig returns where the token signing keys come from
+func (c *Config) KeyConfig() auth.KeyConfig {
+	return auth.KeyConfig{
+		Secret:      c.Auth.JWTSecret,
+		KeysFile:    c.Auth.JWTKeysFile,
+		GracePeriod: c.Auth.KeyGracePeriod,
+	}
+}
+
+// RateLimitPolicy returns policy with its configured override applied. Validate
+// has checked the overrides; unknown policies are returned unchanged.
+func (c *Config) RateLimitPolicy(policy middleware.RateLimitPolicy) middleware.RateLimitPolicy {
+	for _, p := range c.rateLimitPolicies() {
+		if p.policy.Name == policy.Name {
+			policy, _ = middleware.ParseRateLimitPolicy(policy, p.value)
+			break
+		}
+	}
+	return policy
+}
+
+// rateLimitOverride is a default rate limit policy and its configured override
+type rateLimitOverride struct {
+	policy middleware.RateLimitPolicy
+	value  string
+}
+
+// rateLimitPolicies pairs each default rate limit policy with its override
+func (c *Config) rateLimitPolicies() []rateLimitOverride {
+	return []rateLimitOverride{
+		{middleware.RateLimitAuth, c.RateLimit.Auth},
+		{middleware.RateLimitRegister, c.RateLimit.Register},
+		{middleware.RateLimitRead, c.RateLimit.Read},
+		{middleware.RateLimitWrite, c.RateLimit.Write},
+		{middleware.RateLimitScore, c.RateLimit.Score},
+	}
+}
+
+// LoggingConfig returns the logger settings
+func (c *Config) LoggingConfig() logging.Config {
+	cfg := logging.Config{Format: c.Logging.Format, File: c.Logging.File}
+	// Validate has checked the level
+	_ = cfg.Level.UnmarshalText([]byte(c.Logging.Level))
+	return cfg
+}
+
+// TracingConfig returns the tracing exporter settings
+func (c *Config) TracingConfig() tracing.Config {
+	return tracing.Config{
+		Exporter:    c.Tracing.Exporter,
+		ServiceName: c.Tracing.ServiceName,
+		File:        c.Tracing.File,
+		SampleRatio: c.Tracing.SampleRatio,
+	}
+}
+
+// MailConfig returns the mail sender settings
+func (c *Config) MailConfig() mail.Config {
+	return mail.Config{
+		Sender:       c.Mail.Sender,
+		From:         c.Mail.From,
+		SMTPHost:     c.Mail.SMTPHost,
+		SMTPPort:     c.Mail.SMTPPort,
+		SMTPUsername: c.Mail.SMTPUsername,
+		SMTPPassword: c.Mail.SMTPPassword,
+		FileDir:      c.Mail.FileDir,
+	}
+}
+
+// PaymentsConfig returns the receipt verifier settings. The fake verifier is
+// never enabled in release mode.
+func (c *Config) PaymentsConfig() payments.Config {
+	return payments.Config{
+		SteamWebAPIKey:  c.Payments.SteamWebAPIKey,
+		SteamAppID:      c.Payments.SteamAppID,
+		SteamUseSandbox: c.Payments.SteamUseSandbox,
+		FakeVerifier:    c.Payments.FakeVerifier && !c.ReleaseMode(),
+	}
+}
+
+// Redacted returns the configuration as nested maps keyed by YAML path, with
+// every secret that is set replaced by RedactedValue, for display to operators
+func (c *Config) Redacted() map[string]map[string]interface{} {
+	out := make(map[string]map[string]interface{})
+	for _, f := range c.fields() {
+		section, name, _ := strings.Cut(f.path, ".")
+		if out[section] == nil {
+			out[section] = make(map[string]interface{})
+		}
+
+		var value interface{} = f.value.Interface()
+		switch {
+		case f.secret && f.value.String() != "":
+			value = RedactedValue
+		case f.value.Type() == reflect.TypeOf(time.Duration(0)):
+			value = time.Duration(f.value.Int()).String()
+		}
+		out[section][name] = value
+	}
+	return out
+}
+
+// field is one setting, located by its YAML path and environment variable
+type field struct {
+	path   string
+	env    string
+	secret bool
+	value  reflect.Value
+}
+
+// fields lists the settings of c in declaration order
+func (c *Config) fields() []field {
+	var fields []field
+	root := reflect.ValueOf(c).Elem()
+	for i := 0; i < root.NumField(); i++ {
+		section := root.Field(i)
+		sectionName := root.Type().Field(i).Tag.Get("yaml")
+		for j := 0; j < section.NumField(); j++ {
+			tag := section.Type().Field(j).Tag
+			fields = append(fields, field{
+				path:   sectionName + "." + tag.Get("yaml"),
+				env:    tag.Get("env"),
+				secret: tag.Get("secret") == "true",
+				value:  section.Field(j),
+			})
+		}
+	}
+	return fields
+}
+
+// validator collects validation errors
+type validator struct {
+	fields []field
+	errs   []error
+}
+
+// check records an error for the setting at path unless ok
+func (v *validator) check(path string, ok bool, format string, args ...interface{}) {
+	if ok {
+		return
+	}
+	name := path
+	for _, f := range v.fields {
+		if f.path == path {
+			name = fmt.Sprintf("%s (%s)", path, f.env)
+			break
+		}
+	}
+	v.errs = append(v.errs, fmt.Errorf("%s %s", name, fmt.Sprintf(format, args...)))
+}
+
+// joinErrors combines errs under a single heading, or returns nil if there are none
+func joinErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
+
+func validIPOrCIDR(value string) bool {
+	if _, _, err := net.ParseCIDR(value); err == nil {
+		return true
+	}
+	return net.ParseIP(value) != nil
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
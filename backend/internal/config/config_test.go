@@ -0,0 +1,284 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"zombie-car-game-backend/internal/cache"
+	"zombie-car-game-backend/internal/database"
+	"zombie-car-game-backend/internal/logging"
+	"zombie-car-game-backend/internal/middleware"
+	"zombie-car-game-backend/internal/tracing"
+)
+
+// clearEnv unsets every variable the config reads, so the developer's shell does not leak into tests
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, f := range Default().fields() {
+		t.Setenv(f.env, "")
+		if f.secret {
+			t.Setenv(f.env+"_FILE", "")
+		}
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	clearEnv(t)
+
+	cfg, err := Load("")
+	require.NoError(t, err)
+	assert.Equal(t, Default(), cfg)
+	assert.Equal(t, ":8080", cfg.Addr())
+	assert.Equal(t, slog.LevelInfo, cfg.LoggingConfig().Level)
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	clearEnv(t)
+	path := writeFile(t, "config.yaml", `
+server:
+  port: "9090"
+  shutdown_timeout: 45s
+database:
+  host: db.internal
+  name: from_file
+logging:
+  level: debug
+`)
+	t.Setenv("DB_NAME", "from_env")
+	t.Setenv("OTEL_TRACES_EXPORTER", "console")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "0.25")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.10,")
+	t.Setenv("RATE_LIMIT_AUTH", "5/30s")
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "9090", cfg.Server.Port)
+	assert.Equal(t, 45*time.Second, cfg.ServerConfig().ShutdownTimeout)
+	assert.Equal(t, "db.internal", cfg.Database.Host)
+	assert.Equal(t, "from_env", cfg.Database.Name, "the environment overrides the file")
+	assert.Equal(t, "5432", cfg.Database.Port, "unset values keep their defaults")
+	assert.Equal(t, slog.LevelDebug, cfg.LoggingConfig().Level)
+	assert.Equal(t, tracing.ExporterStdout, cfg.Tracing.Exporter)
+	assert.Equal(t, 0.25, cfg.Tracing.SampleRatio)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.0.2.10"}, cfg.Server.TrustedProxies)
+
+	auth := cfg.RateLimitPolicy(middleware.RateLimitAuth)
+	assert.Equal(t, 5, auth.Limit)
+	assert.Equal(t, 30*time.Second, auth.Window)
+	assert.Equal(t, middleware.RateLimitRead, cfg.RateLimitPolicy(middleware.RateLimitRead))
+}
+
+func TestLoad_SecretFiles(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DB_PASSWORD_FILE", writeFile(t, "db_password", "s3cret\n"))
+
+	cfg, err := Load("")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", cfg.Database.Password)
+
+	// Setting a secret both ways is ambiguous
+	t.Setenv("DB_PASSWORD", "other")
+	_, err = Load("")
+	assert.ErrorContains(t, err, "DB_PASSWORD and DB_PASSWORD_FILE are both set")
+
+	// Only secrets can come from files
+	clearEnv(t)
+	t.Setenv("DB_HOST_FILE", writeFile(t, "db_host", "elsewhere"))
+	cfg, err = Load("")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", cfg.Database.Host)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	clearEnv(t)
+
+	_, err := Load(writeFile(t, "config.yaml", "databse:\n  host: typo\n"))
+	assert.ErrorContains(t, err, "databse", "unknown keys are rejected")
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+
+	t.Setenv("SHUTDOWN_TIMEOUT", "soon")
+	t.Setenv("REDIS_DB", "two")
+	_, err = Load("")
+	assert.ErrorContains(t, err, `SHUTDOWN_TIMEOUT: invalid duration "soon"`)
+	assert.ErrorContains(t, err, `REDIS_DB: invalid integer "two"`)
+}
+
+func TestDatabaseConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		clearEnv(t)
+
+		cfg, err := Load("")
+		require.NoError(t, err)
+		assert.Equal(t, &database.Config{
+			Driver:             database.DriverPostgres,
+			Path:               "zombie_game.db",
+			Host:               "localhost",
+			Port:               "5432",
+			User:               "gameuser",
+			Password:           "gamepass",
+			DBName:             "zombie_game",
+			SSLMode:            "disable",
+			SlowQueryThreshold: logging.DefaultSlowQueryThreshold,
+		}, cfg.DatabaseConfig())
+	})
+
+	t.Run("environment overrides", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv("DB_HOST", "db.example.com")
+		t.Setenv("DB_PORT", "6543")
+		t.Setenv("DB_USER", "testuser")
+		t.Setenv("DB_PASSWORD", "testpass")
+		t.Setenv("DB_NAME", "testdb")
+		t.Setenv("DB_SSLMODE", "require")
+		t.Setenv("DB_SLOW_QUERY_THRESHOLD", "50ms")
+
+		cfg, err := Load("")
+		require.NoError(t, err)
+		db := cfg.DatabaseConfig()
+		assert.Equal(t, "db.example.com", db.Host)
+		assert.Equal(t, "6543", db.Port)
+		assert.Equal(t, "testuser", db.User)
+		assert.Equal(t, "testpass", db.Password)
+		assert.Equal(t, "testdb", db.DBName)
+		assert.Equal(t, "require", db.SSLMode)
+		assert.Equal(t, 50*time.Millisecond, db.SlowQueryThreshold)
+	})
+}
+
+func TestCacheConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		clearEnv(t)
+
+		cfg, err := Load("")
+		require.NoError(t, err)
+		assert.Equal(t, &cache.Config{Host: "localhost", Port: "6379"}, cfg.CacheConfig())
+	})
+
+	t.Run("environment overrides", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv("REDIS_HOST", "testhost")
+		t.Setenv("REDIS_PORT", "6380")
+		t.Setenv("REDIS_PASSWORD", "testpass")
+		t.Setenv("REDIS_DB", "5")
+
+		cfg, err := Load("")
+		require.NoError(t, err)
+		assert.Equal(t, &cache.Config{Host: "testhost", Port: "6380", Password: "testpass", DB: 5}, cfg.CacheConfig())
+	})
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Default()
+	require.NoError(t, cfg.Validate())
+
+	cfg.Server.Port = "http"
+	cfg.Server.Mode = "production"
+	cfg.Logging.Level = "loud"
+	cfg.Tracing.SampleRatio = 2
+	cfg.Retention.Audit = -time.Hour
+	cfg.Mail.Sender = "smtp"
+	cfg.Payments.SteamWebAPIKey = "key"
+	cfg.Server.TrustedProxies = []string{"10.0.0.0/8", "lb.internal"}
+	cfg.Auth.RefreshTokenTTL = 0
+	cfg.Auth.TOTPIssuer = ""
+	cfg.RateLimit.Write = "lots"
+	cfg.Mail.AppBaseURL = "localhost:3000"
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	for _, want := range []string{
+		`server.port (PORT) must be a port number, got "http"`,
+		`server.mode (GIN_MODE) must be debug, release or test, got "production"`,
+		`logging.level (LOG_LEVEL)`,
+		`tracing.sample_ratio (OTEL_TRACES_SAMPLER_ARG) must be between 0 and 1`,
+		`retention.audit (AUDIT_RETENTION_PERIOD) must not be negative`,
+		`mail.smtp_host (SMTP_HOST) is required for the smtp mail sender`,
+		`payments.steam_app_id (STEAM_APP_ID)`,
+		`server.trusted_proxies (TRUSTED_PROXIES) must be IP addresses or CIDRs, got "lb.internal"`,
+		`auth.refresh_token_ttl (JWT_REFRESH_TOKEN_TTL) must be positive`,
+		`auth.totp_issuer (TOTP_ISSUER) is required`,
+		`rate_limit.write (RATE_LIMIT_WRITE) must be <limit>/<window> such as 20/1m, got "lots"`,
+		`mail.app_base_url (APP_BASE_URL) must be an http or https URL, got "localhost:3000"`,
+	} {
+		assert.ErrorContains(t, err, want)
+	}
+}
+
+func TestValidate_SQLite(t *testing.T) {
+	cfg := Default()
+	cfg.Database.Driver = "sqlite"
+	cfg.Database.Host = ""
+	cfg.Database.SSLMode = ""
+	require.NoError(t, cfg.Validate(), "Postgres settings are not needed for SQLite")
+
+	cfg.Database.Path = ""
+	assert.ErrorContains(t, cfg.Validate(), "database.path (DB_PATH) is required")
+
+	cfg.Database.Driver = "mysql"
+	assert.ErrorContains(t, cfg.Validate(), `database.driver (DB_DRIVER) must be postgres or sqlite, got "mysql"`)
+}
+
+func TestLoad_Offline(t *testing.T) {
+	clearEnv(t)
+	dataDir := t.TempDir()
+	t.Setenv("OFFLINE_MODE", "true")
+	t.Setenv("OFFLINE_DATA_DIR", dataDir)
+	t.Setenv("DB_DRIVER", "postgres")
+
+	cfg, err := Load("")
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:8080", cfg.Addr())
+	assert.Equal(t, "sqlite", cfg.Database.Driver, "offline mode always uses its own database")
+	assert.Equal(t, filepath.Join(dataDir, "zombie_game.db"), cfg.Database.Path)
+	assert.Zero(t, cfg.Retention.GuestInactivity)
+	require.NotEmpty(t, cfg.Auth.JWTSecret)
+
+	again, err := Load("")
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Auth.JWTSecret, again.Auth.JWTSecret, "the signing secret survives restarts")
+
+	t.Setenv("HOST", "0.0.0.0")
+	t.Setenv("OFFLINE_STATIC_DIR", filepath.Join(dataDir, "missing"))
+	t.Setenv("OFFLINE_SYNC_URL", "ftp://example.com")
+	_, err = Load("")
+	assert.ErrorContains(t, err, `server.host (HOST) must be a loopback address in offline mode, got "0.0.0.0"`)
+	assert.ErrorContains(t, err, "offline.static_dir (OFFLINE_STATIC_DIR) must be a directory holding the built frontend")
+	assert.ErrorContains(t, err, `offline.sync_url (OFFLINE_SYNC_URL) must be an http or https URL, got "ftp://example.com"`)
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.Auth.JWTSecret = "signing-secret"
+	cfg.Redis.Password = ""
+
+	redacted := cfg.Redacted()
+	assert.Equal(t, RedactedValue, redacted["auth"]["jwt_secret"])
+	assert.Equal(t, RedactedValue, redacted["database"]["password"])
+	assert.Equal(t, "", redacted["redis"]["password"], "unset secrets show as unset")
+	assert.Equal(t, "localhost", redacted["database"]["host"])
+	assert.Equal(t, "30s", redacted["server"]["shutdown_timeout"])
+	assert.NotContains(t, redacted["auth"], "JWTSecret")
+}
+
+func TestPaymentsConfig_NoFakeVerifierInRelease(t *testing.T) {
+	cfg := Default()
+	cfg.Payments.FakeVerifier = true
+	assert.True(t, cfg.PaymentsConfig().FakeVerifier)
+
+	cfg.Server.Mode = ModeRelease
+	assert.False(t, cfg.PaymentsConfig().FakeVerifier)
+}
@@ -2,81 +2,107 @@ package database
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"time"
 
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	"zombie-car-game-backend/internal/logging"
 	"zombie-car-game-backend/internal/models"
 )
 
 var DB *gorm.DB
 
+// Database drivers
+const (
+	DriverPostgres = "postgres"
+	// DriverSQLite keeps everything in a single file, for local development and
+	// single-node servers; it needs a cgo build
+	DriverSQLite = "sqlite"
+)
+
 // Config holds database configuration
 type Config struct {
+	// Driver is DriverPostgres or DriverSQLite
+	Driver   string
 	Host     string
 	Port     string
 	User     string
 	Password string
 	DBName   string
 	SSLMode  string
+	// Path is the SQLite database file
+	Path string
+	// SlowQueryThreshold is how long a query may take before it is logged as
+	// slow; 0 disables slow query warnings
+	SlowQueryThreshold time.Duration
 }
 
-// LoadConfig loads database configuration from environment variables
-func LoadConfig() *Config {
-	return &Config{
-		Host:     getEnv("DB_HOST", "localhost"),
-		Port:     getEnv("DB_PORT", "5432"),
-		User:     getEnv("DB_USER", "gameuser"),
-		Password: getEnv("DB_PASSWORD", "gamepass"),
-		DBName:   getEnv("DB_NAME", "zombie_game"),
-		SSLMode:  getEnv("DB_SSLMODE", "disable"),
+// Connect establishes a connection to the database described by config
+func Connect(config *Config) error {
+	db, err := Open(config)
+	if err != nil {
+		return err
 	}
-}
 
-// Connect establishes a connection to the database
-func Connect() error {
-	config := LoadConfig()
-	
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode,
-	)
+	DB = db
+	slog.Info("Database connection established", "driver", db.Dialector.Name())
+	return nil
+}
 
-	// Configure GORM logger
-	var gormLogger logger.Interface
-	if os.Getenv("GIN_MODE") == "release" {
-		gormLogger = logger.Default.LogMode(logger.Silent)
-	} else {
-		gormLogger = logger.Default.LogMode(logger.Info)
+// Open connects to the database described by config without making it the
+// package's connection
+func Open(config *Config) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch config.Driver {
+	case DriverPostgres, "":
+		dsn := fmt.Sprintf(
+			"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode,
+		)
+		dialector = postgres.Open(dsn)
+	case DriverSQLite:
+		if dir := filepath.Dir(config.Path); dir != "." {
+			if err := os.MkdirAll(dir, 0o700); err != nil {
+				return nil, fmt.Errorf("failed to create database directory: %w", err)
+			}
+		}
+		// Enforce foreign keys, let readers run alongside the writer, wait for
+		// the write lock instead of failing, and take it when a transaction begins
+		// so concurrent transactions queue rather than deadlock
+		dsn := config.Path + "?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000&_txlock=immediate"
+		dialector = sqlite.Open(dsn)
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", config.Driver)
 	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+	// Send GORM's logs through slog; LOG_LEVEL=debug shows every query
+	gormLogger := logging.NewGormLogger(config.SlowQueryThreshold)
+
+	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: gormLogger,
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
 	})
-	
 	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
 	// Configure connection pool
 	sqlDB, err := db.DB()
 	if err != nil {
-		return fmt.Errorf("failed to get underlying sql.DB: %w", err)
+		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
 	}
 
 	sqlDB.SetMaxIdleConns(10)
 	sqlDB.SetMaxOpenConns(100)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
-	DB = db
-	log.Println("Database connection established successfully")
-	return nil
+	return db, nil
 }
 
 // AutoMigrate runs database migrations
@@ -90,13 +116,28 @@ func AutoMigrate() error {
 		&models.OwnedVehicle{},
 		&models.GameSession{},
 		&models.LevelProgress{},
+		&models.InventoryItem{},
+		&models.PromoCode{},
+		&models.PromoRedemption{},
+		&models.WalletTransaction{},
+		&models.Purchase{},
+		&models.StoreAccount{},
+		&models.RefreshToken{},
+		&models.AccountToken{},
+		&models.OutboxEmail{},
+		&models.AuditLog{},
+		&models.TwoFactorCredential{},
+		&models.RecoveryCode{},
+		&models.DeviceSession{},
+		&models.Sanction{},
+		&models.SyncedSession{},
 	)
 	
 	if err != nil {
 		return fmt.Errorf("failed to run auto migration: %w", err)
 	}
 
-	log.Println("Database migration completed successfully")
+	slog.Info("Database migration completed")
 	return nil
 }
 
@@ -118,11 +159,3 @@ func Close() error {
 func GetDB() *gorm.DB {
 	return DB
 }
-
-// getEnv gets environment variable with fallback
-func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return fallback
-}
\ No newline at end of file
@@ -1,7 +1,6 @@
 package database
 
 import (
-	"os"
 	"testing"
 	"time"
 
@@ -11,44 +10,6 @@ import (
 	"zombie-car-game-backend/internal/models"
 )
 
-func TestDatabaseConnection(t *testing.T) {
-	// Set test environment variables
-	os.Setenv("DB_HOST", "localhost")
-	os.Setenv("DB_PORT", "5432")
-	os.Setenv("DB_USER", "testuser")
-	os.Setenv("DB_PASSWORD", "testpass")
-	os.Setenv("DB_NAME", "testdb")
-	os.Setenv("DB_SSLMODE", "disable")
-
-	t.Run("Load Config", func(t *testing.T) {
-		config := LoadConfig()
-		assert.Equal(t, "localhost", config.Host)
-		assert.Equal(t, "5432", config.Port)
-		assert.Equal(t, "testuser", config.User)
-		assert.Equal(t, "testpass", config.Password)
-		assert.Equal(t, "testdb", config.DBName)
-		assert.Equal(t, "disable", config.SSLMode)
-	})
-
-	t.Run("Load Config with Defaults", func(t *testing.T) {
-		// Clear environment variables
-		os.Unsetenv("DB_HOST")
-		os.Unsetenv("DB_PORT")
-		os.Unsetenv("DB_USER")
-		os.Unsetenv("DB_PASSWORD")
-		os.Unsetenv("DB_NAME")
-		os.Unsetenv("DB_SSLMODE")
-
-		config := LoadConfig()
-		assert.Equal(t, "localhost", config.Host)
-		assert.Equal(t, "5432", config.Port)
-		assert.Equal(t, "gameuser", config.User)
-		assert.Equal(t, "gamepass", config.Password)
-		assert.Equal(t, "zombie_game", config.DBName)
-		assert.Equal(t, "disable", config.SSLMode)
-	})
-}
-
 func TestAutoMigrate(t *testing.T) {
 	// Create in-memory SQLite database for testing
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
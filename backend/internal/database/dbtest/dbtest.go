@@ -0,0 +1,97 @@
+// Package dbtest opens databases for tests.
+package dbtest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"zombie-car-game-backend/internal/database"
+)
+
+// Open opens an empty database with the schema of the given models.
+//
+// Tests run on a SQLite file of their own, configured as the server configures
+// it. With TEST_DB_DRIVER=postgres they instead run against the Postgres
+// database named by TEST_DB_DSN, each test in a schema of its own that is
+// dropped when the test ends.
+func Open(t testing.TB, schema ...interface{}) *gorm.DB {
+	t.Helper()
+
+	var db *gorm.DB
+	switch driver := os.Getenv("TEST_DB_DRIVER"); driver {
+	case "", database.DriverSQLite:
+		var err error
+		db, err = database.Open(&database.Config{
+			Driver: database.DriverSQLite,
+			Path:   filepath.Join(t.TempDir(), "test.db"),
+		})
+		if err != nil {
+			t.Skip("SQLite requires CGO, skipping database tests")
+			return nil
+		}
+	case database.DriverPostgres:
+		db = openPostgres(t)
+	default:
+		t.Fatalf("unknown TEST_DB_DRIVER %q", driver)
+	}
+
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	require.NoError(t, db.AutoMigrate(schema...))
+	return db
+}
+
+// openPostgres connects to TEST_DB_DSN with a fresh schema as the search path
+func openPostgres(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		t.Fatal("TEST_DB_DSN is required when TEST_DB_DRIVER=postgres")
+	}
+	config := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+
+	admin, err := gorm.Open(postgres.Open(dsn), config)
+	require.NoError(t, err)
+
+	suffix := make([]byte, 6)
+	_, err = rand.Read(suffix)
+	require.NoError(t, err)
+	schema := "test_" + hex.EncodeToString(suffix)
+	require.NoError(t, admin.Exec("CREATE SCHEMA "+schema).Error)
+
+	// Registered first so it runs after the test's connection is closed
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	// DSNs are either URLs or libpq key=value lists
+	switch {
+	case !strings.Contains(dsn, "://"):
+		dsn += " search_path=" + schema
+	case strings.Contains(dsn, "?"):
+		dsn += "&search_path=" + schema
+	default:
+		dsn += "?search_path=" + schema
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), config)
+	require.NoError(t, err)
+	return db
+}
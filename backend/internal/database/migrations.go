@@ -1,19 +1,42 @@
 package database
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io/fs"
-	"path/filepath"
+	"log/slog"
+	"os"
+	"path"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationLockKey stops server instances migrating the same database at once (Postgres only)
+const migrationLockKey = 0x6d696772 // "migr"
+
+var (
+	ErrMigrationModified = errors.New("applied migration has been modified")
+	ErrMigrationMissing  = errors.New("applied migration has no file")
+	ErrNoDownMigration   = errors.New("migration has no down file")
+	ErrUnknownVersion    = errors.New("unknown migration version")
 )
 
+// migrationFilePattern matches NNN_name.up.sql and NNN_name.down.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
 // Migration represents a database migration
 type Migration struct {
-	ID        uint      `gorm:"primaryKey"`
-	Version   string    `gorm:"uniqueIndex;size:50"`
-	Name      string    `gorm:"size:255"`
+	ID      uint   `gorm:"primaryKey"`
+	Version string `gorm:"uniqueIndex;size:50"`
+	Name    string `gorm:"size:255"`
+	// Checksum is the SHA-256 of the up file when it was applied
+	Checksum  string    `gorm:"size:64"`
 	AppliedAt time.Time `gorm:"autoCreateTime"`
 }
 
@@ -22,130 +45,343 @@ func (Migration) TableName() string {
 	return "schema_migrations"
 }
 
-// MigrationFile represents a migration file
+// MigrationFile is a migration loaded from its up and, optionally, down files
 type MigrationFile struct {
 	Version  string
 	Name     string
-	FilePath string
-	SQL      string
+	UpSQL    string
+	DownSQL  string
+	Checksum string
 }
 
-// RunMigrations executes pending database migrations
-func RunMigrations(migrationsPath string) error {
-	if DB == nil {
-		return fmt.Errorf("database connection not established")
-	}
+// MigrationStatus describes a migration file and whether it has been applied
+type MigrationStatus struct {
+	Version   string     `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	// Modified is set when the file no longer matches what was applied
+	Modified bool `json:"modified"`
+	// Missing is set when an applied migration has no file any more
+	Missing bool `json:"missing"`
+}
+
+// Migrator applies and rolls back the SQL migrations in a directory.
+//
+// Migrations are NNN_name.up.sql files, applied in version order, each in its
+// own transaction together with its schema_migrations record; the matching
+// NNN_name.down.sql undoes it. The checksum of every applied up file is stored,
+// and the migrator refuses to run when an applied file has since been edited.
+// Versions recorded by the earlier runner, which never executed the SQL, are
+// applied again.
+// On Postgres a session advisory lock is held for the whole run, so replicas
+// starting together migrate one at a time.
+type Migrator struct {
+	db    *gorm.DB
+	files fs.FS
+}
 
-	// Create migrations table if it doesn't exist
-	if err := DB.AutoMigrate(&Migration{}); err != nil {
-		return fmt.Errorf("failed to create migrations table: %w", err)
+// NewMigrator creates a migrator applying the migrations in files to db
+func NewMigrator(db *gorm.DB, files fs.FS) *Migrator {
+	return &Migrator{
+		db:    db,
+		files: files,
 	}
+}
+
+// Up applies every pending migration and returns how many were applied
+func (m *Migrator) Up() (int, error) {
+	applied := 0
+	err := m.locked(func(db *gorm.DB, files []MigrationFile, done map[string]Migration) error {
+		for _, file := range files {
+			if _, ok := done[file.Version]; ok {
+				continue
+			}
+
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(file.UpSQL).Error; err != nil {
+					return err
+				}
+				return tx.Create(&Migration{Version: file.Version, Name: file.Name, Checksum: file.Checksum}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("failed to apply migration %s_%s: %w", file.Version, file.Name, err)
+			}
+
+			applied++
+			slog.Info("Applied migration", "version", file.Version, "name", file.Name)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back applied migrations newer than target, newest first, and
+// returns how many were rolled back. An empty target rolls back everything.
+func (m *Migrator) Down(target string) (int, error) {
+	rolledBack := 0
+	err := m.locked(func(db *gorm.DB, files []MigrationFile, done map[string]Migration) error {
+		if target != "" && !hasVersion(files, target) {
+			return fmt.Errorf("%w: %s", ErrUnknownVersion, target)
+		}
+
+		for i := len(files) - 1; i >= 0; i-- {
+			file := files[i]
+			if file.Version <= target {
+				break
+			}
+			if _, ok := done[file.Version]; !ok {
+				continue
+			}
+			if file.DownSQL == "" {
+				return fmt.Errorf("%w: %s_%s", ErrNoDownMigration, file.Version, file.Name)
+			}
+
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(file.DownSQL).Error; err != nil {
+					return err
+				}
+				return tx.Where("version = ?", file.Version).Delete(&Migration{}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("failed to roll back migration %s_%s: %w", file.Version, file.Name, err)
+			}
+
+			rolledBack++
+			slog.Info("Rolled back migration", "version", file.Version, "name", file.Name)
+		}
+		return nil
+	})
+	return rolledBack, err
+}
 
-	// Load migration files
-	migrationFiles, err := loadMigrationFiles(migrationsPath)
+// Status lists every migration, known from its file or from the database, in version order
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	files, err := LoadMigrationFiles(m.files)
 	if err != nil {
-		return fmt.Errorf("failed to load migration files: %w", err)
+		return nil, err
+	}
+	if err := m.db.AutoMigrate(&Migration{}); err != nil {
+		return nil, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+	done, err := appliedMigrations(m.db)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get applied migrations
-	var appliedMigrations []Migration
-	if err := DB.Find(&appliedMigrations).Error; err != nil {
-		return fmt.Errorf("failed to get applied migrations: %w", err)
+	// Migrations the earlier runner only recorded are still pending
+	for version, migration := range done {
+		if !migration.executed() {
+			delete(done, version)
+		}
 	}
 
-	appliedVersions := make(map[string]bool)
-	for _, migration := range appliedMigrations {
-		appliedVersions[migration.Version] = true
+	statuses := make([]MigrationStatus, 0, len(files))
+	for _, file := range files {
+		status := MigrationStatus{Version: file.Version, Name: file.Name}
+		if migration, ok := done[file.Version]; ok {
+			appliedAt := migration.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+			status.Modified = migration.Checksum != file.Checksum
+			delete(done, file.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, migration := range done {
+		appliedAt := migration.AppliedAt
+		statuses = append(statuses, MigrationStatus{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Applied:   true,
+			AppliedAt: &appliedAt,
+			Missing:   true,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// locked runs fn on a single connection holding the migration lock, with the
+// migration files and the applied migrations after checking they agree
+func (m *Migrator) locked(fn func(db *gorm.DB, files []MigrationFile, done map[string]Migration) error) error {
+	files, err := LoadMigrationFiles(m.files)
+	if err != nil {
+		return err
 	}
 
-	// Execute pending migrations
-	for _, migrationFile := range migrationFiles {
-		if appliedVersions[migrationFile.Version] {
-			continue // Skip already applied migrations
+	return m.db.Connection(func(conn *gorm.DB) error {
+		// Queries on conn would share one statement; each needs its own
+		db := conn.Session(&gorm.Session{NewDB: true})
+		if db.Dialector.Name() == "postgres" {
+			if err := db.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+				return fmt.Errorf("failed to take migration lock: %w", err)
+			}
+			defer func() {
+				if err := db.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey).Error; err != nil {
+					slog.Error("Failed to release migration lock", "error", err)
+				}
+			}()
 		}
 
-		if err := executeMigration(migrationFile); err != nil {
-			return fmt.Errorf("failed to execute migration %s: %w", migrationFile.Version, err)
+		// Read the applied migrations only once locked, as another instance may have just applied some
+		if err := db.AutoMigrate(&Migration{}); err != nil {
+			return fmt.Errorf("failed to create migrations table: %w", err)
+		}
+		done, err := appliedMigrations(db)
+		if err != nil {
+			return err
+		}
+		if err := forgetUnexecuted(db, done); err != nil {
+			return err
 		}
+		if err := verifyApplied(db, files, done); err != nil {
+			return err
+		}
+
+		return fn(db, files, done)
+	})
+}
+
+// executed reports whether the migration was really applied. The earlier runner
+// recorded every migration without executing it, with no checksum and with the
+// underscores of the name replaced by spaces.
+func (m Migration) executed() bool {
+	return m.Checksum != "" && !strings.Contains(m.Name, " ")
+}
 
-		// Record migration as applied
-		migration := Migration{
-			Version: migrationFile.Version,
-			Name:    migrationFile.Name,
+// forgetUnexecuted removes the records the earlier runner wrote, so the
+// migrations are applied for real. They are idempotent, so running them over
+// the schema AutoMigrate created is safe.
+func forgetUnexecuted(db *gorm.DB, done map[string]Migration) error {
+	for version, migration := range done {
+		if migration.executed() {
+			continue
 		}
-		if err := DB.Create(&migration).Error; err != nil {
-			return fmt.Errorf("failed to record migration %s: %w", migrationFile.Version, err)
+
+		if err := db.Where("version = ?", version).Delete(&Migration{}).Error; err != nil {
+			return fmt.Errorf("failed to forget unexecuted migration %s: %w", version, err)
 		}
+		delete(done, version)
+		slog.Warn("Migration was recorded without being executed, applying it again", "version", version, "name", migration.Name)
+	}
+	return nil
+}
 
-		fmt.Printf("Applied migration: %s - %s\n", migrationFile.Version, migrationFile.Name)
+// verifyApplied checks that every applied migration still has an unchanged file
+func verifyApplied(db *gorm.DB, files []MigrationFile, done map[string]Migration) error {
+	byVersion := make(map[string]MigrationFile, len(files))
+	for _, file := range files {
+		byVersion[file.Version] = file
 	}
 
+	for version, migration := range done {
+		file, ok := byVersion[version]
+		if !ok {
+			return fmt.Errorf("%w: %s_%s", ErrMigrationMissing, version, migration.Name)
+		}
+
+		if migration.Checksum != file.Checksum {
+			return fmt.Errorf("%w: %s_%s; add a new migration instead of editing an applied one",
+				ErrMigrationModified, version, file.Name)
+		}
+	}
 	return nil
 }
 
-// loadMigrationFiles loads and sorts migration files from the given directory
-func loadMigrationFiles(migrationsPath string) ([]MigrationFile, error) {
-	var migrationFiles []MigrationFile
+// appliedMigrations returns the recorded migrations keyed by version
+func appliedMigrations(db *gorm.DB) (map[string]Migration, error) {
+	var migrations []Migration
+	if err := db.Find(&migrations).Error; err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
 
-	err := filepath.WalkDir(migrationsPath, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
+	done := make(map[string]Migration, len(migrations))
+	for _, migration := range migrations {
+		done[migration.Version] = migration
+	}
+	return done, nil
+}
 
-		if d.IsDir() || !strings.HasSuffix(path, ".sql") {
-			return nil
+// LoadMigrationFiles reads the migrations at the top level of files, sorted by version
+func LoadMigrationFiles(files fs.FS) ([]MigrationFile, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[string]*MigrationFile)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
 		}
 
-		// Extract version and name from filename
-		filename := d.Name()
-		parts := strings.SplitN(filename, "_", 2)
-		if len(parts) < 2 {
-			return fmt.Errorf("invalid migration filename format: %s", filename)
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration filename %s, expected NNN_name.up.sql or NNN_name.down.sql", entry.Name())
 		}
+		version, name, direction := match[1], match[2], match[3]
 
-		version := parts[0]
-		name := strings.TrimSuffix(parts[1], ".sql")
-		name = strings.ReplaceAll(name, "_", " ")
+		content, err := fs.ReadFile(files, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
 
-		migrationFiles = append(migrationFiles, MigrationFile{
-			Version:  version,
-			Name:     name,
-			FilePath: path,
-		})
+		file, ok := byVersion[version]
+		if !ok {
+			file = &MigrationFile{Version: version, Name: name}
+			byVersion[version] = file
+		} else if file.Name != name {
+			return nil, fmt.Errorf("migration %s has files named %s and %s", version, file.Name, name)
+		}
 
-		return nil
-	})
+		if direction == "up" {
+			sum := sha256.Sum256(content)
+			file.UpSQL = string(content)
+			file.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			file.DownSQL = string(content)
+		}
+	}
 
-	if err != nil {
-		return nil, err
+	migrationFiles := make([]MigrationFile, 0, len(byVersion))
+	for version, file := range byVersion {
+		if file.UpSQL == "" {
+			return nil, fmt.Errorf("migration %s_%s has no up file", version, file.Name)
+		}
+		migrationFiles = append(migrationFiles, *file)
 	}
 
-	// Sort migrations by version
+	// Versions are zero-padded, so they sort as strings
 	sort.Slice(migrationFiles, func(i, j int) bool {
 		return migrationFiles[i].Version < migrationFiles[j].Version
 	})
-
 	return migrationFiles, nil
 }
 
-// executeMigration executes a single migration file
-func executeMigration(migrationFile MigrationFile) error {
-	// For now, we'll use GORM's AutoMigrate instead of raw SQL
-	// This is safer and more portable across different database systems
-	
-	// The actual SQL execution would be:
-	// sqlContent, err := os.ReadFile(migrationFile.FilePath)
-	// if err != nil {
-	//     return err
-	// }
-	// return DB.Exec(string(sqlContent)).Error
-
-	// Since we're using GORM models, we'll rely on AutoMigrate
-	// which was already called in the Connect function
-	return nil
+func hasVersion(files []MigrationFile, version string) bool {
+	for _, file := range files {
+		if file.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// RunMigrations applies the pending migrations in migrationsPath to the connected database
+func RunMigrations(migrationsPath string) error {
+	if DB == nil {
+		return fmt.Errorf("database connection not established")
+	}
+
+	_, err := NewMigrator(DB, os.DirFS(migrationsPath)).Up()
+	return err
 }
 
-// GetMigrationStatus returns the status of all migrations
+// GetMigrationStatus returns the applied migrations, newest first
 func GetMigrationStatus() ([]Migration, error) {
 	if DB == nil {
 		return nil, fmt.Errorf("database connection not established")
@@ -156,9 +392,31 @@ func GetMigrationStatus() ([]Migration, error) {
 	return migrations, err
 }
 
-// RollbackMigration rolls back the last applied migration (placeholder)
-func RollbackMigration() error {
-	// This would require down migration files
-	// For now, this is a placeholder
-	return fmt.Errorf("rollback functionality not implemented yet")
-}
\ No newline at end of file
+// RollbackMigration rolls back the most recently applied migration in migrationsPath
+func RollbackMigration(migrationsPath string) error {
+	if DB == nil {
+		return fmt.Errorf("database connection not established")
+	}
+
+	var last Migration
+	if err := DB.Order("version DESC").First(&last).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	files, err := LoadMigrationFiles(os.DirFS(migrationsPath))
+	if err != nil {
+		return err
+	}
+	target := ""
+	for _, file := range files {
+		if file.Version < last.Version {
+			target = file.Version
+		}
+	}
+
+	_, err = NewMigrator(DB, os.DirFS(migrationsPath)).Down(target)
+	return err
+}
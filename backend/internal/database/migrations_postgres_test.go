@@ -0,0 +1,54 @@
+package database_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"zombie-car-game-backend/internal/database"
+	"zombie-car-game-backend/internal/database/dbtest"
+	"zombie-car-game-backend/migrations"
+)
+
+// TestMigrator_Postgres applies and rolls back the shipped migrations, which
+// are written for Postgres, against the database named by TEST_DB_DSN
+func TestMigrator_Postgres(t *testing.T) {
+	if os.Getenv("TEST_DB_DSN") == "" {
+		t.Skip("TEST_DB_DSN is not set, skipping Postgres migration test")
+	}
+	t.Setenv("TEST_DB_DRIVER", database.DriverPostgres)
+	db := dbtest.Open(t)
+
+	files, err := database.LoadMigrationFiles(migrations.Files)
+	require.NoError(t, err)
+	migrator := database.NewMigrator(db, migrations.Files)
+
+	applied, err := migrator.Up()
+	require.NoError(t, err)
+	assert.Equal(t, len(files), applied)
+
+	// The schema accepts what the services write
+	var playerID uint
+	require.NoError(t, db.Raw(`INSERT INTO players (username, email, password_hash)
+		VALUES ('migrated', 'migrated@example.com', 'hash') RETURNING id`).Scan(&playerID).Error)
+	require.NoError(t, db.Exec(`INSERT INTO game_sessions (player_id, level_id, session_state)
+		VALUES (?, 'level_1', 'abandoned')`, playerID).Error)
+	require.NoError(t, db.Exec(`INSERT INTO leaderboard (player_id, level_id, score) VALUES (?, 'level_1', 500)`, playerID).Error)
+	require.NoError(t, db.Exec("SELECT refresh_leaderboard()").Error)
+
+	var ranked int64
+	require.NoError(t, db.Raw("SELECT COUNT(*) FROM top_players_by_level").Scan(&ranked).Error)
+	assert.Equal(t, int64(1), ranked)
+
+	rolledBack, err := migrator.Down("")
+	require.NoError(t, err)
+	assert.Equal(t, len(files), rolledBack)
+	assert.False(t, db.Migrator().HasTable("players"))
+	assert.False(t, db.Migrator().HasTable("leaderboard"))
+
+	// Rolling back leaves a schema the migrations apply to again
+	applied, err = migrator.Up()
+	require.NoError(t, err)
+	assert.Equal(t, len(files), applied)
+}
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"zombie-car-game-backend/migrations"
+)
+
+func newMigrationTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	return db
+}
+
+func testMigrations() fstest.MapFS {
+	return fstest.MapFS{
+		"001_create_cars.up.sql":      {Data: []byte("CREATE TABLE cars (id INTEGER PRIMARY KEY, name TEXT);")},
+		"001_create_cars.down.sql":    {Data: []byte("DROP TABLE cars;")},
+		"002_add_cars_speed.up.sql":   {Data: []byte("ALTER TABLE cars ADD COLUMN speed INTEGER;\nCREATE INDEX idx_cars_speed ON cars(speed);")},
+		"002_add_cars_speed.down.sql": {Data: []byte("DROP INDEX idx_cars_speed;\nALTER TABLE cars DROP COLUMN speed;")},
+		"003_create_drivers.up.sql":   {Data: []byte("CREATE TABLE drivers (id INTEGER PRIMARY KEY);")},
+		"003_create_drivers.down.sql": {Data: []byte("DROP TABLE drivers;")},
+	}
+}
+
+func TestMigrator_UpAndDown(t *testing.T) {
+	db := newMigrationTestDB(t)
+	migrator := NewMigrator(db, testMigrations())
+
+	applied, err := migrator.Up()
+	require.NoError(t, err)
+	assert.Equal(t, 3, applied)
+	assert.True(t, db.Migrator().HasColumn("cars", "speed"))
+	assert.True(t, db.Migrator().HasTable("drivers"))
+
+	// Running again applies nothing
+	applied, err = migrator.Up()
+	require.NoError(t, err)
+	assert.Equal(t, 0, applied)
+
+	// Roll back to a target version, newest first
+	rolledBack, err := migrator.Down("001")
+	require.NoError(t, err)
+	assert.Equal(t, 2, rolledBack)
+	assert.False(t, db.Migrator().HasTable("drivers"))
+	assert.False(t, db.Migrator().HasColumn("cars", "speed"))
+	assert.True(t, db.Migrator().HasTable("cars"))
+
+	statuses, err := migrator.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[1].Applied)
+	assert.False(t, statuses[2].Applied)
+
+	_, err = migrator.Down("999")
+	assert.ErrorIs(t, err, ErrUnknownVersion)
+
+	// An empty target rolls everything back
+	rolledBack, err = migrator.Down("")
+	require.NoError(t, err)
+	assert.Equal(t, 1, rolledBack)
+	assert.False(t, db.Migrator().HasTable("cars"))
+}
+
+func TestMigrator_FailedMigrationIsRolledBack(t *testing.T) {
+	db := newMigrationTestDB(t)
+	files := testMigrations()
+	files["002_add_cars_speed.up.sql"] = &fstest.MapFile{Data: []byte("ALTER TABLE cars ADD COLUMN speed INTEGER;\nSELECT * FROM no_such_table;")}
+
+	applied, err := NewMigrator(db, files).Up()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "002_add_cars_speed")
+	assert.Equal(t, 1, applied)
+
+	// Neither the half-applied change nor its record survive
+	assert.False(t, db.Migrator().HasColumn("cars", "speed"))
+	var count int64
+	db.Model(&Migration{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestMigrator_DetectsModifiedAndMissingMigrations(t *testing.T) {
+	db := newMigrationTestDB(t)
+	files := testMigrations()
+	_, err := NewMigrator(db, files).Up()
+	require.NoError(t, err)
+
+	edited := testMigrations()
+	edited["001_create_cars.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE cars (id INTEGER PRIMARY KEY, name TEXT, colour TEXT);")}
+	_, err = NewMigrator(db, edited).Up()
+	assert.ErrorIs(t, err, ErrMigrationModified)
+
+	statuses, err := NewMigrator(db, edited).Status()
+	require.NoError(t, err)
+	assert.True(t, statuses[0].Modified)
+
+	missing := testMigrations()
+	delete(missing, "003_create_drivers.up.sql")
+	delete(missing, "003_create_drivers.down.sql")
+	_, err = NewMigrator(db, missing).Up()
+	assert.ErrorIs(t, err, ErrMigrationMissing)
+}
+
+func TestMigrator_ReappliesMigrationsRecordedByEarlierRunner(t *testing.T) {
+	db := newMigrationTestDB(t)
+	files := testMigrations()
+	loaded, err := LoadMigrationFiles(files)
+	require.NoError(t, err)
+
+	// The earlier runner recorded migrations without executing them; the first
+	// run of this one then filled in their checksums
+	require.NoError(t, db.AutoMigrate(&Migration{}))
+	require.NoError(t, db.Create(&Migration{Version: "001", Name: "create cars"}).Error)
+	require.NoError(t, db.Create(&Migration{Version: "002", Name: "add cars speed", Checksum: loaded[1].Checksum}).Error)
+
+	migrator := NewMigrator(db, files)
+	statuses, err := migrator.Status()
+	require.NoError(t, err)
+	assert.False(t, statuses[0].Applied)
+	assert.False(t, statuses[1].Applied)
+
+	applied, err := migrator.Up()
+	require.NoError(t, err)
+	assert.Equal(t, 3, applied)
+	assert.True(t, db.Migrator().HasColumn("cars", "speed"))
+
+	var recorded []Migration
+	require.NoError(t, db.Order("version").Find(&recorded).Error)
+	require.Len(t, recorded, 3)
+	assert.Equal(t, "create_cars", recorded[0].Name)
+	assert.Equal(t, loaded[0].Checksum, recorded[0].Checksum)
+}
+
+func TestLoadMigrationFiles(t *testing.T) {
+	_, err := LoadMigrationFiles(fstest.MapFS{"1-cars.sql": {Data: []byte("SELECT 1;")}})
+	assert.Error(t, err, "names must follow NNN_name.up.sql")
+
+	_, err = LoadMigrationFiles(fstest.MapFS{"001_cars.down.sql": {Data: []byte("SELECT 1;")}})
+	assert.Error(t, err, "a down file needs its up file")
+
+	// The shipped migrations all parse and can be rolled back
+	files, err := LoadMigrationFiles(migrations.Files)
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, file := range files {
+		assert.NotEmpty(t, file.DownSQL, file.Version)
+		assert.NotContains(t, file.UpSQL, "INDEX CONCURRENTLY", "%s cannot run in a transaction", file.Version)
+		assert.NotContains(t, file.UpSQL, "CREATE EXTENSION", "%s would need superuser rights", file.Version)
+	}
+}
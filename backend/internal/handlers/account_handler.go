@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"zombie-car-game-backend/internal/services"
+)
+
+// AccountHandler handles email verification and password recovery requests
+type AccountHandler struct {
+	accountService *services.AccountService
+}
+
+// NewAccountHandler creates a new account handler
+func NewAccountHandler(accountService *services.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// VerifyEmail handles POST /api/v1/auth/verify-email
+func (h *AccountHandler) VerifyEmail(c *gin.Context) {
+	var req services.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.accountService.VerifyEmail(req.Token); err != nil {
+		switch err {
+		case services.ErrInvalidAccountToken:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Verification link is invalid or has expired",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to verify email",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email verified successfully",
+	})
+}
+
+// ResendVerification handles POST /api/v1/players/verify-email/resend
+func (h *AccountHandler) ResendVerification(c *gin.Context) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Player not authenticated",
+		})
+		return
+	}
+
+	if err := h.accountService.ResendVerification(playerID.(uint)); err != nil {
+		switch err {
+		case services.ErrEmailAlreadyVerified:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Email is already verified",
+			})
+		case services.ErrGuestAccount:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Guest accounts have no email to verify",
+			})
+		case services.ErrPlayerNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Player not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to send verification email",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Verification email sent",
+	})
+}
+
+// ForgotPassword handles POST /api/v1/auth/password/forgot
+func (h *AccountHandler) ForgotPassword(c *gin.Context) {
+	var req services.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.accountService.RequestPasswordReset(req.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to request password reset",
+		})
+		return
+	}
+
+	// Same answer whether or not the email exists
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "If an account exists for that email, a reset link has been sent",
+	})
+}
+
+// ResetPassword handles POST /api/v1/auth/password/reset
+func (h *AccountHandler) ResetPassword(c *gin.Context) {
+	var req services.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	req.IPAddress = c.ClientIP()
+	req.RequestID = c.GetString("request_id")
+
+	if err := h.accountService.ResetPassword(req); err != nil {
+		switch err {
+		case services.ErrInvalidAccountToken:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Reset link is invalid or has expired",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to reset password",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Password reset successfully, please log in again",
+	})
+}
@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"zombie-car-game-backend/internal/services"
+)
+
+// AdminHandler handles the staff-only player management API
+type AdminHandler struct {
+	adminService *services.AdminService
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(adminService *services.AdminService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+	}
+}
+
+// SearchPlayers handles GET /api/v1/admin/players
+func (h *AdminHandler) SearchPlayers(c *gin.Context) {
+	var req services.PlayerSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.adminService.SearchPlayers(req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to search players",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Players retrieved successfully",
+		"data":    result,
+	})
+}
+
+// GetPlayerSessions handles GET /api/v1/admin/players/:id/sessions
+func (h *AdminHandler) GetPlayerSessions(c *gin.Context) {
+	playerID, ok := parsePlayerIDParam(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.adminService.GetPlayerSessions(playerID)
+	if err != nil {
+		switch err {
+		case services.ErrPlayerNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Player not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to retrieve sessions",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Sessions retrieved successfully",
+		"data":    sessions,
+	})
+}
+
+// AdjustCurrency handles POST /api/v1/admin/players/:id/currency
+func (h *AdminHandler) AdjustCurrency(c *gin.Context) {
+	actor, ok := adminActor(c)
+	if !ok {
+		return
+	}
+	playerID, ok := parsePlayerIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req services.AdjustCurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	balance, err := h.adminService.AdjustCurrency(actor, playerID, req)
+	if err != nil {
+		switch err {
+		case services.ErrPlayerNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Player not found",
+			})
+		case services.ErrInsufficientFunds:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Adjustment would make the balance negative",
+			})
+		case services.ErrInvalidCurrency:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid currency type",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to adjust currency",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Currency adjusted successfully",
+		"data": gin.H{
+			"currency": req.Currency,
+			"balance":  balance,
+		},
+	})
+}
+
+// SetLevel handles PUT /api/v1/admin/players/:id/level
+func (h *AdminHandler) SetLevel(c *gin.Context) {
+	actor, ok := adminActor(c)
+	if !ok {
+		return
+	}
+	playerID, ok := parsePlayerIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req services.SetLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	player, err := h.adminService.SetLevel(actor, playerID, req)
+	if err != nil {
+		switch err {
+		case services.ErrPlayerNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Player not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to update level",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Level updated successfully",
+		"data":    player,
+	})
+}
+
+// GrantVehicle handles POST /api/v1/admin/players/:id/vehicles
+func (h *AdminHandler) GrantVehicle(c *gin.Context) {
+	actor, ok := adminActor(c)
+	if !ok {
+		return
+	}
+	playerID, ok := parsePlayerIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req services.GrantVehicleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	vehicle, err := h.adminService.GrantVehicle(actor, playerID, req)
+	if err != nil {
+		switch err {
+		case services.ErrPlayerNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Player not found",
+			})
+		case services.ErrInvalidVehicleType:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid vehicle type",
+			})
+		case services.ErrVehicleAlreadyOwned:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Player already owns this vehicle",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to grant vehicle",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Vehicle granted successfully",
+		"data":    vehicle,
+	})
+}
+
+// RevokeVehicle handles DELETE /api/v1/admin/players/:id/vehicles/:vehicleId
+func (h *AdminHandler) RevokeVehicle(c *gin.Context) {
+	actor, ok := adminActor(c)
+	if !ok {
+		return
+	}
+	playerID, ok := parsePlayerIDParam(c)
+	if !ok {
+		return
+	}
+
+	vehicleID, err := strconv.ParseUint(c.Param("vehicleId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid vehicle ID",
+		})
+		return
+	}
+
+	if err := h.adminService.RevokeVehicle(actor, playerID, uint(vehicleID), c.Query("reason")); err != nil {
+		switch err {
+		case services.ErrVehicleNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Vehicle not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to revoke vehicle",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Vehicle revoked successfully",
+	})
+}
+
+// SetRole handles PUT /api/v1/admin/players/:id/role
+func (h *AdminHandler) SetRole(c *gin.Context) {
+	actor, ok := adminActor(c)
+	if !ok {
+		return
+	}
+	playerID, ok := parsePlayerIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req services.SetRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	player, err := h.adminService.SetRole(actor, playerID, req)
+	if err != nil {
+		switch err {
+		case services.ErrPlayerNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Player not found",
+			})
+		case services.ErrInvalidRole:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid role",
+			})
+		case services.ErrCannotChangeOwnRole:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "You cannot change your own role",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to update role",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role updated successfully",
+		"data":    player,
+	})
+}
+
+// adminActor identifies the staff member making the request, answering 401 if there is none
+func adminActor(c *gin.Context) (services.AdminActor, bool) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Player not authenticated",
+		})
+		return services.AdminActor{}, false
+	}
+
+	return services.AdminActor{
+		PlayerID:  playerID.(uint),
+		IPAddress: c.ClientIP(),
+		RequestID: c.GetString("request_id"),
+	}, true
+}
+
+// parsePlayerIDParam reads the :id path parameter, answering 400 if it is not a player ID
+func parsePlayerIDParam(c *gin.Context) (uint, bool) {
+	playerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid player ID",
+		})
+		return 0, false
+	}
+	return uint(playerID), true
+}
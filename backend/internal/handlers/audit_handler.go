@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"zombie-car-game-backend/internal/services"
+)
+
+// AuditHandler exposes the audit log to staff
+type AuditHandler struct {
+	auditService *services.AuditService
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(auditService *services.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListEntries handles GET /api/v1/admin/audit
+func (h *AuditHandler) ListEntries(c *gin.Context) {
+	var req services.AuditQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.auditService.Query(req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to search audit log",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Audit entries retrieved successfully",
+		"data":    result,
+	})
+}
+
+// VerifyChain handles GET /api/v1/admin/audit/verify
+func (h *AuditHandler) VerifyChain(c *gin.Context) {
+	report, err := h.auditService.VerifyChain(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to verify audit log",
+		})
+		return
+	}
+
+	message := "Audit log is intact"
+	if !report.Valid {
+		message = "Audit log has been tampered with"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    report,
+	})
+}
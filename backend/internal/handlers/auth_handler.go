@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"zombie-car-game-backend/internal/auth"
 	"zombie-car-game-backend/internal/services"
 )
 
@@ -30,6 +34,9 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	req.IPAddress = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
 	response, err := h.playerService.CreatePlayer(req)
 	if err != nil {
 		switch err {
@@ -55,6 +62,93 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	})
 }
 
+// GuestLogin handles POST /api/v1/auth/guest
+func (h *AuthHandler) GuestLogin(c *gin.Context) {
+	var req services.GuestLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	req.IPAddress = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	response, err := h.playerService.LoginAsGuest(req)
+	if err != nil {
+		if respondSanctioned(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Guest login failed",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Guest login successful",
+		"data":    response,
+	})
+}
+
+// UpgradeGuest handles POST /api/v1/players/upgrade
+func (h *AuthHandler) UpgradeGuest(c *gin.Context) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Player not authenticated",
+		})
+		return
+	}
+
+	var req services.UpgradeGuestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	req.IPAddress = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	response, err := h.playerService.UpgradeGuest(playerID.(uint), req)
+	if err != nil {
+		switch err {
+		case services.ErrNotGuest:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Account already has credentials",
+			})
+		case services.ErrUsernameExists:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Username already exists",
+			})
+		case services.ErrEmailExists:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Email already exists",
+			})
+		case services.ErrPlayerNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Player not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to upgrade account",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Account upgraded successfully",
+		"data":    response,
+	})
+}
+
 // Login handles player login
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req services.LoginRequest
@@ -66,8 +160,15 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	req.IPAddress = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
 	response, err := h.playerService.Login(req)
 	if err != nil {
+		if respondLoginThrottled(c, err) || respondSanctioned(c, err) {
+			return
+		}
+
 		switch err {
 		case services.ErrInvalidCredentials:
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -81,16 +182,51 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	if response.TwoFactorRequired {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Two-factor authentication required",
+			"data":    response,
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Login successful",
 		"data":    response,
 	})
 }
 
-// RefreshToken handles token refresh
+// respondLoginThrottled answers 429 with Retry-After if err is a login throttle error
+func respondLoginThrottled(c *gin.Context, err error) bool {
+	var throttled *services.LoginThrottledError
+	if !errors.As(err, &throttled) {
+		return false
+	}
+
+	retryAfter := int(math.Ceil(throttled.RetryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Too many failed login attempts, please try again later",
+		"retry_after": retryAfter,
+	})
+	return true
+}
+
+// respondSanctioned answers 403 with the sanction details if err says the player is banned or suspended
+func respondSanctioned(c *gin.Context, err error) bool {
+	var sanctioned *services.AccountSanctionedError
+	if !errors.As(err, &sanctioned) {
+		return false
+	}
+
+	c.JSON(http.StatusForbidden, sanctioned.Sanction.Notice())
+	return true
+}
+
+// RefreshToken handles token refresh by rotating the presented refresh token
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	var req struct {
-		Token string `json:"token" binding:"required"`
+		RefreshToken string `json:"refresh_token" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -101,11 +237,29 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	response, err := h.playerService.RefreshToken(req.Token)
+	response, err := h.playerService.RefreshToken(req.RefreshToken, services.DeviceInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	})
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Failed to refresh token",
-		})
+		if respondSanctioned(c, err) {
+			return
+		}
+
+		switch err {
+		case services.ErrRefreshTokenReused:
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Refresh token reuse detected, please log in again",
+			})
+		case services.ErrInvalidRefreshToken, services.ErrPlayerNotFound:
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Failed to refresh token",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to refresh token",
+			})
+		}
 		return
 	}
 
@@ -115,12 +269,43 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	})
 }
 
-// Logout handles player logout (client-side token invalidation)
+// Logout revokes the caller's access token and refresh token family
 func (h *AuthHandler) Logout(c *gin.Context) {
-	// In a JWT-based system, logout is typically handled client-side
-	// by removing the token from storage. We can add token blacklisting
-	// in the future if needed.
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	// The body is optional; a bearer token alone is enough to log out
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request data",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	var claims *auth.Claims
+	if value, exists := c.Get("token_claims"); exists {
+		claims = value.(*auth.Claims)
+	}
+
+	if claims == nil && req.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "An access token or refresh token is required",
+		})
+		return
+	}
+
+	if err := h.playerService.Logout(req.RefreshToken, claims); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Logout failed",
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Logout successful",
 	})
-}
\ No newline at end of file
+}
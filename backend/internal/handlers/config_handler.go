@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"zombie-car-game-backend/internal/config"
+)
+
+// ConfigHandler shows staff the configuration the server is running with
+type ConfigHandler struct {
+	cfg *config.Config
+}
+
+// NewConfigHandler creates a new config handler
+func NewConfigHandler(cfg *config.Config) *ConfigHandler {
+	return &ConfigHandler{
+		cfg: cfg,
+	}
+}
+
+// GetConfig handles GET /api/v1/admin/config. Secrets are redacted.
+func (h *ConfigHandler) GetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Configuration retrieved successfully",
+		"data":    h.cfg.Redacted(),
+	})
+}
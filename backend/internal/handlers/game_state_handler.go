@@ -23,7 +23,7 @@ func NewGameStateHandler(gameStateService *services.GameStateService) *GameState
 
 // StartSession handles POST /api/v1/game/sessions
 func (h *GameStateHandler) StartSession(c *gin.Context) {
-	playerID, exists := c.Get("playerID")
+	playerID, exists := c.Get("player_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Player ID not found in context"})
 		return
@@ -35,7 +35,7 @@ func (h *GameStateHandler) StartSession(c *gin.Context) {
 		return
 	}
 
-	session, err := h.gameStateService.StartSession(playerID.(uint), req)
+	session, err := h.gameStateService.WithContext(c.Request.Context()).StartSession(playerID.(uint), req)
 	if err != nil {
 		switch err {
 		case services.ErrPlayerNotFound:
@@ -61,7 +61,7 @@ func (h *GameStateHandler) GetSession(c *gin.Context) {
 		return
 	}
 
-	session, err := h.gameStateService.GetSession(sessionID)
+	session, err := h.gameStateService.WithContext(c.Request.Context()).GetSession(sessionID)
 	if err != nil {
 		switch err {
 		case services.ErrSessionNotFound:
@@ -90,7 +90,7 @@ func (h *GameStateHandler) UpdateScore(c *gin.Context) {
 		return
 	}
 
-	session, err := h.gameStateService.UpdateScore(sessionID, req)
+	session, err := h.gameStateService.WithContext(c.Request.Context()).UpdateScore(sessionID, req)
 	if err != nil {
 		switch err {
 		case services.ErrSessionNotFound:
@@ -126,7 +126,7 @@ func (h *GameStateHandler) EndSession(c *gin.Context) {
 		return
 	}
 
-	result, err := h.gameStateService.EndSession(sessionID, req)
+	result, err := h.gameStateService.WithContext(c.Request.Context()).EndSession(sessionID, req)
 	if err != nil {
 		switch err {
 		case services.ErrSessionNotFound:
@@ -149,9 +149,40 @@ func (h *GameStateHandler) EndSession(c *gin.Context) {
 	})
 }
 
+// ImportSessions handles POST /api/v1/game/sessions/import
+func (h *GameStateHandler) ImportSessions(c *gin.Context) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Player ID not found in context"})
+		return
+	}
+
+	var req services.ImportSessionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.gameStateService.WithContext(c.Request.Context()).ImportSessions(playerID.(uint), req)
+	if err != nil {
+		switch err {
+		case services.ErrPlayerNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Player not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import sessions"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Sessions imported successfully",
+		"result":  result,
+	})
+}
+
 // GetPlayerSessions handles GET /api/v1/game/sessions
 func (h *GameStateHandler) GetPlayerSessions(c *gin.Context) {
-	playerID, exists := c.Get("playerID")
+	playerID, exists := c.Get("player_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Player ID not found in context"})
 		return
@@ -165,7 +196,7 @@ func (h *GameStateHandler) GetPlayerSessions(c *gin.Context) {
 		}
 	}
 
-	sessions, err := h.gameStateService.GetPlayerSessions(playerID.(uint), limit)
+	sessions, err := h.gameStateService.WithContext(c.Request.Context()).GetPlayerSessions(playerID.(uint), limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get player sessions"})
 		return
@@ -176,13 +207,13 @@ func (h *GameStateHandler) GetPlayerSessions(c *gin.Context) {
 
 // GetActiveSession handles GET /api/v1/game/sessions/active
 func (h *GameStateHandler) GetActiveSession(c *gin.Context) {
-	playerID, exists := c.Get("playerID")
+	playerID, exists := c.Get("player_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Player ID not found in context"})
 		return
 	}
 
-	session, err := h.gameStateService.GetActiveSession(playerID.(uint))
+	session, err := h.gameStateService.WithContext(c.Request.Context()).GetActiveSession(playerID.(uint))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get active session"})
 		return
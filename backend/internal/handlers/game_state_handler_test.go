@@ -6,34 +6,29 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/database/dbtest"
 	"zombie-car-game-backend/internal/middleware"
 	"zombie-car-game-backend/internal/models"
 	"zombie-car-game-backend/internal/services"
 )
 
 func setupGameStateTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
-	// Skip tests if CGO is not available
-	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
-	if err != nil {
-		t.Skip("SQLite requires CGO, skipping database tests")
-		return nil, nil
-	}
-
-	// Auto migrate the schema
-	err = db.AutoMigrate(&models.Player{}, &models.GameSession{}, &models.LevelProgress{}, &models.OwnedVehicle{})
-	require.NoError(t, err)
+	// Each test gets a database of its own; registering a player also issues
+	// tokens and queues the verification email
+	db := dbtest.Open(t, &models.Player{}, &models.GameSession{}, &models.LevelProgress{}, &models.OwnedVehicle{}, &models.AuditLog{},
+		&models.RefreshToken{}, &models.DeviceSession{}, &models.Sanction{}, &models.AccountToken{}, &models.OutboxEmail{}, &models.WalletTransaction{})
 
 	// Initialize services
-	playerService := services.NewPlayerService(db)
-	gameStateService := services.NewGameStateService(db, playerService)
 	jwtService := auth.NewJWTService()
+	playerService := services.NewPlayerService(db, jwtService, testAccountConfig)
+	gameStateService := services.NewGameStateService(db, playerService)
 
 	// Initialize handlers
 	gameStateHandler := NewGameStateHandler(gameStateService)
@@ -44,7 +39,7 @@ func setupGameStateTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
 
 	api := router.Group("/api/v1")
 	protected := api.Group("/")
-	protected.Use(middleware.AuthMiddleware(jwtService))
+	protected.Use(middleware.AuthMiddleware(jwtService, nil))
 
 	game := protected.Group("/game")
 	sessions := game.Group("/sessions")
@@ -61,7 +56,7 @@ func setupGameStateTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
 }
 
 func createTestPlayerForHandler(t *testing.T, db *gorm.DB) (*models.Player, string) {
-	playerService := services.NewPlayerService(db)
+	playerService := services.NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 	
 	req := services.CreatePlayerRequest{
 		Username: "testplayer",
@@ -75,6 +70,13 @@ func createTestPlayerForHandler(t *testing.T, db *gorm.DB) (*models.Player, stri
 	return response.Player, response.Token
 }
 
+// backdateHandlerSession moves a session's start a minute into the past, so the
+// scores the tests submit are plausible for the time played
+func backdateHandlerSession(t *testing.T, db *gorm.DB, sessionID string) {
+	require.NoError(t, db.Model(&models.GameSession{}).Where("id = ?", sessionID).
+		Update("started_at", time.Now().Add(-time.Minute)).Error)
+}
+
 func TestGameStateHandler_StartSession(t *testing.T) {
 	router, db := setupGameStateTestRouter(t)
 	if router == nil {
@@ -89,7 +91,7 @@ func TestGameStateHandler_StartSession(t *testing.T) {
 		}
 		jsonBody, _ := json.Marshal(reqBody)
 
-		req, _ := http.NewRequest("POST", "/api/v1/game/sessions", bytes.NewBuffer(jsonBody))
+		req, _ := http.NewRequest("POST", "/api/v1/game/sessions/", bytes.NewBuffer(jsonBody))
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Authorization", "Bearer "+token)
 
@@ -117,7 +119,7 @@ func TestGameStateHandler_StartSession(t *testing.T) {
 		}
 		jsonBody, _ := json.Marshal(reqBody)
 
-		req, _ := http.NewRequest("POST", "/api/v1/game/sessions", bytes.NewBuffer(jsonBody))
+		req, _ := http.NewRequest("POST", "/api/v1/game/sessions/", bytes.NewBuffer(jsonBody))
 		req.Header.Set("Content-Type", "application/json")
 
 		w := httptest.NewRecorder()
@@ -132,7 +134,7 @@ func TestGameStateHandler_StartSession(t *testing.T) {
 		}
 		jsonBody, _ := json.Marshal(reqBody)
 
-		req, _ := http.NewRequest("POST", "/api/v1/game/sessions", bytes.NewBuffer(jsonBody))
+		req, _ := http.NewRequest("POST", "/api/v1/game/sessions/", bytes.NewBuffer(jsonBody))
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Authorization", "Bearer "+token)
 
@@ -174,7 +176,7 @@ func TestGameStateHandler_GetActiveSession(t *testing.T) {
 		}
 		jsonBody, _ := json.Marshal(reqBody)
 
-		req, _ := http.NewRequest("POST", "/api/v1/game/sessions", bytes.NewBuffer(jsonBody))
+		req, _ := http.NewRequest("POST", "/api/v1/game/sessions/", bytes.NewBuffer(jsonBody))
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Authorization", "Bearer "+token)
 
@@ -216,7 +218,7 @@ func TestGameStateHandler_UpdateScore(t *testing.T) {
 	}
 	jsonBody, _ := json.Marshal(reqBody)
 
-	req, _ := http.NewRequest("POST", "/api/v1/game/sessions", bytes.NewBuffer(jsonBody))
+	req, _ := http.NewRequest("POST", "/api/v1/game/sessions/", bytes.NewBuffer(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
 
@@ -231,6 +233,8 @@ func TestGameStateHandler_UpdateScore(t *testing.T) {
 	session := startResponse["session"].(map[string]interface{})
 	sessionID := session["id"].(string)
 
+	backdateHandlerSession(t, db, sessionID)
+
 	t.Run("successful score update", func(t *testing.T) {
 		updateBody := map[string]interface{}{
 			"score":             100,
@@ -292,7 +296,7 @@ func TestGameStateHandler_EndSession(t *testing.T) {
 	}
 	jsonBody, _ := json.Marshal(reqBody)
 
-	req, _ := http.NewRequest("POST", "/api/v1/game/sessions", bytes.NewBuffer(jsonBody))
+	req, _ := http.NewRequest("POST", "/api/v1/game/sessions/", bytes.NewBuffer(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
 
@@ -306,6 +310,7 @@ func TestGameStateHandler_EndSession(t *testing.T) {
 
 	session := startResponse["session"].(map[string]interface{})
 	sessionID := session["id"].(string)
+	backdateHandlerSession(t, db, sessionID)
 
 	t.Run("successful session end", func(t *testing.T) {
 		endBody := map[string]interface{}{
@@ -345,7 +350,7 @@ func TestGameStateHandler_EndSession(t *testing.T) {
 		}
 		jsonBody, _ := json.Marshal(reqBody)
 
-		req, _ := http.NewRequest("POST", "/api/v1/game/sessions", bytes.NewBuffer(jsonBody))
+		req, _ := http.NewRequest("POST", "/api/v1/game/sessions/", bytes.NewBuffer(jsonBody))
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Authorization", "Bearer "+token)
 
@@ -5,42 +5,38 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/database/dbtest"
 	"zombie-car-game-backend/internal/middleware"
 	"zombie-car-game-backend/internal/models"
 	"zombie-car-game-backend/internal/services"
 )
 
+// testAccountConfig is the account configuration of services under test
+var testAccountConfig = services.AccountConfig{
+	RefreshTokenTTL: 30 * 24 * time.Hour,
+	AppBaseURL:      "http://localhost:3000",
+	TOTPIssuer:      "Zombie Car Game",
+}
+
 func setupTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
 	// Set test mode
 	gin.SetMode(gin.TestMode)
-	os.Setenv("JWT_SECRET", "test-secret-key")
-
-	// Setup test database
-	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
-	})
-	if err != nil {
-		t.Skip("SQLite requires CGO, skipping integration tests")
-		return nil, nil
-	}
 
-	// Auto migrate
-	err = db.AutoMigrate(&models.Player{}, &models.OwnedVehicle{}, &models.GameSession{}, &models.LevelProgress{})
-	require.NoError(t, err)
+	// Setup a test database of its own, so registrations do not collide across tests
+	db := dbtest.Open(t, &models.Player{}, &models.OwnedVehicle{}, &models.GameSession{}, &models.LevelProgress{}, &models.RefreshToken{}, &models.DeviceSession{}, &models.Sanction{},
+		&models.AccountToken{}, &models.OutboxEmail{}, &models.AuditLog{}, &models.WalletTransaction{})
 
 	// Setup services and handlers
-	playerService := services.NewPlayerService(db)
 	jwtService := auth.NewJWTService()
+	playerService := services.NewPlayerService(db, jwtService, testAccountConfig)
 	authHandler := NewAuthHandler(playerService)
 	playerHandler := NewPlayerHandler(playerService)
 
@@ -53,12 +49,12 @@ func setupTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
 		authGroup.POST("/register", authHandler.Register)
 		authGroup.POST("/login", authHandler.Login)
 		authGroup.POST("/refresh", authHandler.RefreshToken)
-		authGroup.POST("/logout", authHandler.Logout)
+		authGroup.POST("/logout", middleware.OptionalAuthMiddleware(jwtService), authHandler.Logout)
 	}
 
 	// Protected routes
 	protected := r.Group("/api/v1/players")
-	protected.Use(middleware.AuthMiddleware(jwtService))
+	protected.Use(middleware.AuthMiddleware(jwtService, services.NewSanctionService(db, jwtService, testAccountConfig.RefreshTokenTTL)))
 	{
 		protected.GET("/profile", playerHandler.GetProfile)
 		protected.GET("/progress", playerHandler.GetProgress)
@@ -206,7 +202,9 @@ func TestAuthIntegration_DuplicateRegistration(t *testing.T) {
 
 	require.Equal(t, 201, w.Code)
 
-	// Try to register with same username
+	// Try to register with same username; the first request consumed its body
+	req, _ = http.NewRequest("POST", "/api/v1/auth/register", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
 	w = httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"zombie-car-game-backend/internal/auth"
+)
+
+// JWKSHandler publishes the public signing keys so other services can verify player tokens
+type JWKSHandler struct {
+	jwtService *auth.JWTService
+}
+
+// NewJWKSHandler creates a new JWKS handler
+func NewJWKSHandler(jwtService *auth.JWTService) *JWKSHandler {
+	return &JWKSHandler{
+		jwtService: jwtService,
+	}
+}
+
+// GetJWKS serves the JSON Web Key Set
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	// Short cache so verifiers pick up rotated keys well within the grace period
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.jwtService.Keys().PublicJWKS())
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"zombie-car-game-backend/internal/services"
+)
+
+// OfflineHandler handles the embedded server's sync with the online server
+type OfflineHandler struct {
+	syncService *services.SyncService
+}
+
+// NewOfflineHandler creates a new offline handler
+func NewOfflineHandler(syncService *services.SyncService) *OfflineHandler {
+	return &OfflineHandler{
+		syncService: syncService,
+	}
+}
+
+// SyncRequest carries the access token the player got by logging in online
+type SyncRequest struct {
+	AccessToken string `json:"access_token" binding:"required"`
+}
+
+// GetStatus handles GET /api/v1/offline/status
+func (h *OfflineHandler) GetStatus(c *gin.Context) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Player not authenticated",
+		})
+		return
+	}
+
+	pending, err := h.syncService.Pending(playerID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve sync status",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Sync status retrieved successfully",
+		"data": gin.H{
+			"pending_sessions": pending,
+		},
+	})
+}
+
+// Sync handles POST /api/v1/offline/sync
+func (h *OfflineHandler) Sync(c *gin.Context) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Player not authenticated",
+		})
+		return
+	}
+
+	var req SyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.syncService.Sync(c.Request.Context(), playerID.(uint), req.AccessToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrSyncNotConfigured):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "No online server configured",
+			})
+		case errors.Is(err, services.ErrSyncUnauthorized):
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Online login expired",
+			})
+		case errors.Is(err, services.ErrSyncUnavailable):
+			c.JSON(http.StatusBadGateway, gin.H{
+				"error": "Online server unavailable",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to sync sessions",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Sessions synced successfully",
+		"data":    result,
+	})
+}
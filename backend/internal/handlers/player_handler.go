@@ -30,7 +30,7 @@ func (h *PlayerHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	player, err := h.playerService.GetPlayer(playerID.(uint))
+	player, err := h.playerService.WithContext(c.Request.Context()).GetPlayer(playerID.(uint))
 	if err != nil {
 		switch err {
 		case services.ErrPlayerNotFound:
@@ -61,7 +61,7 @@ func (h *PlayerHandler) GetProgress(c *gin.Context) {
 		return
 	}
 
-	player, err := h.playerService.GetPlayerProgress(playerID.(uint))
+	player, err := h.playerService.WithContext(c.Request.Context()).GetPlayerProgress(playerID.(uint))
 	if err != nil {
 		switch err {
 		case services.ErrPlayerNotFound:
@@ -104,7 +104,7 @@ func (h *PlayerHandler) UpdateCurrency(c *gin.Context) {
 		return
 	}
 
-	err := h.playerService.UpdatePlayerCurrency(playerID.(uint), req.Amount)
+	err := h.playerService.WithContext(c.Request.Context()).UpdatePlayerCurrency(playerID.(uint), req.Amount)
 	if err != nil {
 		switch err {
 		case services.ErrPlayerNotFound:
@@ -150,7 +150,7 @@ func (h *PlayerHandler) UpdateLevel(c *gin.Context) {
 		return
 	}
 
-	err := h.playerService.UpdatePlayerLevel(playerID.(uint), req.Level)
+	err := h.playerService.WithContext(c.Request.Context()).UpdatePlayerLevel(playerID.(uint), req.Level)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to update level",
@@ -185,7 +185,7 @@ func (h *PlayerHandler) UpdateScore(c *gin.Context) {
 		return
 	}
 
-	err := h.playerService.UpdatePlayerScore(playerID.(uint), req.Score)
+	err := h.playerService.WithContext(c.Request.Context()).UpdatePlayerScore(playerID.(uint), req.Score)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to update score",
@@ -209,7 +209,7 @@ func (h *PlayerHandler) GetPlayerByID(c *gin.Context) {
 		return
 	}
 
-	player, err := h.playerService.GetPlayer(uint(playerID))
+	player, err := h.playerService.WithContext(c.Request.Context()).GetPlayer(uint(playerID))
 	if err != nil {
 		switch err {
 		case services.ErrPlayerNotFound:
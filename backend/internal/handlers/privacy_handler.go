@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"zombie-car-game-backend/internal/services"
+)
+
+// PrivacyHandler handles personal data export and account deletion requests
+type PrivacyHandler struct {
+	privacyService *services.PrivacyService
+}
+
+// NewPrivacyHandler creates a new privacy handler
+func NewPrivacyHandler(privacyService *services.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{
+		privacyService: privacyService,
+	}
+}
+
+// ExportData handles GET /api/v1/players/me/export
+func (h *PrivacyHandler) ExportData(c *gin.Context) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Player not authenticated",
+		})
+		return
+	}
+
+	export, err := h.privacyService.ExportPlayerData(playerID.(uint))
+	if err != nil {
+		switch err {
+		case services.ErrPlayerNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Player not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to export player data",
+			})
+		}
+		return
+	}
+
+	// The archive is the response itself so it can be saved as-is
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="player-%d-export.json"`, export.Player.ID))
+	c.Header("Cache-Control", "no-store")
+	c.IndentedJSON(http.StatusOK, export)
+}
+
+// DeleteAccount handles DELETE /api/v1/players/me
+func (h *PrivacyHandler) DeleteAccount(c *gin.Context) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Player not authenticated",
+		})
+		return
+	}
+
+	// Guests have no password, so an empty body is allowed
+	var req services.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	dueAt, err := h.privacyService.RequestDeletion(playerID.(uint), req, c.ClientIP())
+	if err != nil {
+		switch err {
+		case services.ErrInvalidCredentials:
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Password is incorrect",
+			})
+		case services.ErrPlayerNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Player not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to delete account",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Account scheduled for deletion",
+		"data": gin.H{
+			"deletion_due_at": dueAt,
+		},
+	})
+}
+
+// CancelDeletion handles POST /api/v1/players/me/deletion/cancel
+func (h *PrivacyHandler) CancelDeletion(c *gin.Context) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Player not authenticated",
+		})
+		return
+	}
+
+	if err := h.privacyService.CancelDeletion(playerID.(uint), c.ClientIP()); err != nil {
+		switch err {
+		case services.ErrDeletionNotScheduled:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Account is not scheduled for deletion",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to cancel account deletion",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Account deletion cancelled",
+	})
+}
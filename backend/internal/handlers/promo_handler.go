@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"zombie-car-game-backend/internal/services"
+)
+
+// PromoHandler handles promo code related requests
+type PromoHandler struct {
+	promoService *services.PromoService
+}
+
+// NewPromoHandler creates a new promo handler
+func NewPromoHandler(promoService *services.PromoService) *PromoHandler {
+	return &PromoHandler{
+		promoService: promoService,
+	}
+}
+
+// RedeemCode handles POST /api/v1/promo/redeem
+func (h *PromoHandler) RedeemCode(c *gin.Context) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Player not authenticated",
+		})
+		return
+	}
+
+	var req services.RedeemPromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.promoService.RedeemPromoCode(playerID.(uint), req.Code, c.ClientIP())
+	if err != nil {
+		switch err {
+		case services.ErrPromoCodeNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Promo code not found",
+			})
+		case services.ErrPromoCodeInactive, services.ErrPromoCodeExpired:
+			c.JSON(http.StatusGone, gin.H{
+				"error": "Promo code has expired",
+			})
+		case services.ErrPromoCodeExhausted:
+			c.JSON(http.StatusGone, gin.H{
+				"error": "Promo code has reached its redemption limit",
+			})
+		case services.ErrPromoCodeAlreadyRedeemed:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Promo code already redeemed",
+			})
+		case services.ErrPlayerNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Player not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to redeem promo code",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Promo code redeemed successfully",
+		"data":    result,
+	})
+}
+
+// CreateCode handles POST /api/v1/admin/promo-codes
+func (h *PromoHandler) CreateCode(c *gin.Context) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Player not authenticated",
+		})
+		return
+	}
+
+	var req services.CreatePromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	promo, err := h.promoService.CreatePromoCode(playerID.(uint), req)
+	if err != nil {
+		switch err {
+		case services.ErrPromoCodeExists:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Promo code already exists",
+			})
+		case services.ErrInvalidPromoCode, services.ErrInvalidPromoReward,
+			services.ErrInvalidVehicleType, services.ErrPromoCodeExpired:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to create promo code",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Promo code created successfully",
+		"data":    promo,
+	})
+}
+
+// ListCodes handles GET /api/v1/admin/promo-codes
+func (h *PromoHandler) ListCodes(c *gin.Context) {
+	codes, err := h.promoService.ListPromoCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list promo codes",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Promo codes retrieved successfully",
+		"data":    codes,
+	})
+}
+
+// DeactivateCode handles DELETE /api/v1/admin/promo-codes/:code
+func (h *PromoHandler) DeactivateCode(c *gin.Context) {
+	err := h.promoService.DeactivatePromoCode(c.Param("code"))
+	if err != nil {
+		switch err {
+		case services.ErrPromoCodeNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Promo code not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to deactivate promo code",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Promo code deactivated successfully",
+	})
+}
+
+// GetRedemptions handles GET /api/v1/admin/promo-codes/:code/redemptions
+func (h *PromoHandler) GetRedemptions(c *gin.Context) {
+	redemptions, err := h.promoService.GetRedemptions(c.Param("code"))
+	if err != nil {
+		switch err {
+		case services.ErrPromoCodeNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Promo code not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to retrieve redemptions",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Redemptions retrieved successfully",
+		"data":    redemptions,
+	})
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"zombie-car-game-backend/internal/services"
+)
+
+// SanctionHandler handles bans, suspensions and other sanctions, and appeals against them
+type SanctionHandler struct {
+	sanctionService *services.SanctionService
+	playerService   *services.PlayerService
+}
+
+// NewSanctionHandler creates a new sanction handler
+func NewSanctionHandler(sanctionService *services.SanctionService, playerService *services.PlayerService) *SanctionHandler {
+	return &SanctionHandler{
+		sanctionService: sanctionService,
+		playerService:   playerService,
+	}
+}
+
+// Appeal handles POST /api/v1/auth/appeal
+func (h *SanctionHandler) Appeal(c *gin.Context) {
+	var req services.AppealSanctionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	req.IPAddress = c.ClientIP()
+
+	sanction, err := h.playerService.AppealSanction(req)
+	if err != nil {
+		if respondLoginThrottled(c, err) {
+			return
+		}
+
+		switch err {
+		case services.ErrInvalidCredentials:
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid username or password",
+			})
+		case services.ErrSanctionNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Sanction not found",
+			})
+		case services.ErrSanctionNotActive:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Sanction is no longer active",
+			})
+		case services.ErrAppealAlreadySubmitted:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "This sanction has already been appealed",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to submit appeal",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Appeal submitted, a moderator will review it",
+		"data": gin.H{
+			"sanction_id":   sanction.ID,
+			"appeal_status": sanction.AppealStatus,
+		},
+	})
+}
+
+// ListPlayerSanctions handles GET /api/v1/admin/players/:id/sanctions
+func (h *SanctionHandler) ListPlayerSanctions(c *gin.Context) {
+	playerID, ok := parsePlayerIDParam(c)
+	if !ok {
+		return
+	}
+
+	sanctions, err := h.sanctionService.ListPlayerSanctions(playerID)
+	if err != nil {
+		switch err {
+		case services.ErrPlayerNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Player not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to retrieve sanctions",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Sanctions retrieved successfully",
+		"data":    sanctions,
+	})
+}
+
+// IssueSanction handles POST /api/v1/admin/players/:id/sanctions
+func (h *SanctionHandler) IssueSanction(c *gin.Context) {
+	actor, ok := adminActor(c)
+	if !ok {
+		return
+	}
+	playerID, ok := parsePlayerIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req services.IssueSanctionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	sanction, err := h.sanctionService.IssueSanction(actor, playerID, req)
+	if err != nil {
+		switch err {
+		case services.ErrPlayerNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Player not found",
+			})
+		case services.ErrInvalidSanction:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid sanction: suspensions need a future expiry and staff cannot sanction themselves",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to issue sanction",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Sanction issued successfully",
+		"data":    sanction,
+	})
+}
+
+// LiftSanction handles POST /api/v1/admin/sanctions/:id/lift
+func (h *SanctionHandler) LiftSanction(c *gin.Context) {
+	actor, ok := adminActor(c)
+	if !ok {
+		return
+	}
+	sanctionID, ok := parseSanctionIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req services.LiftSanctionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	sanction, err := h.sanctionService.LiftSanction(actor, sanctionID, req)
+	if err != nil {
+		switch err {
+		case services.ErrSanctionNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Sanction not found",
+			})
+		case services.ErrSanctionNotActive:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Sanction is no longer active",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to lift sanction",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Sanction lifted successfully",
+		"data":    sanction,
+	})
+}
+
+// ListAppeals handles GET /api/v1/admin/sanctions/appeals
+func (h *SanctionHandler) ListAppeals(c *gin.Context) {
+	sanctions, err := h.sanctionService.ListPendingAppeals()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve appeals",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Appeals retrieved successfully",
+		"data":    sanctions,
+	})
+}
+
+// RejectAppeal handles POST /api/v1/admin/sanctions/:id/appeal/reject
+func (h *SanctionHandler) RejectAppeal(c *gin.Context) {
+	actor, ok := adminActor(c)
+	if !ok {
+		return
+	}
+	sanctionID, ok := parseSanctionIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req services.ResolveAppealRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request data",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	sanction, err := h.sanctionService.RejectAppeal(actor, sanctionID, req)
+	if err != nil {
+		switch err {
+		case services.ErrSanctionNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Sanction not found",
+			})
+		case services.ErrNoPendingAppeal:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Sanction has no pending appeal",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to reject appeal",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Appeal rejected",
+		"data":    sanction,
+	})
+}
+
+// parseSanctionIDParam reads the :id path parameter, answering 400 if it is not a sanction ID
+func parseSanctionIDParam(c *gin.Context) (uint, bool) {
+	sanctionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid sanction ID",
+		})
+		return 0, false
+	}
+	return uint(sanctionID), true
+}
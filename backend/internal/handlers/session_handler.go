@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/services"
+)
+
+// SessionHandler handles listing and revoking a player's device sessions
+type SessionHandler struct {
+	sessionService *services.SessionService
+}
+
+// NewSessionHandler creates a new session handler
+func NewSessionHandler(sessionService *services.SessionService) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// ListSessions handles GET /api/v1/players/sessions
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Player not authenticated",
+		})
+		return
+	}
+
+	sessions, err := h.sessionService.ListSessions(playerID.(uint), currentSessionID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve sessions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Sessions retrieved successfully",
+		"data":    sessions,
+	})
+}
+
+// RevokeSession handles DELETE /api/v1/players/sessions/:id
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Player not authenticated",
+		})
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(playerID.(uint), c.Param("id")); err != nil {
+		switch err {
+		case services.ErrDeviceSessionNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Session not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to revoke session",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Session revoked successfully",
+	})
+}
+
+// RevokeOtherSessions handles POST /api/v1/players/sessions/revoke-others
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Player not authenticated",
+		})
+		return
+	}
+
+	// Without a session on the token there is no "this device" to keep
+	sessionID := currentSessionID(c)
+	if sessionID == "" {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Current login has no device session, please log in again",
+		})
+		return
+	}
+
+	revoked, err := h.sessionService.RevokeOtherSessions(playerID.(uint), sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to revoke sessions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Signed out of all other sessions",
+		"data": gin.H{
+			"revoked": revoked,
+		},
+	})
+}
+
+// currentSessionID returns the device session of the token authenticating the request
+func currentSessionID(c *gin.Context) string {
+	if value, ok := c.Get("token_claims"); ok {
+		if claims, ok := value.(*auth.Claims); ok {
+			return claims.SessionID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"zombie-car-game-backend/internal/services"
+)
+
+// TwoFactorHandler handles TOTP enrollment and the second login step
+type TwoFactorHandler struct {
+	playerService *services.PlayerService
+}
+
+// NewTwoFactorHandler creates a new two-factor handler
+func NewTwoFactorHandler(playerService *services.PlayerService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		playerService: playerService,
+	}
+}
+
+// VerifyLogin handles POST /api/v1/auth/2fa/verify
+func (h *TwoFactorHandler) VerifyLogin(c *gin.Context) {
+	var req services.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	req.IPAddress = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	response, err := h.playerService.CompleteTwoFactorLogin(req)
+	if err != nil {
+		if respondLoginThrottled(c, err) || respondSanctioned(c, err) {
+			return
+		}
+
+		switch err {
+		case services.ErrInvalidTwoFactorCode:
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid authentication code",
+			})
+		case services.ErrInvalidTwoFactorChallenge, services.ErrTwoFactorNotEnabled:
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Login challenge is invalid or has expired, please log in again",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Login failed",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Login successful",
+		"data":    response,
+	})
+}
+
+// Enroll handles POST /api/v1/players/2fa/enroll
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Player not authenticated",
+		})
+		return
+	}
+
+	enrollment, err := h.playerService.BeginTwoFactorEnrollment(playerID.(uint))
+	if err != nil {
+		switch err {
+		case services.ErrTwoFactorAlreadyEnabled:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Two-factor authentication is already enabled",
+			})
+		case services.ErrGuestAccount:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Guest accounts cannot enable two-factor authentication",
+			})
+		case services.ErrPlayerNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Player not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to start two-factor enrollment",
+			})
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Scan the provisioning URI with an authenticator app, then confirm with a code",
+		"data":    enrollment,
+	})
+}
+
+// Confirm handles POST /api/v1/players/2fa/confirm
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Player not authenticated",
+		})
+		return
+	}
+
+	var req services.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	codes, err := h.playerService.ConfirmTwoFactor(playerID.(uint), req.Code, c.ClientIP())
+	if err != nil {
+		switch err {
+		case services.ErrTwoFactorNotEnrolled:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "No two-factor enrollment to confirm",
+			})
+		case services.ErrInvalidTwoFactorCode:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid authentication code",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to enable two-factor authentication",
+			})
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Two-factor authentication enabled",
+		"data": gin.H{
+			"recovery_codes": codes,
+		},
+	})
+}
+
+// RegenerateRecoveryCodes handles POST /api/v1/players/2fa/recovery-codes
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Player not authenticated",
+		})
+		return
+	}
+
+	var req services.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	codes, err := h.playerService.RegenerateRecoveryCodes(playerID.(uint), req.Code)
+	if err != nil {
+		switch err {
+		case services.ErrTwoFactorNotEnabled:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Two-factor authentication is not enabled",
+			})
+		case services.ErrInvalidTwoFactorCode:
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid authentication code",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to regenerate recovery codes",
+			})
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Recovery codes regenerated",
+		"data": gin.H{
+			"recovery_codes": codes,
+		},
+	})
+}
+
+// Disable handles POST /api/v1/players/2fa/disable
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Player not authenticated",
+		})
+		return
+	}
+
+	var req services.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.playerService.DisableTwoFactor(playerID.(uint), req, c.ClientIP()); err != nil {
+		switch err {
+		case services.ErrTwoFactorNotEnabled:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Two-factor authentication is not enabled",
+			})
+		case services.ErrInvalidCredentials:
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Password is incorrect",
+			})
+		case services.ErrInvalidTwoFactorCode:
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid authentication code",
+			})
+		case services.ErrPlayerNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Player not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to disable two-factor authentication",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Two-factor authentication disabled",
+	})
+}
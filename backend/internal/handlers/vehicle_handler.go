@@ -28,7 +28,7 @@ func (h *VehicleHandler) GetAvailableVehicles(c *gin.Context) {
 
 // GetPlayerVehicles handles GET /api/v1/vehicles
 func (h *VehicleHandler) GetPlayerVehicles(c *gin.Context) {
-	playerID, exists := c.Get("playerID")
+	playerID, exists := c.Get("player_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Player ID not found in context"})
 		return
@@ -45,7 +45,7 @@ func (h *VehicleHandler) GetPlayerVehicles(c *gin.Context) {
 
 // GetVehicle handles GET /api/v1/vehicles/:id
 func (h *VehicleHandler) GetVehicle(c *gin.Context) {
-	playerID, exists := c.Get("playerID")
+	playerID, exists := c.Get("player_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Player ID not found in context"})
 		return
@@ -76,7 +76,7 @@ func (h *VehicleHandler) GetVehicle(c *gin.Context) {
 
 // PurchaseVehicle handles POST /api/v1/vehicles/purchase
 func (h *VehicleHandler) PurchaseVehicle(c *gin.Context) {
-	playerID, exists := c.Get("playerID")
+	playerID, exists := c.Get("player_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Player ID not found in context"})
 		return
@@ -88,6 +88,9 @@ func (h *VehicleHandler) PurchaseVehicle(c *gin.Context) {
 		return
 	}
 
+	req.IPAddress = c.ClientIP()
+	req.RequestID = c.GetString("request_id")
+
 	vehicle, err := h.vehicleService.PurchaseVehicle(playerID.(uint), req)
 	if err != nil {
 		switch err {
@@ -117,7 +120,7 @@ func (h *VehicleHandler) PurchaseVehicle(c *gin.Context) {
 
 // UpgradeVehicle handles POST /api/v1/vehicles/upgrade
 func (h *VehicleHandler) UpgradeVehicle(c *gin.Context) {
-	playerID, exists := c.Get("playerID")
+	playerID, exists := c.Get("player_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Player ID not found in context"})
 		return
@@ -129,6 +132,9 @@ func (h *VehicleHandler) UpgradeVehicle(c *gin.Context) {
 		return
 	}
 
+	req.IPAddress = c.ClientIP()
+	req.RequestID = c.GetString("request_id")
+
 	vehicle, err := h.vehicleService.UpgradeVehicle(playerID.(uint), req)
 	if err != nil {
 		switch err {
@@ -10,30 +10,24 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/database/dbtest"
 	"zombie-car-game-backend/internal/middleware"
 	"zombie-car-game-backend/internal/models"
 	"zombie-car-game-backend/internal/services"
 )
 
 func setupVehicleTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
-	// Skip tests if CGO is not available
-	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
-	if err != nil {
-		t.Skip("SQLite requires CGO, skipping database tests")
-		return nil, nil
-	}
-
-	// Auto migrate the schema
-	err = db.AutoMigrate(&models.Player{}, &models.GameSession{}, &models.LevelProgress{}, &models.OwnedVehicle{})
-	require.NoError(t, err)
+	// Each test gets a database of its own; registering a player also issues
+	// tokens and queues the verification email
+	db := dbtest.Open(t, &models.Player{}, &models.GameSession{}, &models.LevelProgress{}, &models.OwnedVehicle{}, &models.AuditLog{},
+		&models.RefreshToken{}, &models.DeviceSession{}, &models.Sanction{}, &models.AccountToken{}, &models.OutboxEmail{}, &models.WalletTransaction{})
 
 	// Initialize services
-	playerService := services.NewPlayerService(db)
-	vehicleService := services.NewVehicleService(db, playerService)
 	jwtService := auth.NewJWTService()
+	playerService := services.NewPlayerService(db, jwtService, testAccountConfig)
+	vehicleService := services.NewVehicleService(db, playerService)
 
 	// Initialize handlers
 	vehicleHandler := NewVehicleHandler(vehicleService)
@@ -44,7 +38,7 @@ func setupVehicleTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
 
 	api := router.Group("/api/v1")
 	protected := api.Group("/")
-	protected.Use(middleware.AuthMiddleware(jwtService))
+	protected.Use(middleware.AuthMiddleware(jwtService, nil))
 
 	vehicles := protected.Group("/vehicles")
 	{
@@ -59,7 +53,7 @@ func setupVehicleTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
 }
 
 func createTestPlayerForVehicleHandler(t *testing.T, db *gorm.DB, currency int, level int) (*models.Player, string) {
-	playerService := services.NewPlayerService(db)
+	playerService := services.NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 	
 	req := services.CreatePlayerRequest{
 		Username: "testplayer",
@@ -221,7 +215,7 @@ func TestVehicleHandler_GetPlayerVehicles(t *testing.T) {
 	_, token := createTestPlayerForVehicleHandler(t, db, 5000, 5)
 
 	t.Run("get vehicles when player has none", func(t *testing.T) {
-		req, _ := http.NewRequest("GET", "/api/v1/vehicles", nil)
+		req, _ := http.NewRequest("GET", "/api/v1/vehicles/", nil)
 		req.Header.Set("Authorization", "Bearer "+token)
 
 		w := httptest.NewRecorder()
@@ -253,7 +247,7 @@ func TestVehicleHandler_GetPlayerVehicles(t *testing.T) {
 		require.Equal(t, http.StatusCreated, w.Code)
 
 		// Now get vehicles
-		req, _ = http.NewRequest("GET", "/api/v1/vehicles", nil)
+		req, _ = http.NewRequest("GET", "/api/v1/vehicles/", nil)
 		req.Header.Set("Authorization", "Bearer "+token)
 
 		w = httptest.NewRecorder()
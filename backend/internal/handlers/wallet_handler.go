@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"zombie-car-game-backend/internal/payments"
+	"zombie-car-game-backend/internal/services"
+)
+
+// WalletHandler handles wallet and store purchase requests
+type WalletHandler struct {
+	walletService   *services.WalletService
+	purchaseService *services.PurchaseService
+}
+
+// NewWalletHandler creates a new wallet handler
+func NewWalletHandler(walletService *services.WalletService, purchaseService *services.PurchaseService) *WalletHandler {
+	return &WalletHandler{
+		walletService:   walletService,
+		purchaseService: purchaseService,
+	}
+}
+
+// GetWallet handles GET /api/v1/wallet
+func (h *WalletHandler) GetWallet(c *gin.Context) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Player not authenticated",
+		})
+		return
+	}
+
+	wallet, err := h.walletService.GetWallet(playerID.(uint))
+	if err != nil {
+		switch err {
+		case services.ErrPlayerNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Player not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to retrieve wallet",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Wallet retrieved successfully",
+		"data":    wallet,
+	})
+}
+
+// GetProducts handles GET /api/v1/wallet/products
+func (h *WalletHandler) GetProducts(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"products": h.purchaseService.GetProducts()})
+}
+
+// VerifyPurchase handles POST /api/v1/wallet/purchases
+func (h *WalletHandler) VerifyPurchase(c *gin.Context) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Player not authenticated",
+		})
+		return
+	}
+
+	var req services.VerifyPurchaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.purchaseService.VerifyPurchase(c.Request.Context(), playerID.(uint), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, payments.ErrUnknownStore):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported store"})
+		case errors.Is(err, payments.ErrInvalidReceipt):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid receipt"})
+		case errors.Is(err, payments.ErrReceiptPending):
+			c.JSON(http.StatusAccepted, gin.H{"error": "Purchase has not completed yet"})
+		case errors.Is(err, payments.ErrAccountNotLinked):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Link your store account first"})
+		case errors.Is(err, payments.ErrAccountMismatch):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Receipt belongs to another store account"})
+		case errors.Is(err, payments.ErrMultiItemOrder):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Orders with more than one item are not supported"})
+		case errors.Is(err, payments.ErrStoreUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Store verification is unavailable, please retry"})
+		case errors.Is(err, services.ErrUnknownProduct):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown product"})
+		case errors.Is(err, services.ErrReceiptAlreadyUsed):
+			c.JSON(http.StatusConflict, gin.H{"error": "Receipt already used"})
+		case errors.Is(err, services.ErrPurchaseRefunded):
+			c.JSON(http.StatusConflict, gin.H{"error": "Purchase has been refunded"})
+		case errors.Is(err, services.ErrPurchasePartiallyRefunded):
+			c.JSON(http.StatusConflict, gin.H{"error": "Purchase has been partially refunded, please contact support"})
+		case errors.Is(err, services.ErrPlayerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Player not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify purchase"})
+		}
+		return
+	}
+
+	status := http.StatusCreated
+	if result.Duplicate {
+		status = http.StatusOK
+	}
+
+	c.JSON(status, gin.H{
+		"message": "Purchase verified successfully",
+		"data":    result,
+	})
+}
+
+// LinkStoreAccount handles POST /api/v1/wallet/store-accounts
+func (h *WalletHandler) LinkStoreAccount(c *gin.Context) {
+	playerID, exists := c.Get("player_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Player not authenticated",
+		})
+		return
+	}
+
+	var req services.LinkStoreAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	account, err := h.purchaseService.LinkStoreAccount(c.Request.Context(), playerID.(uint), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, payments.ErrUnknownStore), errors.Is(err, services.ErrStoreAccountUnsupported):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported store"})
+		case errors.Is(err, payments.ErrInvalidProof):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account proof"})
+		case errors.Is(err, payments.ErrStoreUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Store verification is unavailable, please retry"})
+		case errors.Is(err, services.ErrStoreAccountTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": "Store account is linked to another player"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link store account"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Store account linked successfully",
+		"data":    account,
+	})
+}
+
+// RefundPurchase handles POST /api/v1/admin/purchases/refund
+func (h *WalletHandler) RefundPurchase(c *gin.Context) {
+	var req services.RefundPurchaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	purchase, err := h.purchaseService.RefundPurchase(req.Store, req.TransactionID)
+	if err != nil {
+		switch err {
+		case services.ErrPurchaseNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Purchase not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to refund purchase",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Purchase refunded successfully",
+		"data":    purchase,
+	})
+}
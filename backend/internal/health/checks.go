@@ -0,0 +1,46 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// ErrNotConfigured is reported by checks of dependencies the server started without
+var ErrNotConfigured = errors.New("not configured")
+
+// DatabaseCheck pings db. The API cannot serve anything without it, so it is critical.
+func DatabaseCheck(db *gorm.DB) Check {
+	return Check{
+		Name:     "database",
+		Critical: true,
+		Probe: func(ctx context.Context) error {
+			if db == nil {
+				return ErrNotConfigured
+			}
+			sqlDB, err := db.DB()
+			if err != nil {
+				return fmt.Errorf("failed to get database connection: %w", err)
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	}
+}
+
+// RedisCheck pings client, which may be nil. Without Redis, rate limits and
+// login throttling fall back to per-process state and token revocation checks
+// fail open, so it only degrades the server.
+func RedisCheck(client *redis.Client) Check {
+	return Check{
+		Name: "redis",
+		Probe: func(ctx context.Context) error {
+			if client == nil {
+				return ErrNotConfigured
+			}
+			return client.Ping(ctx).Err()
+		},
+	}
+}
@@ -0,0 +1,186 @@
+// Package health runs dependency checks for the liveness and readiness probes.
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Overall and per-check statuses
+const (
+	StatusOK          = "ok"
+	StatusDegraded    = "degraded"
+	StatusUnavailable = "unavailable"
+	StatusDown        = "down"
+)
+
+// Default check settings
+const (
+	DefaultTimeout  = 2 * time.Second
+	DefaultCacheTTL = 5 * time.Second
+)
+
+// ErrShuttingDown is reported once the server has started shutting down
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// Check is a dependency probe. A failing critical check makes the server
+// unavailable; a failing optional one only degrades it.
+type Check struct {
+	Name     string
+	Critical bool
+	// Timeout bounds each probe; DefaultTimeout if zero
+	Timeout time.Duration
+	Probe   func(ctx context.Context) error
+}
+
+// CheckResult is the outcome of the most recent probe of a check
+type CheckResult struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMS float64   `json:"latency_ms"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report is the readiness of the server as a whole
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// Checker runs checks and caches their results, so frequent probes from the
+// orchestrator and load balancers do not hammer the dependencies
+type Checker struct {
+	cacheTTL     time.Duration
+	checks       []*cachedCheck
+	shuttingDown atomic.Bool
+}
+
+type cachedCheck struct {
+	Check
+
+	mu     sync.Mutex
+	result CheckResult
+}
+
+// NewChecker creates a checker caching results for cacheTTL
+func NewChecker(cacheTTL time.Duration, checks ...Check) *Checker {
+	c := &Checker{cacheTTL: cacheTTL}
+	for _, check := range checks {
+		if check.Timeout <= 0 {
+			check.Timeout = DefaultTimeout
+		}
+		c.checks = append(c.checks, &cachedCheck{Check: check})
+	}
+	return c
+}
+
+// SetShuttingDown makes the server report itself unavailable so traffic drains
+// away before it stops
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Report runs every check whose cached result has expired, concurrently, and
+// combines the results
+func (c *Checker) Report(ctx context.Context) Report {
+	results := make([]CheckResult, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func(i int, check *cachedCheck) {
+			defer wg.Done()
+			results[i] = check.run(ctx, c.cacheTTL)
+		}(i, check)
+	}
+	wg.Wait()
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	report := Report{Status: StatusOK, Checks: results}
+	for _, result := range results {
+		if result.Status == StatusOK {
+			continue
+		}
+		if result.Critical {
+			report.Status = StatusUnavailable
+		} else if report.Status == StatusOK {
+			report.Status = StatusDegraded
+		}
+	}
+	if c.shuttingDown.Load() {
+		report.Status = StatusUnavailable
+		report.Checks = append(report.Checks, CheckResult{
+			Name:      "shutdown",
+			Status:    StatusDown,
+			Critical:  true,
+			Error:     ErrShuttingDown.Error(),
+			CheckedAt: time.Now().UTC(),
+		})
+	}
+	return report
+}
+
+// run probes the check unless its cached result is still fresh
+func (c *cachedCheck) run(ctx context.Context, ttl time.Duration) CheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.result.CheckedAt.IsZero() && time.Since(c.result.CheckedAt) < ttl {
+		return c.result
+	}
+
+	// The result is shared with other callers, so a caller disconnecting must not fail it
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.Probe(ctx)
+	result := CheckResult{
+		Name:      c.Name,
+		Status:    StatusOK,
+		Critical:  c.Critical,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		CheckedAt: start.UTC(),
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	c.result = result
+	return result
+}
+
+// LivezHandler reports that the process is running and able to serve
+// requests. It deliberately checks no dependencies, so an outage of the
+// database does not get every replica restarted.
+func LivezHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": StatusOK})
+}
+
+// ReadyzHandler creates a handler reporting whether the server should receive
+// traffic: 200 when every critical dependency is up, even if optional ones are
+// down, and 503 otherwise. The per-check detail is included with ?verbose=true.
+func ReadyzHandler(checker *Checker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := checker.Report(c.Request.Context())
+
+		code := http.StatusOK
+		if report.Status == StatusUnavailable {
+			code = http.StatusServiceUnavailable
+		}
+
+		if c.Query("verbose") == "true" {
+			c.JSON(code, report)
+			return
+		}
+		c.JSON(code, gin.H{"status": report.Status})
+	}
+}
@@ -0,0 +1,117 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDependency is a probe whose health the test controls
+type fakeDependency struct {
+	calls atomic.Int32
+	err   atomic.Value
+}
+
+func (d *fakeDependency) probe(ctx context.Context) error {
+	d.calls.Add(1)
+	if err, ok := d.err.Load().(error); ok && err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *fakeDependency) fail(err error) {
+	d.err.Store(err)
+}
+
+func serveReadyz(t *testing.T, checker *Checker, query string) (int, Report) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/readyz", ReadyzHandler(checker))
+
+	req, _ := http.NewRequest("GET", "/readyz"+query, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var report Report
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	return w.Code, report
+}
+
+func TestReadyz_DependencyCriticality(t *testing.T) {
+	database, redis := &fakeDependency{}, &fakeDependency{}
+	checker := NewChecker(0,
+		Check{Name: "database", Critical: true, Probe: database.probe},
+		Check{Name: "redis", Probe: redis.probe},
+	)
+
+	code, report := serveReadyz(t, checker, "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, StatusOK, report.Status)
+	assert.Empty(t, report.Checks, "detail is only shown on request")
+
+	// An optional dependency failing degrades the server but keeps it in rotation
+	redis.fail(errors.New("connection refused"))
+	code, report = serveReadyz(t, checker, "?verbose=true")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, StatusDegraded, report.Status)
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, "redis", report.Checks[1].Name)
+	assert.Equal(t, StatusDown, report.Checks[1].Status)
+	assert.Equal(t, "connection refused", report.Checks[1].Error)
+
+	// A critical one failing takes it out
+	database.fail(errors.New("too many connections"))
+	code, report = serveReadyz(t, checker, "")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, StatusUnavailable, report.Status)
+}
+
+func TestChecker_CachesResultsAndTimesOut(t *testing.T) {
+	database := &fakeDependency{}
+	slow := Check{
+		Name:    "slow",
+		Timeout: 10 * time.Millisecond,
+		Probe: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	checker := NewChecker(time.Minute, Check{Name: "database", Critical: true, Probe: database.probe}, slow)
+
+	report := checker.Report(context.Background())
+	assert.Equal(t, StatusDegraded, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks[1].Error)
+
+	// Within the cache TTL the dependencies are not probed again, even after they change
+	database.fail(errors.New("down"))
+	report = checker.Report(context.Background())
+	assert.Equal(t, int32(1), database.calls.Load())
+	assert.Equal(t, StatusDegraded, report.Status)
+}
+
+func TestChecker_ShuttingDown(t *testing.T) {
+	checker := NewChecker(0, Check{Name: "database", Critical: true, Probe: (&fakeDependency{}).probe})
+	checker.SetShuttingDown()
+
+	code, report := serveReadyz(t, checker, "")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, StatusUnavailable, report.Status)
+
+	// Liveness is unaffected; the process is still serving in-flight requests
+	r := gin.New()
+	r.GET("/livez", LivezHandler)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/livez", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,32 @@
+package lifecycle
+
+import (
+	"net/http"
+	"time"
+)
+
+// ServerConfig holds the HTTP server's timeouts and how it shuts down
+type ServerConfig struct {
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// DrainDelay is how long the server keeps accepting requests after readiness
+	// starts failing, so load balancers stop routing to it first
+	DrainDelay time.Duration
+	// ShutdownTimeout bounds waiting for in-flight requests, and then again for
+	// background workers, before they are abandoned
+	ShutdownTimeout time.Duration
+}
+
+// NewServer creates an HTTP server for handler with the timeouts in cfg
+func NewServer(addr string, handler http.Handler, cfg ServerConfig) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+}
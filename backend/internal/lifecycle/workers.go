@@ -0,0 +1,64 @@
+// Package lifecycle runs the server's background workers and stops them in
+// order during shutdown.
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// Workers runs background jobs, each with its own context, so shutdown can stop
+// them one at a time and wait for each to finish its current pass
+type Workers struct {
+	mu      sync.Mutex
+	workers []*worker
+}
+
+type worker struct {
+	name   string
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Go starts run in a goroutine. run must return soon after its context is cancelled.
+func (w *Workers) Go(name string, run func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &worker{name: name, cancel: cancel, done: make(chan struct{})}
+
+	w.mu.Lock()
+	w.workers = append(w.workers, job)
+	w.mu.Unlock()
+
+	go func() {
+		defer close(job.done)
+		run(ctx)
+	}()
+}
+
+// Stop stops the workers in reverse start order, waiting for each to return
+// before stopping the next, so a worker started after another may rely on it
+// until it has stopped. It gives up when ctx is done, leaving the remaining
+// workers cancelled but possibly still running.
+func (w *Workers) Stop(ctx context.Context) error {
+	w.mu.Lock()
+	workers := w.workers
+	w.workers = nil
+	w.mu.Unlock()
+
+	for i := len(workers) - 1; i >= 0; i-- {
+		job := workers[i]
+		job.cancel()
+		select {
+		case <-job.done:
+			slog.Info("Stopped background worker", "worker", job.name)
+		case <-ctx.Done():
+			for _, remaining := range workers[:i] {
+				remaining.cancel()
+			}
+			return fmt.Errorf("timed out stopping worker %s: %w", job.name, ctx.Err())
+		}
+	}
+	return nil
+}
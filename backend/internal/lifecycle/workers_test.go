@@ -0,0 +1,63 @@
+package lifecycle
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkers_StopInReverseOrder(t *testing.T) {
+	var mu sync.Mutex
+	var stopped []string
+	record := func(name string) func(ctx context.Context) {
+		return func(ctx context.Context) {
+			<-ctx.Done()
+			mu.Lock()
+			stopped = append(stopped, name)
+			mu.Unlock()
+		}
+	}
+
+	var workers Workers
+	workers.Go("mail_outbox", record("mail_outbox"))
+	workers.Go("guest_cleanup", record("guest_cleanup"))
+	workers.Go("audit_retention", record("audit_retention"))
+
+	require.NoError(t, workers.Stop(context.Background()))
+	assert.Equal(t, []string{"audit_retention", "guest_cleanup", "mail_outbox"}, stopped)
+
+	// Stopping again is a no-op
+	require.NoError(t, workers.Stop(context.Background()))
+}
+
+func TestWorkers_StopTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	var workers Workers
+	firstCancelled := make(chan struct{})
+	workers.Go("first", func(ctx context.Context) {
+		<-ctx.Done()
+		close(firstCancelled)
+	})
+	workers.Go("stuck", func(ctx context.Context) {
+		<-release
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := workers.Stop(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stuck")
+
+	// Workers that were not waited for are still told to stop
+	select {
+	case <-firstCancelled:
+	case <-time.After(time.Second):
+		t.Fatal("remaining worker was not cancelled")
+	}
+}
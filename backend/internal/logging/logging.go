@@ -0,0 +1,127 @@
+// Package logging configures structured logging with log/slog and carries
+// request-scoped fields, such as the request ID, through contexts.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Config selects the log level and output format
+type Config struct {
+	Level slog.Level
+	// Format is "json" (the default) or "text"
+	Format string
+	// File, if set, receives a copy of every record, for log shippers that tail files
+	File string
+}
+
+// Setup makes a logger built from cfg the default for both log/slog and the
+// standard log package, and returns it. Records go to w and, if cfg.File is
+// set, are appended to that file; a file that cannot be opened is reported
+// and skipped rather than stopping the server.
+func Setup(cfg Config, w io.Writer) *slog.Logger {
+	var fileErr error
+	if cfg.File != "" {
+		file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			fileErr = err
+		} else {
+			w = io.MultiWriter(w, file)
+		}
+	}
+
+	logger := slog.New(NewHandler(cfg, w))
+	slog.SetDefault(logger)
+	if fileErr != nil {
+		logger.Warn("Failed to open LOG_FILE, skipping it", "file", cfg.File, "error", fileErr)
+	}
+	return logger
+}
+
+// NewHandler creates a handler writing records to w. JSON records use the
+// field names the log pipeline expects: timestamp, level and message.
+func NewHandler(cfg Config, w io.Writer) slog.Handler {
+	opts := &slog.HandlerOptions{Level: cfg.Level}
+
+	var handler slog.Handler
+	if cfg.Format == "text" {
+		handler = slog.NewTextHandler(w, opts)
+	} else {
+		opts.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) > 0 {
+				return a
+			}
+			switch a.Key {
+			case slog.TimeKey:
+				return slog.String("timestamp", a.Value.Time().Format("2006-01-02T15:04:05.000-0700"))
+			case slog.MessageKey:
+				a.Key = "message"
+			case slog.LevelKey:
+				return slog.String("level", strings.ToLower(a.Value.String()))
+			}
+			return a
+		}
+		handler = slog.NewJSONHandler(w, opts)
+	}
+
+	return contextHandler{handler}
+}
+
+type attrsKey struct{}
+
+// WithAttrs returns a copy of ctx whose log records carry attrs, in addition
+// to any attributes ctx already carries
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	combined := make([]slog.Attr, 0, len(existing)+len(attrs))
+	combined = append(combined, existing...)
+	combined = append(combined, attrs...)
+	return context.WithValue(ctx, attrsKey{}, combined)
+}
+
+// FromContext returns the default logger with the attributes carried by ctx
+// already attached, for code that hands a logger on rather than logging with ctx
+func FromContext(ctx context.Context) *slog.Logger {
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	if len(attrs) == 0 {
+		return slog.Default()
+	}
+	args := make([]any, len(attrs))
+	for i, attr := range attrs {
+		args[i] = attr
+	}
+	return slog.Default().With(args...)
+}
+
+// contextHandler adds the attributes carried by the context to every record,
+// along with the IDs of the trace span the context belongs to, if any
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs, ok := ctx.Value(attrsKey{}).([]slog.Attr); ok {
+		r.AddAttrs(attrs...)
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		r.AddAttrs(
+			slog.String("trace_id", span.TraceID().String()),
+			slog.String("span_id", span.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
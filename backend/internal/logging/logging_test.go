@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &record), line)
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestHandler_JSONFieldsAndContextAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(Config{Level: slog.LevelInfo, Format: "json"}, &buf))
+
+	ctx := WithAttrs(context.Background(), slog.String("request_id", "req-1"))
+	ctx = WithAttrs(ctx, slog.Uint64("player_id", 42))
+	logger.InfoContext(ctx, "Request handled", "status", 200)
+	logger.Debug("Hidden below the configured level")
+
+	records := decodeLines(t, &buf)
+	require.Len(t, records, 1)
+	record := records[0]
+	assert.Equal(t, "Request handled", record["message"])
+	assert.Equal(t, "info", record["level"])
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, float64(42), record["player_id"])
+	assert.Equal(t, float64(200), record["status"])
+
+	// The format the log pipeline parses timestamps with
+	_, err := time.Parse("2006-01-02T15:04:05.000-0700", record["timestamp"].(string))
+	assert.NoError(t, err)
+}
+
+func TestGormLogger_Levels(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(NewHandler(Config{Level: slog.LevelInfo}, &buf)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	gormLogger := NewGormLogger(100 * time.Millisecond)
+	ctx := WithAttrs(context.Background(), slog.String("request_id", "req-2"))
+	query := func() (string, int64) { return "SELECT 1", 1 }
+
+	// Fast queries are debug only, and record-not-found is not a failure
+	gormLogger.Trace(ctx, time.Now(), query, nil)
+	gormLogger.Trace(ctx, time.Now(), query, gorm.ErrRecordNotFound)
+	assert.Empty(t, buf.String())
+
+	gormLogger.Trace(ctx, time.Now().Add(-time.Second), query, nil)
+	gormLogger.Trace(ctx, time.Now(), query, errors.New("relation does not exist"))
+
+	records := decodeLines(t, &buf)
+	require.Len(t, records, 2)
+	assert.Equal(t, "Slow query", records[0]["message"])
+	assert.Equal(t, "warn", records[0]["level"])
+	assert.Equal(t, "SELECT 1", records[0]["sql"])
+	assert.Equal(t, "req-2", records[0]["request_id"])
+	assert.Equal(t, "Query failed", records[1]["message"])
+	assert.Equal(t, "relation does not exist", records[1]["error"])
+}
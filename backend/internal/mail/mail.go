@@ -0,0 +1,66 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers email messages
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config selects and configures the mail sender
+type Config struct {
+	// Sender is "smtp", "file" or "log"
+	Sender string
+	From   string
+	// SMTP settings, used by the smtp sender
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	// FileDir is where the file sender writes messages
+	FileDir string
+}
+
+// NewSender creates the sender selected by cfg.Sender.
+//
+// Supported values are "smtp" (delivers through cfg.SMTPHost), "file" (writes
+// messages into cfg.FileDir) and "log" (the default, prints messages to the
+// server log).
+func NewSender(cfg Config) (Sender, error) {
+	from := cfg.From
+	if from == "" {
+		from = "Zombie Car Game <no-reply@localhost>"
+	}
+
+	switch cfg.Sender {
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("an SMTP host is required for the smtp mail sender")
+		}
+		port := cfg.SMTPPort
+		if port == "" {
+			port = "587"
+		}
+		return NewSMTPSender(cfg.SMTPHost, port, cfg.SMTPUsername, cfg.SMTPPassword, from), nil
+	case "file":
+		dir := cfg.FileDir
+		if dir == "" {
+			dir = "tmp/mail"
+		}
+		return NewFileSender(dir, from)
+	case "", "log":
+		return NewLogSender(from), nil
+	default:
+		return nil, fmt.Errorf("unknown mail sender %q", cfg.Sender)
+	}
+}
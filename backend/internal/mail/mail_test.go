@@ -0,0 +1,55 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSender_Send(t *testing.T) {
+	dir := t.TempDir()
+	sender, err := NewFileSender(dir, "Game <no-reply@example.com>")
+	require.NoError(t, err)
+
+	require.NoError(t, sender.Send(context.Background(), Message{
+		To:      "player@example.com",
+		Subject: "Hello",
+		Body:    "line one\nline two",
+	}))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "To: player@example.com\r\n")
+	assert.Contains(t, string(data), "Subject: Hello\r\n")
+	assert.Contains(t, string(data), "line one\r\nline two")
+}
+
+func TestNewSender(t *testing.T) {
+	sender, err := NewSender(Config{})
+	require.NoError(t, err)
+	assert.IsType(t, &LogSender{}, sender)
+
+	sender, err = NewSender(Config{Sender: "file", FileDir: t.TempDir()})
+	require.NoError(t, err)
+	assert.IsType(t, &FileSender{}, sender)
+
+	_, err = NewSender(Config{Sender: "smtp"})
+	assert.Error(t, err)
+
+	_, err = NewSender(Config{Sender: "pigeon"})
+	assert.Error(t, err)
+}
+
+func TestEnvelopeAddress(t *testing.T) {
+	addr, err := envelopeAddress("Game <no-reply@example.com>")
+	require.NoError(t, err)
+	assert.Equal(t, "no-reply@example.com", addr)
+}
@@ -0,0 +1,148 @@
+// Package metrics exposes Prometheus metrics for the HTTP API, its database and
+// Redis connections, and gameplay events.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by route, method and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by route and method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	httpErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_request_errors_total",
+		Help: "HTTP requests answered with a server error, by route and method.",
+	}, []string{"method", "route"})
+
+	gameSessions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "game_sessions_total",
+		Help: "Game sessions started, and ended by final state.",
+	}, []string{"state"})
+
+	scoreRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "game_score_validation_rejections_total",
+		Help: "Score submissions rejected by anti-cheat validation, by rule.",
+	}, []string{"reason"})
+
+	currencyEarned = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "game_currency_earned_total",
+		Help: "Currency credited to players, by currency and source.",
+	}, []string{"currency", "source"})
+
+	currencySpent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "game_currency_spent_total",
+		Help: "Currency debited from players, by currency and reason.",
+	}, []string{"currency", "reason"})
+
+	vehiclesPurchased = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "game_vehicles_purchased_total",
+		Help: "Vehicles bought with currency, by vehicle type.",
+	}, []string{"vehicle_type"})
+)
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Middleware creates a middleware recording request counts, latency and server
+// errors. Requests are labelled with the route pattern rather than the raw path
+// so IDs in URLs do not create a series each.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		status := c.Writer.Status()
+
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+		httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+		if status >= http.StatusInternalServerError {
+			httpErrors.WithLabelValues(method, route).Inc()
+		}
+	}
+}
+
+// RegisterDatabase exports the connection pool statistics of db, the same
+// figures /api/v1/status/db reports, plus the number of players in a game
+func RegisterDatabase(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	if err := prometheus.Register(collectors.NewDBStatsCollector(sqlDB, "main")); err != nil {
+		return err
+	}
+
+	return prometheus.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "game_active_players",
+		Help: "Players with a game session in progress.",
+	}, func() float64 {
+		var active int64
+		db.Table("game_sessions").
+			Where("session_state = ?", "active").
+			Distinct("player_id").
+			Count(&active)
+		return float64(active)
+	}))
+}
+
+// SessionStarted counts a new game session
+func SessionStarted() {
+	gameSessions.WithLabelValues("started").Inc()
+}
+
+// SessionsEnded counts n game sessions ending in state
+func SessionsEnded(state string, n int) {
+	if n > 0 {
+		gameSessions.WithLabelValues(state).Add(float64(n))
+	}
+}
+
+// ScoreRejected counts a score submission that failed the validation rule reason
+func ScoreRejected(reason string) {
+	scoreRejections.WithLabelValues(reason).Inc()
+}
+
+// CurrencyEarned counts currency credited to a player
+func CurrencyEarned(currency, source string, amount int) {
+	if amount > 0 {
+		currencyEarned.WithLabelValues(currency, source).Add(float64(amount))
+	}
+}
+
+// CurrencySpent counts currency debited from a player
+func CurrencySpent(currency, reason string, amount int) {
+	if amount > 0 {
+		currencySpent.WithLabelValues(currency, reason).Add(float64(amount))
+	}
+}
+
+// VehiclePurchased counts a vehicle bought with currency
+func VehiclePurchased(vehicleType string) {
+	vehiclesPurchased.WithLabelValues(vehicleType).Inc()
+}
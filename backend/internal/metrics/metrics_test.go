@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware_LabelsByRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(Middleware())
+	r.GET("/players/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/fail", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+	r.GET("/metrics", gin.WrapH(Handler()))
+
+	for _, path := range []string{"/players/1", "/players/2"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	req, _ := http.NewRequest("POST", "/fail", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/players/:id", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(httpErrors.WithLabelValues("POST", "/fail")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(httpErrors.WithLabelValues("GET", "/players/:id")))
+
+	SessionStarted()
+	SessionsEnded("abandoned", 0)
+	ScoreRejected("score_rate")
+	CurrencySpent("soft", "vehicle_purchase", 500)
+	VehiclePurchased("sedan")
+
+	req, _ = http.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	body := w.Body.String()
+	assert.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/players/:id"} 2`)
+	assert.Contains(t, body, `game_sessions_total{state="started"} 1`)
+	assert.NotContains(t, body, `game_sessions_total{state="abandoned"}`)
+	assert.Contains(t, body, `game_score_validation_rejections_total{reason="score_rate"} 1`)
+	assert.Contains(t, body, `game_currency_spent_total{currency="soft",reason="vehicle_purchase"} 500`)
+	assert.Contains(t, body, `game_vehicles_purchased_total{vehicle_type="sedan"} 1`)
+}
@@ -1,15 +1,26 @@
 package middleware
 
 import (
+	"log/slog"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/logging"
+	"zombie-car-game-backend/internal/models"
 )
 
-// AuthMiddleware creates a middleware for JWT authentication
-func AuthMiddleware(jwtService *auth.JWTService) gin.HandlerFunc {
+// SanctionChecker looks up the ban or suspension currently barring a player, if any
+type SanctionChecker interface {
+	ActiveAccessSanction(playerID uint) (*models.Sanction, error)
+}
+
+// AuthMiddleware creates a middleware for JWT authentication.
+// If sanctions is not nil, banned and suspended players are refused with a 403
+// explaining the sanction, and requests are refused with a 503 while sanctions
+// cannot be looked up.
+func AuthMiddleware(jwtService *auth.JWTService, sanctions SanctionChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -37,6 +48,8 @@ func AuthMiddleware(jwtService *auth.JWTService) gin.HandlerFunc {
 			switch err {
 			case auth.ErrExpiredToken:
 				message = "Token has expired"
+			case auth.ErrRevokedToken:
+				message = "Token has been revoked"
 			case auth.ErrInvalidToken:
 				message = "Invalid token"
 			default:
@@ -50,9 +63,29 @@ func AuthMiddleware(jwtService *auth.JWTService) gin.HandlerFunc {
 			return
 		}
 
+		if sanctions != nil {
+			// Fails closed, so a ban cannot be sidestepped by overloading the lookup
+			sanction, err := sanctions.ActiveAccessSanction(claims.PlayerID)
+			if err != nil {
+				slog.ErrorContext(c.Request.Context(), "Sanction lookup failed", "error", err)
+				c.JSON(http.StatusServiceUnavailable, gin.H{
+					"error": "Service temporarily unavailable, please retry",
+				})
+				c.Abort()
+				return
+			}
+			if sanction != nil {
+				c.JSON(http.StatusForbidden, sanction.Notice())
+				c.Abort()
+				return
+			}
+		}
+
 		// Set player information in context
 		c.Set("player_id", claims.PlayerID)
 		c.Set("username", claims.Username)
+		c.Set("token_claims", claims)
+		c.Request = c.Request.WithContext(logging.WithAttrs(c.Request.Context(), slog.Uint64("player_id", uint64(claims.PlayerID))))
 		c.Next()
 	}
 }
@@ -77,8 +110,48 @@ func OptionalAuthMiddleware(jwtService *auth.JWTService) gin.HandlerFunc {
 		if err == nil {
 			c.Set("player_id", claims.PlayerID)
 			c.Set("username", claims.Username)
+			c.Set("token_claims", claims)
+			c.Request = c.Request.WithContext(logging.WithAttrs(c.Request.Context(), slog.Uint64("player_id", uint64(claims.PlayerID))))
 		}
 
 		c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// AdminMiddleware restricts a route group to staff roles. It must run after AuthMiddleware.
+func AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !auth.IsStaff(tokenRole(c)) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Insufficient permissions",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequirePermission restricts a route to roles granted permission. It must run after AuthMiddleware.
+func RequirePermission(permission auth.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !auth.HasPermission(tokenRole(c), permission) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Insufficient permissions",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// tokenRole returns the role carried by the request's access token
+func tokenRole(c *gin.Context) string {
+	if value, ok := c.Get("token_claims"); ok {
+		if claims, ok := value.(*auth.Claims); ok {
+			return claims.Role
+		}
+	}
+	return ""
+}
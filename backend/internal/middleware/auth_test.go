@@ -1,21 +1,18 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
 )
 
 func TestAuthMiddleware_ValidToken(t *testing.T) {
-	// Set test secret
-	os.Setenv("JWT_SECRET", "test-secret-key")
-	defer os.Unsetenv("JWT_SECRET")
-
 	gin.SetMode(gin.TestMode)
 	
 	jwtService := auth.NewJWTService()
@@ -24,7 +21,7 @@ func TestAuthMiddleware_ValidToken(t *testing.T) {
 
 	// Create test router
 	r := gin.New()
-	r.Use(AuthMiddleware(jwtService))
+	r.Use(AuthMiddleware(jwtService, nil))
 	r.GET("/test", func(c *gin.Context) {
 		playerID, exists := c.Get("player_id")
 		assert.True(t, exists)
@@ -55,7 +52,7 @@ func TestAuthMiddleware_MissingToken(t *testing.T) {
 
 	// Create test router
 	r := gin.New()
-	r.Use(AuthMiddleware(jwtService))
+	r.Use(AuthMiddleware(jwtService, nil))
 	r.GET("/test", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "success"})
 	})
@@ -78,7 +75,7 @@ func TestAuthMiddleware_InvalidTokenFormat(t *testing.T) {
 
 	// Create test router
 	r := gin.New()
-	r.Use(AuthMiddleware(jwtService))
+	r.Use(AuthMiddleware(jwtService, nil))
 	r.GET("/test", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "success"})
 	})
@@ -102,7 +99,7 @@ func TestAuthMiddleware_InvalidToken(t *testing.T) {
 
 	// Create test router
 	r := gin.New()
-	r.Use(AuthMiddleware(jwtService))
+	r.Use(AuthMiddleware(jwtService, nil))
 	r.GET("/test", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "success"})
 	})
@@ -120,10 +117,6 @@ func TestAuthMiddleware_InvalidToken(t *testing.T) {
 }
 
 func TestOptionalAuthMiddleware_WithValidToken(t *testing.T) {
-	// Set test secret
-	os.Setenv("JWT_SECRET", "test-secret-key")
-	defer os.Unsetenv("JWT_SECRET")
-
 	gin.SetMode(gin.TestMode)
 	
 	jwtService := auth.NewJWTService()
@@ -211,4 +204,117 @@ func TestOptionalAuthMiddleware_WithInvalidToken(t *testing.T) {
 	r.ServeHTTP(w, req)
 
 	assert.Equal(t, 200, w.Code)
-}
\ No newline at end of file
+}
+func TestAdminMiddleware_Roles(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	jwtService := auth.NewJWTService()
+
+	r := gin.New()
+	admin := r.Group("/admin")
+	admin.Use(AuthMiddleware(jwtService, nil), AdminMiddleware())
+	admin.GET("/players", func(c *gin.Context) {
+		c.JSON(200, gin.H{"message": "success"})
+	})
+	admin.POST("/roles", RequirePermission(auth.PermissionManageRoles), func(c *gin.Context) {
+		c.JSON(200, gin.H{"message": "success"})
+	})
+
+	tests := []struct {
+		role        string
+		wantPlayers int
+		wantRoles   int
+	}{
+		{auth.RolePlayer, 403, 403},
+		{auth.RoleSupport, 200, 403},
+		{auth.RoleModerator, 200, 403},
+		{auth.RoleAdmin, 200, 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			token, err := jwtService.GenerateSessionToken(1, "staff", tt.role, "")
+			assert.NoError(t, err)
+
+			req, _ := http.NewRequest("GET", "/admin/players", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantPlayers, w.Code)
+
+			req, _ = http.NewRequest("POST", "/admin/roles", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantRoles, w.Code)
+		})
+	}
+}
+
+type stubSanctionChecker map[uint]*models.Sanction
+
+func (s stubSanctionChecker) ActiveAccessSanction(playerID uint) (*models.Sanction, error) {
+	return s[playerID], nil
+}
+
+func TestAuthMiddleware_BannedPlayer(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	jwtService := auth.NewJWTService()
+	sanctions := stubSanctionChecker{
+		2: {ID: 9, PlayerID: 2, Type: models.SanctionBan, Reason: "aimbot"},
+	}
+
+	r := gin.New()
+	r.Use(AuthMiddleware(jwtService, sanctions))
+	r.GET("/test", func(c *gin.Context) {
+		c.JSON(200, gin.H{"message": "success"})
+	})
+
+	token, err := jwtService.GenerateToken(1, "fairplayer")
+	assert.NoError(t, err)
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	token, err = jwtService.GenerateToken(2, "cheater")
+	assert.NoError(t, err)
+	req, _ = http.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, 403, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"account_banned"`)
+	assert.Contains(t, w.Body.String(), `"reason":"aimbot"`)
+}
+
+type failingSanctionChecker struct{}
+
+func (failingSanctionChecker) ActiveAccessSanction(playerID uint) (*models.Sanction, error) {
+	return nil, errors.New("database unavailable")
+}
+
+func TestAuthMiddleware_SanctionLookupFailsClosed(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	jwtService := auth.NewJWTService()
+
+	r := gin.New()
+	r.Use(AuthMiddleware(jwtService, failingSanctionChecker{}))
+	r.GET("/test", func(c *gin.Context) {
+		c.JSON(200, gin.H{"message": "success"})
+	})
+
+	token, err := jwtService.GenerateToken(1, "player")
+	assert.NoError(t, err)
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,317 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimitPolicy is a token bucket: Limit requests may be made in a burst, and
+// the bucket refills completely over Window
+type RateLimitPolicy struct {
+	// Name separates the buckets of different policies and appears in RateLimit-Policy
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// Default policies, overridable in the config with <limit>/<window>, e.g. 20/1m
+var (
+	// RateLimitAuth covers login, refresh and the other unauthenticated account endpoints
+	RateLimitAuth = RateLimitPolicy{Name: "auth", Limit: 20, Window: time.Minute}
+	// RateLimitRegister makes scripted account creation slow
+	RateLimitRegister = RateLimitPolicy{Name: "register", Limit: 5, Window: time.Hour}
+	// RateLimitRead covers authenticated GET requests
+	RateLimitRead = RateLimitPolicy{Name: "read", Limit: 300, Window: time.Minute}
+	// RateLimitWrite covers every other authenticated request
+	RateLimitWrite = RateLimitPolicy{Name: "write", Limit: 60, Window: time.Minute}
+	// RateLimitScore absorbs the bursts the client's offline queue replays when it reconnects
+	RateLimitScore = RateLimitPolicy{Name: "score", Limit: 120, Window: time.Minute}
+)
+
+// ParseRateLimitPolicy returns policy with the limit and window from value, written
+// as <limit>/<window>. An empty value leaves the policy unchanged.
+func ParseRateLimitPolicy(policy RateLimitPolicy, value string) (RateLimitPolicy, error) {
+	if value == "" {
+		return policy, nil
+	}
+
+	limit, window, ok := strings.Cut(value, "/")
+	n, err := strconv.Atoi(limit)
+	d, werr := time.ParseDuration(window)
+	if !ok || err != nil || werr != nil || n <= 0 || d <= 0 {
+		return policy, fmt.Errorf("invalid rate limit %q, want <limit>/<window> such as 20/1m", value)
+	}
+
+	policy.Limit = n
+	policy.Window = d
+	return policy, nil
+}
+
+// refillPerSecond is how many tokens the bucket regains each second
+func (p RateLimitPolicy) refillPerSecond() float64 {
+	return float64(p.Limit) / p.Window.Seconds()
+}
+
+// rateLimitResult is the state of a bucket after taking a token from it
+type rateLimitResult struct {
+	allowed bool
+	// remaining is the number of tokens left in the bucket
+	remaining float64
+}
+
+// bucketStore takes tokens from buckets
+type bucketStore interface {
+	Take(ctx context.Context, key string, policy RateLimitPolicy) (rateLimitResult, error)
+}
+
+// RateLimiter enforces token-bucket rate limits per player, or per IP before login.
+//
+// Buckets live in Redis so every replica shares them. When Redis is not
+// configured or fails, the limiter degrades to per-process buckets so the API
+// keeps working with looser limits instead of failing closed.
+type RateLimiter struct {
+	primary  bucketStore
+	fallback bucketStore
+
+	mu          sync.Mutex
+	lastWarning time.Time
+}
+
+// NewRateLimiter creates a rate limiter; client may be nil
+func NewRateLimiter(client *redis.Client) *RateLimiter {
+	l := &RateLimiter{
+		fallback: newMemoryBucketStore(),
+	}
+	if client != nil {
+		l.primary = &redisBucketStore{client: client}
+	}
+	return l
+}
+
+// rateLimitTimeout bounds the Redis round trip made on every limited request
+const rateLimitTimeout = 100 * time.Millisecond
+
+// Limit creates a middleware applying policy to every request. Authenticated
+// requests are counted per player, so it must run after AuthMiddleware on
+// protected routes; anything else is counted per client IP.
+func (l *RateLimiter) Limit(policy RateLimitPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !l.allow(c, policy) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// LimitRoutes creates a middleware choosing a policy per route: the entry in
+// routes for the matched route if there is one (keyed "METHOD /full/path"),
+// otherwise reads for GET and HEAD requests and writes for everything else
+func (l *RateLimiter) LimitRoutes(reads, writes RateLimitPolicy, routes map[string]RateLimitPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy, ok := routes[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			policy = writes
+			if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+				policy = reads
+			}
+		}
+		if !l.allow(c, policy) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// allow takes a token for the request and sets the RateLimit headers, answering
+// 429 if the bucket is empty
+func (l *RateLimiter) allow(c *gin.Context, policy RateLimitPolicy) bool {
+	key := "ratelimit:" + policy.Name + ":" + rateLimitSubject(c)
+	result := l.take(c.Request.Context(), key, policy)
+
+	// With several policies on one route, the headers describe the tightest one
+	remaining := int(math.Floor(result.remaining))
+	if tightest, exists := c.Get("ratelimit_remaining"); !exists || remaining <= tightest.(int) {
+		c.Set("ratelimit_remaining", remaining)
+		refill := policy.refillPerSecond()
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, int(policy.Window.Seconds())))
+		c.Header("RateLimit-Limit", strconv.Itoa(policy.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(int(math.Ceil((float64(policy.Limit)-result.remaining)/refill))))
+	}
+
+	if result.allowed {
+		return true
+	}
+
+	retryAfter := int(math.Ceil((1 - result.remaining) / policy.refillPerSecond()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Too many requests, please slow down",
+		"retry_after": retryAfter,
+	})
+	return false
+}
+
+func (l *RateLimiter) take(ctx context.Context, key string, policy RateLimitPolicy) rateLimitResult {
+	if l.primary != nil {
+		ctx, cancel := context.WithTimeout(ctx, rateLimitTimeout)
+		defer cancel()
+		result, err := l.primary.Take(ctx, key, policy)
+		if err == nil {
+			return result
+		}
+		l.warnDegraded(err)
+	}
+	result, _ := l.fallback.Take(ctx, key, policy)
+	return result
+}
+
+// warnDegraded logs Redis failures at most once a minute, since every request would hit them
+func (l *RateLimiter) warnDegraded(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if time.Since(l.lastWarning) < time.Minute {
+		return
+	}
+	l.lastWarning = time.Now()
+	slog.Warn("Rate limiter degraded, Redis unavailable", "error", err)
+}
+
+// rateLimitSubject identifies who a request counts against. Anonymous requests
+// are keyed by ClientIP, which only follows X-Forwarded-For from the proxies
+// configured with SetTrustedProxies.
+func rateLimitSubject(c *gin.Context) string {
+	if playerID, exists := c.Get("player_id"); exists {
+		return "player:" + strconv.FormatUint(uint64(playerID.(uint)), 10)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// tokenBucketScript refills and takes from a bucket atomically, using the Redis
+// clock so every replica agrees on time. Tokens are returned as a string because
+// Redis truncates Lua numbers to integers.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil then
+  tokens = capacity
+  ts = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) / rate) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// redisBucketStore keeps buckets in Redis
+type redisBucketStore struct {
+	client *redis.Client
+}
+
+func (s *redisBucketStore) Take(ctx context.Context, key string, policy RateLimitPolicy) (rateLimitResult, error) {
+	perMillisecond := policy.refillPerSecond() / 1000
+	values, err := tokenBucketScript.Run(ctx, s.client, []string{key}, policy.Limit, perMillisecond).Slice()
+	if err != nil {
+		return rateLimitResult{}, err
+	}
+	if len(values) != 2 {
+		return rateLimitResult{}, fmt.Errorf("unexpected token bucket reply %v", values)
+	}
+
+	allowed, _ := values[0].(int64)
+	tokens, _ := values[1].(string)
+	remaining, err := strconv.ParseFloat(tokens, 64)
+	if err != nil {
+		return rateLimitResult{}, fmt.Errorf("unexpected token count %q: %w", tokens, err)
+	}
+	return rateLimitResult{allowed: allowed == 1, remaining: remaining}, nil
+}
+
+// memoryBucketStore keeps buckets in process memory
+type memoryBucketStore struct {
+	mu        sync.Mutex
+	buckets   map[string]memoryBucket
+	lastSweep time.Time
+}
+
+type memoryBucket struct {
+	tokens  float64
+	updated time.Time
+	// full is when the bucket will have refilled, after which it can be forgotten
+	full time.Time
+}
+
+func newMemoryBucketStore() *memoryBucketStore {
+	return &memoryBucketStore{
+		buckets: make(map[string]memoryBucket),
+	}
+}
+
+func (s *memoryBucketStore) Take(ctx context.Context, key string, policy RateLimitPolicy) (rateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	capacity := float64(policy.Limit)
+	rate := policy.refillPerSecond()
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = memoryBucket{tokens: capacity, updated: now}
+	}
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.updated).Seconds()*rate)
+	bucket.updated = now
+
+	allowed := bucket.tokens >= 1
+	if allowed {
+		bucket.tokens--
+	}
+	bucket.full = now.Add(time.Duration((capacity - bucket.tokens) / rate * float64(time.Second)))
+	s.buckets[key] = bucket
+
+	return rateLimitResult{allowed: allowed, remaining: bucket.tokens}, nil
+}
+
+// sweep drops full buckets so the map cannot grow without bound
+func (s *memoryBucketStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, bucket := range s.buckets {
+		if now.After(bucket.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_TokenBucket(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := NewRateLimiter(nil)
+	r := gin.New()
+	r.Use(limiter.LimitRoutes(
+		RateLimitPolicy{Name: "read", Limit: 3, Window: time.Minute},
+		RateLimitPolicy{Name: "write", Limit: 1, Window: time.Minute},
+		map[string]RateLimitPolicy{
+			"POST /score": {Name: "score", Limit: 2, Window: time.Minute},
+		},
+	))
+	r.GET("/items", func(c *gin.Context) { c.Status(200) })
+	r.POST("/items", func(c *gin.Context) { c.Status(200) })
+	r.POST("/score", func(c *gin.Context) { c.Status(200) })
+
+	send := func(method, path, ip string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 2; i >= 0; i-- {
+		w := send("GET", "/items", "10.0.0.1")
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, "3", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "3;w=60", w.Header().Get("RateLimit-Policy"))
+		assert.Equal(t, string(rune('0'+i)), w.Header().Get("RateLimit-Remaining"))
+	}
+
+	w := send("GET", "/items", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "20", w.Header().Get("Retry-After"))
+	assert.Equal(t, "60", w.Header().Get("RateLimit-Reset"))
+
+	// Writes and the score route have buckets of their own, and so does every IP
+	assert.Equal(t, 200, send("POST", "/items", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, send("POST", "/items", "10.0.0.1").Code)
+	assert.Equal(t, 200, send("POST", "/score", "10.0.0.1").Code)
+	assert.Equal(t, 200, send("POST", "/score", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, send("POST", "/score", "10.0.0.1").Code)
+	assert.Equal(t, 200, send("GET", "/items", "10.0.0.2").Code)
+}
+
+func TestRateLimiter_PerPlayerAndDegradedRedis(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Nothing listens on this port, so every Redis call fails and the limiter falls back to memory
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer client.Close()
+	limiter := NewRateLimiter(client)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if id := c.GetHeader("X-Player"); id == "1" {
+			c.Set("player_id", uint(1))
+		} else if id == "2" {
+			c.Set("player_id", uint(2))
+		}
+	})
+	r.Use(limiter.Limit(RateLimitPolicy{Name: "write", Limit: 1, Window: time.Hour}))
+	r.POST("/test", func(c *gin.Context) { c.Status(200) })
+
+	send := func(player string) int {
+		req, _ := http.NewRequest("POST", "/test", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Player", player)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Players sharing an IP do not use up each other's allowance
+	assert.Equal(t, 200, send("1"))
+	assert.Equal(t, http.StatusTooManyRequests, send("1"))
+	assert.Equal(t, 200, send("2"))
+}
+
+func TestParseRateLimitPolicy(t *testing.T) {
+	policy, err := ParseRateLimitPolicy(RateLimitAuth, "5/30s")
+	require.NoError(t, err)
+	assert.Equal(t, "auth", policy.Name)
+	assert.Equal(t, 5, policy.Limit)
+	assert.Equal(t, 30*time.Second, policy.Window)
+
+	policy, err = ParseRateLimitPolicy(RateLimitRead, "")
+	require.NoError(t, err)
+	assert.Equal(t, RateLimitRead, policy)
+
+	for _, value := range []string{"lots", "0/1m", "10/never", "10/-1m"} {
+		_, err = ParseRateLimitPolicy(RateLimitRead, value)
+		assert.Error(t, err, value)
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"zombie-car-game-backend/internal/logging"
+)
+
+// RequestIDHeader carries the ID that ties a request to its logs and audit entries
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds IDs accepted from callers so they fit the audit log column
+const maxRequestIDLength = 64
+
+// RequestID creates a middleware that gives every request an ID. An ID sent by
+// the caller (for example a load balancer) is kept if it looks sane, otherwise a
+// new one is generated. The ID is echoed in the response, stored in the context
+// under "request_id" and attached to everything logged with the request context.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Set("request_id", requestID)
+		c.Request = c.Request.WithContext(logging.WithAttrs(c.Request.Context(), slog.String("request_id", requestID)))
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// validRequestID accepts short IDs made of printable ASCII without spaces
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(RequestID())
+	r.GET("/test", func(c *gin.Context) {
+		c.String(200, c.GetString("request_id"))
+	})
+
+	// A sane ID from the caller is kept
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.Header.Set(RequestIDHeader, "lb-1234")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, "lb-1234", w.Body.String())
+	assert.Equal(t, "lb-1234", w.Header().Get(RequestIDHeader))
+
+	// Anything else is replaced with a generated one
+	req, _ = http.NewRequest("GET", "/test", nil)
+	req.Header.Set(RequestIDHeader, "has spaces\tand tabs")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Len(t, w.Body.String(), 36)
+	assert.Equal(t, w.Body.String(), w.Header().Get(RequestIDHeader))
+}
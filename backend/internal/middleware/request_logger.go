@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"zombie-car-game-backend/internal/logging"
+)
+
+// quietRoutes are polled by infrastructure, so their requests are only logged at debug level
+var quietRoutes = map[string]bool{
+	"/health":  true,
+	"/livez":   true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// RequestLogger creates a middleware writing one access log line per request.
+// It attaches the matched route to the request context, so it must run after
+// RequestID for the line to carry the request ID; the player ID is picked up
+// from the context when AuthMiddleware ran.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		route := c.FullPath()
+		if route != "" {
+			c.Request = c.Request.WithContext(logging.WithAttrs(c.Request.Context(), slog.String("route", route)))
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case quietRoutes[route]:
+			level = slog.LevelDebug
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+		slog.LogAttrs(c.Request.Context(), level, "Request handled", attrs...)
+	}
+}
+
+// Recovery creates a middleware turning panics into 500 responses, logging the
+// panic with the request's context instead of gin's plain-text output
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered any) {
+		slog.ErrorContext(c.Request.Context(), "Panic while handling request", "panic", fmt.Sprint(recovered))
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"zombie-car-game-backend/internal/logging"
+)
+
+func TestRequestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(logging.NewHandler(logging.Config{Level: slog.LevelInfo}, &buf)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	r := gin.New()
+	r.Use(RequestID(), RequestLogger(), Recovery())
+	r.GET("/players/:id", func(c *gin.Context) {
+		slog.InfoContext(c.Request.Context(), "Handler ran")
+		c.Status(http.StatusNoContent)
+	})
+	r.GET("/boom", func(c *gin.Context) {
+		panic("kaboom")
+	})
+
+	req, _ := http.NewRequest("GET", "/players/7", nil)
+	req.Header.Set(RequestIDHeader, "req-log")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	req, _ = http.NewRequest("GET", "/boom", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+	records := make([]map[string]interface{}, len(lines))
+	for i, line := range lines {
+		require.NoError(t, json.Unmarshal([]byte(line), &records[i]))
+	}
+
+	// Logs written by the handler and the access line share the request's fields
+	for _, record := range records[:2] {
+		assert.Equal(t, "req-log", record["request_id"])
+		assert.Equal(t, "/players/:id", record["route"])
+	}
+	assert.Equal(t, "Request handled", records[1]["message"])
+	assert.Equal(t, float64(http.StatusNoContent), records[1]["status"])
+	assert.Equal(t, "/players/7", records[1]["path"])
+
+	assert.Equal(t, "Panic while handling request", records[2]["message"])
+	assert.Equal(t, "kaboom", records[2]["panic"])
+	assert.Equal(t, "error", records[3]["level"])
+	assert.Equal(t, records[2]["request_id"], records[3]["request_id"])
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// AccountTokenPurpose identifies what an account token can be used for
+type AccountTokenPurpose string
+
+const (
+	AccountTokenEmailVerification AccountTokenPurpose = "email_verification"
+	AccountTokenPasswordReset     AccountTokenPurpose = "password_reset"
+	AccountTokenTwoFactorLogin    AccountTokenPurpose = "two_factor_login"
+)
+
+// AccountToken is a single-use token mailed to a player, or handed out as the
+// challenge between the two steps of a two-factor login. Only a hash of the token is stored.
+type AccountToken struct {
+	ID        uint                `json:"id" gorm:"primaryKey"`
+	PlayerID  uint                `json:"player_id" gorm:"not null;index"`
+	Purpose   AccountTokenPurpose `json:"purpose" gorm:"size:32;not null;index"`
+	TokenHash string              `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time           `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time          `json:"used_at,omitempty"`
+	CreatedAt time.Time           `json:"created_at"`
+
+	// Relationships
+	Player Player `json:"-" gorm:"foreignKey:PlayerID"`
+}
+
+// TableName specifies the table name for AccountToken model
+func (AccountToken) TableName() string {
+	return "account_tokens"
+}
+
+// IsExpired returns true if the token can no longer be redeemed
+func (at *AccountToken) IsExpired(now time.Time) bool {
+	return !now.Before(at.ExpiresAt)
+}
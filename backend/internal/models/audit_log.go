@@ -0,0 +1,70 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Audit actions
+const (
+	AuditActionLoginLockout      = "auth.lockout"
+	AuditActionDeletionRequested = "account.deletion_requested"
+	AuditActionDeletionCancelled = "account.deletion_cancelled"
+	AuditActionAccountAnonymized = "account.anonymized"
+	AuditActionTwoFactorEnabled  = "auth.2fa_enabled"
+	AuditActionTwoFactorDisabled = "auth.2fa_disabled"
+	AuditActionCurrencyAdjusted  = "admin.currency_adjusted"
+	AuditActionLevelChanged      = "admin.level_changed"
+	AuditActionProgressReset     = "admin.progress_reset"
+	AuditActionVehicleGranted    = "admin.vehicle_granted"
+	AuditActionVehicleRevoked    = "admin.vehicle_revoked"
+	AuditActionRoleChanged       = "admin.role_changed"
+	AuditActionSanctionIssued    = "admin.sanction_issued"
+	AuditActionSanctionLifted    = "admin.sanction_lifted"
+	AuditActionAppealRejected    = "admin.appeal_rejected"
+	AuditActionSanctionAppealed  = "account.sanction_appealed"
+	AuditActionPasswordReset     = "account.password_reset"
+	AuditActionCurrencyUpdated   = "player.currency_updated"
+	AuditActionVehiclePurchased  = "player.vehicle_purchased"
+	AuditActionVehicleUpgraded   = "player.vehicle_upgraded"
+	AuditActionAuditPruned       = "audit.pruned"
+)
+
+// ErrAuditLogImmutable is returned when something tries to change a recorded audit entry
+var ErrAuditLogImmutable = errors.New("audit log entries cannot be modified")
+
+// AuditLog records a security-relevant event. Entries are append-only and
+// chained: Hash covers the entry's contents and the Hash of the entry before it,
+// so editing or removing a row in the middle of the log is detectable.
+type AuditLog struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Action     string    `json:"action" gorm:"size:64;not null;index"`
+	ActorID    *uint     `json:"actor_id,omitempty" gorm:"index"`
+	TargetType string    `json:"target_type" gorm:"size:32"`
+	TargetID   string    `json:"target_id" gorm:"size:255;index"`
+	Before     string    `json:"before,omitempty" gorm:"type:text"`
+	After      string    `json:"after,omitempty" gorm:"type:text"`
+	Details    string    `json:"details,omitempty" gorm:"type:text"`
+	RequestID  string    `json:"request_id,omitempty" gorm:"size:64;index"`
+	IPAddress  string    `json:"ip_address,omitempty" gorm:"size:45"`
+	PrevHash   string    `json:"prev_hash" gorm:"size:64"`
+	Hash       string    `json:"hash" gorm:"size:64;not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName specifies the table name for AuditLog model
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// BeforeUpdate keeps recorded entries from being rewritten through GORM
+func (a *AuditLog) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
+
+// BeforeDelete keeps entries from being deleted through GORM; retention pruning skips hooks deliberately
+func (a *AuditLog) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
@@ -0,0 +1,12 @@
+package models
+
+import "gorm.io/gorm"
+
+// jsonDataType is the column type for values stored as JSON: jsonb on
+// Postgres and text elsewhere, such as SQLite
+func jsonDataType(db *gorm.DB) string {
+	if db.Dialector.Name() == "postgres" {
+		return "jsonb"
+	}
+	return "text"
+}
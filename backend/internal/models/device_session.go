@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// Device platforms recognised from the user agent
+const (
+	PlatformWeb      = "web"
+	PlatformElectron = "electron"
+	PlatformAndroid  = "android"
+	PlatformIOS      = "ios"
+	PlatformUnknown  = "unknown"
+)
+
+// DeviceSession is one login of a player on a device. Its ID is the family ID of
+// the refresh tokens issued for the login and the sid claim of its access tokens.
+type DeviceSession struct {
+	ID         string     `json:"id" gorm:"primaryKey;size:36"`
+	PlayerID   uint       `json:"-" gorm:"not null;index"`
+	UserAgent  string     `json:"user_agent" gorm:"size:255"`
+	IPAddress  string     `json:"ip_address" gorm:"size:45"`
+	Platform   string     `json:"platform" gorm:"size:20;not null"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt  *time.Time `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// Current marks the session making the request; it is not stored
+	Current bool `json:"current" gorm:"-"`
+
+	// Relationships
+	Player Player `json:"-" gorm:"foreignKey:PlayerID"`
+}
+
+// TableName specifies the table name for DeviceSession model
+func (DeviceSession) TableName() string {
+	return "device_sessions"
+}
@@ -18,7 +18,7 @@ const (
 
 // GameSession represents a single game session
 type GameSession struct {
-	ID               uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ID               uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
 	PlayerID         uint           `json:"player_id" gorm:"not null;index"`
 	LevelID          string         `json:"level_id" gorm:"size:50;not null"`
 	Score            int            `json:"score" gorm:"default:0"`
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InventoryItemType represents the category of an inventory item
+type InventoryItemType string
+
+const (
+	InventoryItemCosmetic InventoryItemType = "cosmetic"
+	InventoryItemGeneric  InventoryItemType = "item"
+)
+
+// InventoryItem represents a cosmetic or consumable item held by a player
+type InventoryItem struct {
+	ID         uint              `json:"id" gorm:"primaryKey"`
+	PlayerID   uint              `json:"player_id" gorm:"not null;uniqueIndex:idx_inventory_player_item"`
+	ItemType   InventoryItemType `json:"item_type" gorm:"size:20;not null;uniqueIndex:idx_inventory_player_item"`
+	ItemID     string            `json:"item_id" gorm:"size:50;not null;uniqueIndex:idx_inventory_player_item"`
+	Quantity   int               `json:"quantity" gorm:"not null"`
+	AcquiredAt time.Time         `json:"acquired_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt    `json:"-" gorm:"index"`
+
+	// Relationships
+	Player Player `json:"player,omitempty" gorm:"foreignKey:PlayerID"`
+}
+
+// TableName specifies the table name for InventoryItem model
+func (InventoryItem) TableName() string {
+	return "inventory_items"
+}
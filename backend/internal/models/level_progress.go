@@ -7,8 +7,8 @@ import (
 // LevelProgress represents a player's progress on a specific level
 type LevelProgress struct {
 	ID          uint           `json:"id" gorm:"primaryKey"`
-	PlayerID    uint           `json:"player_id" gorm:"not null;index"`
-	LevelID     string         `json:"level_id" gorm:"size:50;not null"`
+	PlayerID    uint           `json:"player_id" gorm:"not null;index;uniqueIndex:idx_level_progress_player_level"`
+	LevelID     string         `json:"level_id" gorm:"size:50;not null;uniqueIndex:idx_level_progress_player_level"`
 	BestScore   int            `json:"best_score" gorm:"default:0"`
 	Completed   bool           `json:"completed" gorm:"default:false"`
 	StarsEarned int            `json:"stars_earned" gorm:"default:0"`
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// OutboxStatus represents the delivery state of a queued email
+type OutboxStatus string
+
+const (
+	OutboxStatusPending OutboxStatus = "pending"
+	OutboxStatusSending OutboxStatus = "sending"
+	OutboxStatusSent    OutboxStatus = "sent"
+	OutboxStatusFailed  OutboxStatus = "failed"
+)
+
+// OutboxEmail is an email queued for delivery.
+// Rows are written in the same transaction as the change that triggers them
+// and delivered asynchronously by the outbox worker.
+type OutboxEmail struct {
+	ID            uint         `json:"id" gorm:"primaryKey"`
+	Recipient     string       `json:"recipient" gorm:"size:255;not null"`
+	Subject       string       `json:"subject" gorm:"size:255;not null"`
+	Body          string       `json:"body" gorm:"type:text;not null"`
+	Status        OutboxStatus `json:"status" gorm:"size:20;not null;default:'pending';index:idx_outbox_status_next"`
+	Attempts      int          `json:"attempts" gorm:"not null;default:0"`
+	LastError     string       `json:"last_error,omitempty" gorm:"size:500"`
+	NextAttemptAt time.Time    `json:"next_attempt_at" gorm:"not null;index:idx_outbox_status_next"`
+	SentAt        *time.Time   `json:"sent_at,omitempty"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+// TableName specifies the table name for OutboxEmail model
+func (OutboxEmail) TableName() string {
+	return "outbox_emails"
+}
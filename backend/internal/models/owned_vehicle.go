@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 // VehicleUpgrades represents the upgrades applied to a vehicle
@@ -28,12 +29,19 @@ func (vu *VehicleUpgrades) Scan(value interface{}) error {
 		return nil
 	}
 	
-	bytes, ok := value.([]byte)
-	if !ok {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, vu)
+	case string:
+		return json.Unmarshal([]byte(v), vu)
+	default:
 		return errors.New("type assertion to []byte failed")
 	}
-	
-	return json.Unmarshal(bytes, vu)
+}
+
+// GormDBDataType stores upgrades as jsonb on Postgres and text on other databases
+func (VehicleUpgrades) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return jsonDataType(db)
 }
 
 // OwnedVehicle represents a vehicle owned by a player
@@ -41,7 +49,7 @@ type OwnedVehicle struct {
 	ID           uint              `json:"id" gorm:"primaryKey"`
 	PlayerID     uint              `json:"player_id" gorm:"not null;index"`
 	VehicleType  string            `json:"vehicle_type" gorm:"size:50;not null"`
-	Upgrades     VehicleUpgrades   `json:"upgrades" gorm:"type:jsonb;default:'{}'"`
+	Upgrades     VehicleUpgrades   `json:"upgrades" gorm:"default:'{}'"`
 	PurchasedAt  time.Time         `json:"purchased_at"`
 	DeletedAt    gorm.DeletedAt    `json:"-" gorm:"index"`
 
@@ -1,27 +1,36 @@
 package models
 
 import (
-	"time"
 	"gorm.io/gorm"
+	"time"
 )
 
 // Player represents a game player
 type Player struct {
-	ID           uint           `json:"id" gorm:"primaryKey"`
-	Username     string         `json:"username" gorm:"uniqueIndex;size:50;not null"`
-	Email        string         `json:"email" gorm:"uniqueIndex;size:100;not null"`
-	PasswordHash string         `json:"-" gorm:"size:255;not null"`
-	Currency     int            `json:"currency" gorm:"default:0"`
-	Level        int            `json:"level" gorm:"default:1"`
-	TotalScore   int64          `json:"total_score" gorm:"default:0"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	Username         string         `json:"username" gorm:"uniqueIndex;size:50;not null"`
+	Email            string         `json:"email" gorm:"uniqueIndex;size:100;not null"`
+	PasswordHash     string         `json:"-" gorm:"size:255;not null"`
+	EmailVerifiedAt  *time.Time     `json:"email_verified_at,omitempty"`
+	Role             string         `json:"role" gorm:"size:20;not null;default:player;index"`
+	IsGuest          bool           `json:"is_guest" gorm:"default:false;index"`
+	DeviceIDHash     *string        `json:"-" gorm:"size:64;uniqueIndex"`
+	TwoFactorEnabled bool           `json:"two_factor_enabled" gorm:"default:false"`
+	LastActiveAt     *time.Time     `json:"last_active_at,omitempty" gorm:"index"`
+	DeletionDueAt    *time.Time     `json:"deletion_due_at,omitempty" gorm:"index"`
+	Currency         int            `json:"currency" gorm:"default:0"`
+	PremiumCurrency  int            `json:"premium_currency" gorm:"default:0"`
+	Level            int            `json:"level" gorm:"default:1"`
+	TotalScore       int64          `json:"total_score" gorm:"default:0"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relationships
-	OwnedVehicles []OwnedVehicle `json:"owned_vehicles,omitempty" gorm:"foreignKey:PlayerID"`
-	GameSessions  []GameSession  `json:"game_sessions,omitempty" gorm:"foreignKey:PlayerID"`
+	OwnedVehicles []OwnedVehicle  `json:"owned_vehicles,omitempty" gorm:"foreignKey:PlayerID"`
+	GameSessions  []GameSession   `json:"game_sessions,omitempty" gorm:"foreignKey:PlayerID"`
 	LevelProgress []LevelProgress `json:"level_progress,omitempty" gorm:"foreignKey:PlayerID"`
+	Inventory     []InventoryItem `json:"inventory,omitempty" gorm:"foreignKey:PlayerID"`
 }
 
 // TableName specifies the table name for Player model
@@ -34,5 +43,8 @@ func (p *Player) BeforeCreate(tx *gorm.DB) error {
 	if p.Currency == 0 {
 		p.Currency = 1000 // Starting currency
 	}
+	if p.Role == "" {
+		p.Role = "player"
+	}
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// PromoRewardType identifies what a promo code grants
+type PromoRewardType string
+
+const (
+	PromoRewardCurrency PromoRewardType = "currency"
+	PromoRewardVehicle  PromoRewardType = "vehicle"
+	PromoRewardCosmetic PromoRewardType = "cosmetic"
+	PromoRewardItem     PromoRewardType = "item"
+)
+
+// PromoReward represents a single grant attached to a promo code
+type PromoReward struct {
+	Type     PromoRewardType `json:"type" binding:"required,oneof=currency vehicle cosmetic item"`
+	ItemID   string          `json:"item_id,omitempty" binding:"max=50"`
+	Quantity int             `json:"quantity" binding:"min=0"`
+}
+
+// PromoRewards is the list of rewards stored as JSON on a promo code
+type PromoRewards []PromoReward
+
+// Value implements the driver.Valuer interface for database storage
+func (pr PromoRewards) Value() (driver.Value, error) {
+	if pr == nil {
+		return "[]", nil
+	}
+	bytes, err := json.Marshal(pr)
+	if err != nil {
+		return nil, err
+	}
+	return string(bytes), nil
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (pr *PromoRewards) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, pr)
+	case string:
+		return json.Unmarshal([]byte(v), pr)
+	default:
+		return errors.New("type assertion to []byte failed")
+	}
+}
+
+// GormDBDataType stores rewards as jsonb on Postgres and text on other databases
+func (PromoRewards) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return jsonDataType(db)
+}
+
+// PromoCode represents a redeemable promo or gift code created by an admin
+type PromoCode struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	Code            string         `json:"code" gorm:"uniqueIndex;size:50;not null"`
+	Description     string         `json:"description" gorm:"size:255"`
+	Rewards         PromoRewards   `json:"rewards" gorm:"not null"`
+	MaxRedemptions  int            `json:"max_redemptions" gorm:"not null"`  // 0 means unlimited
+	PerPlayerLimit  int            `json:"per_player_limit" gorm:"not null"` // 0 means unlimited
+	RedemptionCount int            `json:"redemption_count" gorm:"not null"`
+	Active          bool           `json:"active" gorm:"not null"`
+	ExpiresAt       *time.Time     `json:"expires_at,omitempty"`
+	CreatedBy       uint           `json:"created_by"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName specifies the table name for PromoCode model
+func (PromoCode) TableName() string {
+	return "promo_codes"
+}
+
+// IsExpired returns true if the code has passed its expiry time
+func (pc *PromoCode) IsExpired(now time.Time) bool {
+	return pc.ExpiresAt != nil && !now.Before(*pc.ExpiresAt)
+}
+
+// IsExhausted returns true if the code has no redemptions left
+func (pc *PromoCode) IsExhausted() bool {
+	return pc.MaxRedemptions > 0 && pc.RedemptionCount >= pc.MaxRedemptions
+}
+
+// PromoRedemption is the audit record of a single promo code redemption
+type PromoRedemption struct {
+	ID          uint         `json:"id" gorm:"primaryKey"`
+	PromoCodeID uint         `json:"promo_code_id" gorm:"not null;index:idx_promo_redemptions_code_player"`
+	PlayerID    uint         `json:"player_id" gorm:"not null;index:idx_promo_redemptions_code_player;index"`
+	Code        string       `json:"code" gorm:"size:50;not null"`
+	Rewards     PromoRewards `json:"rewards" gorm:"not null"`
+	IPAddress   string       `json:"ip_address" gorm:"size:45"`
+	RedeemedAt  time.Time    `json:"redeemed_at"`
+
+	// Relationships
+	PromoCode PromoCode `json:"-" gorm:"foreignKey:PromoCodeID"`
+	Player    Player    `json:"-" gorm:"foreignKey:PlayerID"`
+}
+
+// TableName specifies the table name for PromoRedemption model
+func (PromoRedemption) TableName() string {
+	return "promo_redemptions"
+}
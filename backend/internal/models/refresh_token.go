@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// RefreshToken is a server-side record of an opaque refresh token.
+// Only a hash of the token is stored. Tokens issued from the same login share
+// a FamilyID so that reuse of a rotated token can revoke the whole chain.
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	PlayerID  uint       `json:"player_id" gorm:"not null;index"`
+	FamilyID  string     `json:"family_id" gorm:"size:36;not null;index"`
+	TokenHash string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	// Relationships
+	Player Player `json:"-" gorm:"foreignKey:PlayerID"`
+}
+
+// TableName specifies the table name for RefreshToken model
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// IsExpired returns true if the token can no longer be exchanged
+func (rt *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(rt.ExpiresAt)
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"time"
+)
+
+// SanctionType is the kind of restriction placed on a player
+type SanctionType string
+
+const (
+	// SanctionBan bars the player from logging in or using the API, permanently unless it has an expiry
+	SanctionBan SanctionType = "ban"
+	// SanctionSuspension bars the player from logging in or using the API until it expires
+	SanctionSuspension SanctionType = "suspension"
+	// SanctionLeaderboardExclusion hides the player from leaderboards but leaves the game playable
+	SanctionLeaderboardExclusion SanctionType = "leaderboard_exclusion"
+	// SanctionMute stops the player from posting anything other players can see
+	SanctionMute SanctionType = "mute"
+)
+
+// AppealStatus tracks a player's appeal against a sanction
+type AppealStatus string
+
+const (
+	AppealStatusPending  AppealStatus = "pending"
+	AppealStatusAccepted AppealStatus = "accepted"
+	AppealStatusRejected AppealStatus = "rejected"
+)
+
+// Sanction is a restriction issued against a player by staff. Sanctions are
+// never deleted; lifting one records who lifted it and why, so the full
+// history stays available to admins.
+type Sanction struct {
+	ID         uint         `json:"id" gorm:"primaryKey"`
+	PlayerID   uint         `json:"player_id" gorm:"not null;index"`
+	Type       SanctionType `json:"type" gorm:"size:32;not null;index"`
+	Reason     string       `json:"reason" gorm:"size:500;not null"`
+	IssuedBy   *uint        `json:"issued_by,omitempty"`
+	ExpiresAt  *time.Time   `json:"expires_at,omitempty" gorm:"index"`
+	LiftedAt   *time.Time   `json:"lifted_at,omitempty"`
+	LiftedBy   *uint        `json:"lifted_by,omitempty"`
+	LiftReason string       `json:"lift_reason,omitempty" gorm:"size:500"`
+
+	AppealMessage    string        `json:"appeal_message,omitempty" gorm:"type:text"`
+	AppealStatus     *AppealStatus `json:"appeal_status,omitempty" gorm:"size:20;index"`
+	AppealedAt       *time.Time    `json:"appealed_at,omitempty"`
+	AppealResolvedAt *time.Time    `json:"appeal_resolved_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	Player Player `json:"-" gorm:"foreignKey:PlayerID"`
+}
+
+// TableName specifies the table name for Sanction model
+func (Sanction) TableName() string {
+	return "sanctions"
+}
+
+// IsActive returns true if the sanction has not been lifted and has not expired
+func (s *Sanction) IsActive(now time.Time) bool {
+	return s.LiftedAt == nil && (s.ExpiresAt == nil || now.Before(*s.ExpiresAt))
+}
+
+// BlocksAccess returns true for sanction types that keep the player out of the API
+func (s *Sanction) BlocksAccess() bool {
+	return s.Type == SanctionBan || s.Type == SanctionSuspension
+}
+
+// HidesFromLeaderboards returns true for sanction types that remove the player from leaderboards
+func (s *Sanction) HidesFromLeaderboards() bool {
+	return s.BlocksAccess() || s.Type == SanctionLeaderboardExclusion
+}
+
+// SanctionNotice is the error payload shown to a player who is barred by a sanction
+type SanctionNotice struct {
+	Error      string       `json:"error"`
+	Code       string       `json:"code"`
+	SanctionID uint         `json:"sanction_id"`
+	Type       SanctionType `json:"type"`
+	Reason     string       `json:"reason"`
+	ExpiresAt  *time.Time   `json:"expires_at,omitempty"`
+	Appealable bool         `json:"appealable"`
+}
+
+// Notice describes the sanction to the player it was issued against
+func (s *Sanction) Notice() SanctionNotice {
+	notice := SanctionNotice{
+		Error:      "Account is banned",
+		Code:       "account_banned",
+		SanctionID: s.ID,
+		Type:       s.Type,
+		Reason:     s.Reason,
+		ExpiresAt:  s.ExpiresAt,
+		Appealable: s.AppealStatus == nil,
+	}
+	if s.Type == SanctionSuspension {
+		notice.Error = "Account is suspended"
+		notice.Code = "account_suspended"
+	}
+	return notice
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SyncedSession records that an offline server has uploaded a locally played
+// session to the online server
+type SyncedSession struct {
+	SessionID uuid.UUID `json:"session_id" gorm:"type:uuid;primaryKey"`
+	SyncedAt  time.Time `json:"synced_at" gorm:"not null"`
+}
+
+// TableName specifies the table name for SyncedSession model
+func (SyncedSession) TableName() string {
+	return "synced_sessions"
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+)
+
+// TwoFactorCredential is a player's TOTP secret. It starts unconfirmed and only
+// protects logins once the player has proven their authenticator produces codes.
+type TwoFactorCredential struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	PlayerID     uint       `json:"player_id" gorm:"not null;uniqueIndex"`
+	Secret       string     `json:"-" gorm:"size:64;not null"`
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty"`
+	LastUsedStep int64      `json:"-" gorm:"not null;default:0"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	// Relationships
+	Player Player `json:"-" gorm:"foreignKey:PlayerID"`
+}
+
+// TableName specifies the table name for TwoFactorCredential model
+func (TwoFactorCredential) TableName() string {
+	return "two_factor_credentials"
+}
+
+// RecoveryCode is a single-use backup code for a player who lost their authenticator.
+// Only a hash of the code is stored.
+type RecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	PlayerID  uint       `json:"player_id" gorm:"not null;index"`
+	CodeHash  string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	// Relationships
+	Player Player `json:"-" gorm:"foreignKey:PlayerID"`
+}
+
+// TableName specifies the table name for RecoveryCode model
+func (RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+)
+
+// CurrencyType identifies one of the player's wallets
+type CurrencyType string
+
+const (
+	CurrencySoft    CurrencyType = "soft"
+	CurrencyPremium CurrencyType = "premium"
+)
+
+// PurchaseStatus represents the lifecycle of a store purchase
+type PurchaseStatus string
+
+const (
+	PurchaseStatusCredited PurchaseStatus = "credited"
+	PurchaseStatusRefunded PurchaseStatus = "refunded"
+	// PurchaseStatusPartiallyRefunded keeps the credit, awaiting review by support
+	PurchaseStatusPartiallyRefunded PurchaseStatus = "partially_refunded"
+)
+
+// WalletTransaction is an append-only ledger entry for a wallet balance change
+type WalletTransaction struct {
+	ID           uint         `json:"id" gorm:"primaryKey"`
+	PlayerID     uint         `json:"player_id" gorm:"not null;index"`
+	Currency     CurrencyType `json:"currency" gorm:"size:20;not null"`
+	Amount       int          `json:"amount" gorm:"not null"`
+	BalanceAfter int          `json:"balance_after" gorm:"not null"`
+	Reason       string       `json:"reason" gorm:"size:50;not null"`
+	Reference    string       `json:"reference,omitempty" gorm:"size:150;index"`
+	CreatedAt    time.Time    `json:"created_at"`
+
+	// Relationships
+	Player Player `json:"-" gorm:"foreignKey:PlayerID"`
+}
+
+// TableName specifies the table name for WalletTransaction model
+func (WalletTransaction) TableName() string {
+	return "wallet_transactions"
+}
+
+// Purchase represents a verified store receipt that credited premium currency.
+// The store and its transaction ID are unique so a receipt is credited only once.
+type Purchase struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	PlayerID      uint           `json:"player_id" gorm:"not null;index"`
+	Store         string         `json:"store" gorm:"size:20;not null;uniqueIndex:idx_purchases_store_transaction"`
+	TransactionID string         `json:"transaction_id" gorm:"size:128;not null;uniqueIndex:idx_purchases_store_transaction"`
+	ProductID     string         `json:"product_id" gorm:"size:50;not null"`
+	Quantity      int            `json:"quantity" gorm:"not null"`
+	PremiumAmount int            `json:"premium_amount" gorm:"not null"`
+	Status        PurchaseStatus `json:"status" gorm:"size:20;not null"`
+	PurchasedAt   time.Time      `json:"purchased_at"`
+	RefundedAt    *time.Time     `json:"refunded_at,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+
+	// Relationships
+	Player Player `json:"-" gorm:"foreignKey:PlayerID"`
+}
+
+// TableName specifies the table name for Purchase model
+func (Purchase) TableName() string {
+	return "purchases"
+}
+
+// IsRefunded returns true if the purchase credit has been reversed
+func (p *Purchase) IsRefunded() bool {
+	return p.Status == PurchaseStatusRefunded
+}
+
+// StoreAccount links a player to their account with a store, such as their
+// SteamID. Players link an account by proving they own it, and receipts from
+// that store must then have been bought by the linked account.
+type StoreAccount struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	PlayerID  uint      `json:"player_id" gorm:"not null;uniqueIndex:idx_store_accounts_player_store"`
+	Store     string    `json:"store" gorm:"size:20;not null;uniqueIndex:idx_store_accounts_player_store;uniqueIndex:idx_store_accounts_store_account"`
+	AccountID string    `json:"account_id" gorm:"size:64;not null;uniqueIndex:idx_store_accounts_store_account"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relationships
+	Player Player `json:"-" gorm:"foreignKey:PlayerID"`
+}
+
+// TableName specifies the table name for StoreAccount model
+func (StoreAccount) TableName() string {
+	return "store_accounts"
+}
@@ -0,0 +1,50 @@
+package offline
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Frontend serves the built frontend in dir for requests no route matched.
+// Paths that are not files get index.html, so the app's client-side routes
+// survive a reload; unknown API paths still get a JSON 404.
+func Frontend(dir string) gin.HandlerFunc {
+	root := http.Dir(dir)
+	files := http.FileServer(root)
+
+	return func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, "/api/") ||
+			(c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+
+		// http.Dir rejects paths escaping dir
+		if f, err := root.Open(path.Clean(c.Request.URL.Path)); err == nil {
+			info, statErr := f.Stat()
+			f.Close()
+			if statErr == nil && !info.IsDir() {
+				files.ServeHTTP(c.Writer, c.Request)
+				return
+			}
+		}
+
+		// index.html names the current asset bundles, so it must not be cached
+		c.Header("Cache-Control", "no-cache")
+		index, err := root.Open("/index.html")
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Frontend not found"})
+			return
+		}
+		defer index.Close()
+		info, err := index.Stat()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read frontend"})
+			return
+		}
+		http.ServeContent(c.Writer, c.Request, "index.html", info.ModTime(), index)
+	}
+}
@@ -0,0 +1,59 @@
+package offline
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrontend(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html>app</html>"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "static"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "static", "main.js"), []byte("console.log(1)"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(dir), "secret.txt"), []byte("secret"), 0o644))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/livez", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	router.NoRoute(Frontend(dir))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		body   string
+	}{
+		{"asset", http.MethodGet, "/static/main.js", http.StatusOK, "console.log(1)"},
+		{"client-side route", http.MethodGet, "/garage/upgrades", http.StatusOK, "<html>app</html>"},
+		{"directory", http.MethodGet, "/static/", http.StatusOK, "<html>app</html>"},
+		{"escaping the directory", http.MethodGet, "/../secret.txt", http.StatusOK, "<html>app</html>"},
+		{"unknown API path", http.MethodGet, "/api/v1/unknown", http.StatusNotFound, `{"error":"Not found"}`},
+		{"other method", http.MethodPost, "/garage", http.StatusNotFound, `{"error":"Not found"}`},
+		{"registered route", http.MethodGet, "/livez", http.StatusOK, "ok"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.body, w.Body.String())
+		})
+	}
+
+	t.Run("index is not cached", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	})
+}
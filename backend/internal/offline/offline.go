@@ -0,0 +1,71 @@
+// Package offline supports running the server embedded in the desktop build:
+// bound to this machine only, with its data under the user's app-data directory
+// and the built frontend served alongside the API.
+package offline
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AppName is the directory the desktop app keeps its data in, matching the
+// Electron product name so both use the same app-data directory
+const AppName = "Zombie Car Game"
+
+// secretFile holds the signing secret of the local server's tokens
+const secretFile = "jwt_secret"
+
+// DefaultDataDir returns the app's directory under the user's configuration
+// directory, such as %AppData% on Windows or ~/Library/Application Support on macOS
+func DefaultDataDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the app-data directory: %w", err)
+	}
+	return filepath.Join(dir, AppName), nil
+}
+
+// LoadSecret returns the signing secret stored in dataDir, creating it on first
+// use. Every install gets its own secret, so tokens from one install are
+// worthless anywhere else.
+func LoadSecret(dataDir string) (string, error) {
+	path := filepath.Join(dataDir, secretFile)
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if secret := strings.TrimSpace(string(data)); secret != "" {
+			return secret, nil
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("failed to read the local signing secret: %w", err)
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate the local signing secret: %w", err)
+	}
+	secret := hex.EncodeToString(raw)
+
+	if err := os.MkdirAll(dataDir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create the app-data directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(secret+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("failed to store the local signing secret: %w", err)
+	}
+	return secret, nil
+}
+
+// IsLoopback reports whether host only accepts connections from this machine
+func IsLoopback(host string) bool {
+	switch host {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package offline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSecret(t *testing.T) {
+	dataDir := filepath.Join(t.TempDir(), AppName)
+
+	secret, err := LoadSecret(dataDir)
+	require.NoError(t, err)
+	assert.Len(t, secret, 64)
+
+	info, err := os.Stat(filepath.Join(dataDir, secretFile))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	again, err := LoadSecret(dataDir)
+	require.NoError(t, err)
+	assert.Equal(t, secret, again, "the stored secret is reused")
+
+	other, err := LoadSecret(t.TempDir())
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, other, "every install gets its own secret")
+}
+
+func TestIsLoopback(t *testing.T) {
+	for _, host := range []string{"localhost", "127.0.0.1", "::1"} {
+		assert.True(t, IsLoopback(host), host)
+	}
+	for _, host := range []string{"", "0.0.0.0", "::", "192.168.1.10", "example.com"} {
+		assert.False(t, IsLoopback(host), host)
+	}
+}
@@ -0,0 +1,114 @@
+package payments
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// FakeStore is the store identifier used by the fake verifier
+const FakeStore = "fake"
+
+// FakeVerifier is an in-memory verifier for tests and local development.
+// Receipts must be registered with AddReceipt before they verify, and account
+// proofs with AddAccount.
+type FakeVerifier struct {
+	mu       sync.RWMutex
+	receipts map[string]VerifiedReceipt
+	buyers   map[string]string
+	accounts map[string]string
+}
+
+// NewFakeVerifier creates an empty fake verifier
+func NewFakeVerifier() *FakeVerifier {
+	return &FakeVerifier{
+		receipts: make(map[string]VerifiedReceipt),
+		buyers:   make(map[string]string),
+		accounts: make(map[string]string),
+	}
+}
+
+// Store returns the fake store identifier
+func (f *FakeVerifier) Store() string {
+	return FakeStore
+}
+
+// AddReceipt registers a receipt that will verify as a purchase of the given product
+func (f *FakeVerifier) AddReceipt(receipt, transactionID, productID string, quantity int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.receipts[receipt] = VerifiedReceipt{
+		Store:         FakeStore,
+		TransactionID: transactionID,
+		ProductID:     productID,
+		Quantity:      quantity,
+		PurchasedAt:   time.Now(),
+	}
+}
+
+// SetBuyer makes a previously added receipt verify only for the given store account
+func (f *FakeVerifier) SetBuyer(receipt, account string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.buyers[receipt] = account
+}
+
+// AddAccount registers a proof that will verify as ownership of accountID
+func (f *FakeVerifier) AddAccount(proof, accountID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.accounts[proof] = accountID
+}
+
+// MarkPartiallyRefunded makes a previously added receipt report a partial refund
+func (f *FakeVerifier) MarkPartiallyRefunded(receipt string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r, ok := f.receipts[receipt]; ok {
+		r.PartiallyRefunded = true
+		f.receipts[receipt] = r
+	}
+}
+
+// MarkRefunded makes a previously added receipt report as refunded
+func (f *FakeVerifier) MarkRefunded(receipt string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r, ok := f.receipts[receipt]; ok {
+		r.Refunded = true
+		f.receipts[receipt] = r
+	}
+}
+
+// Verify returns the registered receipt or ErrInvalidReceipt. Receipts given a
+// buyer with SetBuyer verify only for that account; others for any account.
+func (f *FakeVerifier) Verify(ctx context.Context, receipt, account string) (*VerifiedReceipt, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	r, ok := f.receipts[receipt]
+	if !ok {
+		return nil, ErrInvalidReceipt
+	}
+	if buyer, ok := f.buyers[receipt]; ok && buyer != account {
+		return nil, ErrAccountMismatch
+	}
+	return &r, nil
+}
+
+// VerifyAccount returns the account registered for proof or ErrInvalidProof
+func (f *FakeVerifier) VerifyAccount(ctx context.Context, proof string) (string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	accountID, ok := f.accounts[proof]
+	if !ok {
+		return "", ErrInvalidProof
+	}
+	return accountID, nil
+}
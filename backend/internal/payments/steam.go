@@ -0,0 +1,203 @@
+package payments
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// SteamStore is the store identifier used by the Steam verifier
+const SteamStore = "steam"
+
+const (
+	steamAPIBaseURL          = "https://partner.steam-api.com"
+	steamQueryTxnPath        = "/ISteamMicroTxn/QueryTxn/v3/"
+	steamSandboxQueryTxnPath = "/ISteamMicroTxnSandbox/QueryTxn/v3/"
+	steamAuthTicketPath      = "/ISteamUserAuth/AuthenticateUserTicket/v1/"
+)
+
+// SteamVerifier verifies Steam microtransaction order IDs with the Steam Web API
+type SteamVerifier struct {
+	apiKey  string
+	appID   string
+	baseURL string
+	path    string
+	client  *http.Client
+}
+
+// NewSteamVerifier creates a verifier for the given publisher key and app ID
+func NewSteamVerifier(apiKey, appID string, sandbox bool) *SteamVerifier {
+	path := steamQueryTxnPath
+	if sandbox {
+		path = steamSandboxQueryTxnPath
+	}
+
+	return &SteamVerifier{
+		apiKey:  apiKey,
+		appID:   appID,
+		baseURL: steamAPIBaseURL,
+		path:    path,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Store returns the Steam store identifier
+func (s *SteamVerifier) Store() string {
+	return SteamStore
+}
+
+// steamQueryTxnResponse mirrors the QueryTxn response body
+type steamQueryTxnResponse struct {
+	Response struct {
+		Result string `json:"result"`
+		Params struct {
+			OrderID string `json:"orderid"`
+			TransID string `json:"transid"`
+			SteamID string `json:"steamid"`
+			Status  string `json:"status"`
+			Time    string `json:"time"`
+			Items   []struct {
+				ItemID json.Number `json:"itemid"`
+				Qty    int         `json:"qty"`
+			} `json:"items"`
+		} `json:"params"`
+		Error struct {
+			ErrorCode string `json:"errorcode"`
+			ErrorDesc string `json:"errordesc"`
+		} `json:"error"`
+	} `json:"response"`
+}
+
+// Verify queries Steam for the order ID submitted by the client. The order must
+// have been placed by account, the player's linked SteamID, so that one player
+// cannot claim an order ID belonging to someone else.
+func (s *SteamVerifier) Verify(ctx context.Context, receipt, account string) (*VerifiedReceipt, error) {
+	if _, err := strconv.ParseUint(receipt, 10, 64); err != nil {
+		return nil, ErrInvalidReceipt
+	}
+	if account == "" {
+		return nil, ErrAccountNotLinked
+	}
+
+	query := url.Values{}
+	query.Set("key", s.apiKey)
+	query.Set("appid", s.appID)
+	query.Set("orderid", receipt)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+s.path+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build steam request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStoreUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: steam returned status %d", ErrStoreUnavailable, resp.StatusCode)
+	}
+
+	var body steamQueryTxnResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%w: malformed steam response", ErrStoreUnavailable)
+	}
+
+	if body.Response.Result != "OK" || len(body.Response.Params.Items) == 0 {
+		return nil, ErrInvalidReceipt
+	}
+
+	params := body.Response.Params
+	if params.SteamID != account {
+		return nil, ErrAccountMismatch
+	}
+	// The game only places single-item orders; crediting one item of a larger
+	// order would silently drop the rest
+	if len(params.Items) > 1 {
+		return nil, ErrMultiItemOrder
+	}
+	verified := &VerifiedReceipt{
+		Store:         SteamStore,
+		TransactionID: params.OrderID,
+		ProductID:     params.Items[0].ItemID.String(),
+		Quantity:      params.Items[0].Qty,
+	}
+	if t, err := time.Parse(time.RFC3339, params.Time); err == nil {
+		verified.PurchasedAt = t
+	} else {
+		verified.PurchasedAt = time.Now()
+	}
+
+	switch params.Status {
+	case "Succeeded":
+	case "Refunded", "Chargedback", "RefundedSuspectedFraud", "RefundedFriendlyFraud":
+		verified.Refunded = true
+	case "PartialRefund":
+		verified.PartiallyRefunded = true
+	case "Init", "Approved":
+		return nil, ErrReceiptPending
+	default:
+		return nil, ErrInvalidReceipt
+	}
+
+	return verified, nil
+}
+
+// steamAuthTicketResponse mirrors the AuthenticateUserTicket response body
+type steamAuthTicketResponse struct {
+	Response struct {
+		Params struct {
+			Result  string `json:"result"`
+			SteamID string `json:"steamid"`
+		} `json:"params"`
+		Error struct {
+			ErrorCode int    `json:"errorcode"`
+			ErrorDesc string `json:"errordesc"`
+		} `json:"error"`
+	} `json:"response"`
+}
+
+// VerifyAccount checks a hex-encoded auth session ticket from the Steam client
+// and returns the SteamID it was issued to
+func (s *SteamVerifier) VerifyAccount(ctx context.Context, proof string) (string, error) {
+	if _, err := hex.DecodeString(proof); err != nil || proof == "" {
+		return "", ErrInvalidProof
+	}
+
+	query := url.Values{}
+	query.Set("key", s.apiKey)
+	query.Set("appid", s.appID)
+	query.Set("ticket", proof)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+steamAuthTicketPath+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build steam request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrStoreUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: steam returned status %d", ErrStoreUnavailable, resp.StatusCode)
+	}
+
+	var body steamAuthTicketResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("%w: malformed steam response", ErrStoreUnavailable)
+	}
+
+	params := body.Response.Params
+	if params.Result != "OK" || params.SteamID == "" {
+		return "", ErrInvalidProof
+	}
+	return params.SteamID, nil
+}
@@ -0,0 +1,129 @@
+package payments
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSteamID is the account that placed the test server's order
+const testSteamID = "76561198000000001"
+
+func newTestSteamVerifier(t *testing.T, status string) *SteamVerifier {
+	return newTestSteamVerifierWithItems(t, status, `[{"itemid":1002,"qty":1,"amount":"399"}]`)
+}
+
+func newTestSteamVerifierWithItems(t *testing.T, status, items string) *SteamVerifier {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-key", r.URL.Query().Get("key"))
+		assert.Equal(t, "480", r.URL.Query().Get("appid"))
+
+		if r.URL.Path == steamAuthTicketPath {
+			if r.URL.Query().Get("ticket") != "14000000" {
+				w.Write([]byte(`{"response":{"error":{"errorcode":101,"errordesc":"Invalid ticket"}}}`))
+				return
+			}
+			w.Write([]byte(`{"response":{"params":{"result":"OK","steamid":"` + testSteamID + `"}}}`))
+			return
+		}
+
+		assert.Equal(t, steamQueryTxnPath, r.URL.Path)
+		if r.URL.Query().Get("orderid") != "938473" {
+			w.Write([]byte(`{"response":{"result":"Failure","error":{"errorcode":"100","errordesc":"not found"}}}`))
+			return
+		}
+
+		w.Write([]byte(`{"response":{"result":"OK","params":{"orderid":"938473","transid":"374839",` +
+			`"steamid":"` + testSteamID + `","status":"` + status + `","time":"2026-01-01T00:00:00Z",` +
+			`"items":` + items + `}}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	verifier := NewSteamVerifier("test-key", "480", false)
+	verifier.baseURL = server.URL
+	return verifier
+}
+
+func TestSteamVerifier_Verify(t *testing.T) {
+	verifier := newTestSteamVerifier(t, "Succeeded")
+
+	receipt, err := verifier.Verify(context.Background(), "938473", testSteamID)
+	require.NoError(t, err)
+	assert.Equal(t, SteamStore, receipt.Store)
+	assert.Equal(t, "938473", receipt.TransactionID)
+	assert.Equal(t, "1002", receipt.ProductID)
+	assert.Equal(t, 1, receipt.Quantity)
+	assert.False(t, receipt.Refunded)
+
+	_, err = verifier.Verify(context.Background(), "111", testSteamID)
+	assert.ErrorIs(t, err, ErrInvalidReceipt)
+
+	_, err = verifier.Verify(context.Background(), "not-a-number", testSteamID)
+	assert.ErrorIs(t, err, ErrInvalidReceipt)
+}
+
+func TestSteamVerifier_Verify_Account(t *testing.T) {
+	verifier := newTestSteamVerifier(t, "Succeeded")
+
+	_, err := verifier.Verify(context.Background(), "938473", "")
+	assert.ErrorIs(t, err, ErrAccountNotLinked)
+
+	_, err = verifier.Verify(context.Background(), "938473", "76561198000000002")
+	assert.ErrorIs(t, err, ErrAccountMismatch, "another player's order cannot be claimed")
+}
+
+func TestSteamVerifier_Verify_MultipleItems(t *testing.T) {
+	verifier := newTestSteamVerifierWithItems(t, "Succeeded",
+		`[{"itemid":1001,"qty":1,"amount":"99"},{"itemid":1003,"qty":1,"amount":"999"}]`)
+
+	_, err := verifier.Verify(context.Background(), "938473", testSteamID)
+	assert.ErrorIs(t, err, ErrMultiItemOrder)
+}
+
+func TestSteamVerifier_Verify_Statuses(t *testing.T) {
+	receipt, err := newTestSteamVerifier(t, "Refunded").Verify(context.Background(), "938473", testSteamID)
+	require.NoError(t, err)
+	assert.True(t, receipt.Refunded)
+
+	receipt, err = newTestSteamVerifier(t, "PartialRefund").Verify(context.Background(), "938473", testSteamID)
+	require.NoError(t, err)
+	assert.True(t, receipt.PartiallyRefunded)
+	assert.False(t, receipt.Refunded, "a partial refund does not reverse the whole purchase")
+
+	_, err = newTestSteamVerifier(t, "Approved").Verify(context.Background(), "938473", testSteamID)
+	assert.ErrorIs(t, err, ErrReceiptPending)
+
+	_, err = newTestSteamVerifier(t, "Failed").Verify(context.Background(), "938473", testSteamID)
+	assert.ErrorIs(t, err, ErrInvalidReceipt)
+}
+
+func TestSteamVerifier_VerifyAccount(t *testing.T) {
+	verifier := newTestSteamVerifier(t, "Succeeded")
+
+	steamID, err := verifier.VerifyAccount(context.Background(), "14000000")
+	require.NoError(t, err)
+	assert.Equal(t, testSteamID, steamID)
+
+	_, err = verifier.VerifyAccount(context.Background(), "15000000")
+	assert.ErrorIs(t, err, ErrInvalidProof)
+
+	_, err = verifier.VerifyAccount(context.Background(), "not-hex")
+	assert.ErrorIs(t, err, ErrInvalidProof)
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry(NewFakeVerifier())
+
+	v, err := registry.Get(FakeStore)
+	require.NoError(t, err)
+	assert.Equal(t, FakeStore, v.Store())
+
+	_, err = registry.Get(SteamStore)
+	assert.ErrorIs(t, err, ErrUnknownStore)
+
+	assert.Equal(t, []string{FakeStore}, registry.Stores())
+}
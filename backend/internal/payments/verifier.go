@@ -0,0 +1,114 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+var (
+	ErrUnknownStore     = errors.New("unknown store")
+	ErrInvalidReceipt   = errors.New("invalid receipt")
+	ErrReceiptPending   = errors.New("receipt has not completed")
+	ErrStoreUnavailable = errors.New("store verification unavailable")
+	ErrAccountNotLinked = errors.New("no store account linked")
+	ErrInvalidProof     = errors.New("invalid proof of store account ownership")
+	ErrAccountMismatch  = errors.New("receipt belongs to another store account")
+	ErrMultiItemOrder   = errors.New("orders with more than one item are not supported")
+)
+
+// VerifiedReceipt is the store's authoritative view of a purchase
+type VerifiedReceipt struct {
+	Store         string
+	TransactionID string
+	ProductID     string
+	Quantity      int
+	Refunded      bool
+	// PartiallyRefunded means part of the payment was returned; the purchase is
+	// left for support to settle rather than reversed
+	PartiallyRefunded bool
+	PurchasedAt       time.Time
+}
+
+// ReceiptVerifier verifies a client-submitted receipt with a store backend
+type ReceiptVerifier interface {
+	// Store returns the identifier clients use to select this verifier
+	Store() string
+	// Verify checks the receipt with the store and returns the purchase it proves.
+	// account is the player's linked account with the store, or empty if none is
+	// linked; stores that know the buyer reject receipts bought by anyone else.
+	Verify(ctx context.Context, receipt, account string) (*VerifiedReceipt, error)
+}
+
+// AccountVerifier is implemented by stores whose purchases belong to a store
+// account, which players link by proving they own it
+type AccountVerifier interface {
+	ReceiptVerifier
+	// VerifyAccount checks a store-issued proof of account ownership, such as a
+	// Steam auth ticket, and returns the account's ID
+	VerifyAccount(ctx context.Context, proof string) (string, error)
+}
+
+// Registry holds the verifiers available to the server, keyed by store
+type Registry struct {
+	verifiers map[string]ReceiptVerifier
+}
+
+// NewRegistry creates a registry with the given verifiers
+func NewRegistry(verifiers ...ReceiptVerifier) *Registry {
+	r := &Registry{verifiers: make(map[string]ReceiptVerifier)}
+	for _, v := range verifiers {
+		r.Register(v)
+	}
+	return r
+}
+
+// Config selects the store verifiers the server accepts receipts from
+type Config struct {
+	SteamWebAPIKey  string
+	SteamAppID      string
+	SteamUseSandbox bool
+	// FakeVerifier accepts made-up receipts; it must never be enabled in production
+	FakeVerifier bool
+}
+
+// NewRegistryFromConfig creates a registry with the verifiers enabled in cfg.
+// The Steam verifier is enabled when both its API key and app ID are set.
+func NewRegistryFromConfig(cfg Config) *Registry {
+	r := NewRegistry()
+
+	if cfg.SteamWebAPIKey != "" && cfg.SteamAppID != "" {
+		r.Register(NewSteamVerifier(cfg.SteamWebAPIKey, cfg.SteamAppID, cfg.SteamUseSandbox))
+	}
+
+	if cfg.FakeVerifier {
+		r.Register(NewFakeVerifier())
+	}
+
+	return r
+}
+
+// Register adds or replaces the verifier for its store
+func (r *Registry) Register(v ReceiptVerifier) {
+	r.verifiers[v.Store()] = v
+}
+
+// Get returns the verifier for a store
+func (r *Registry) Get(store string) (ReceiptVerifier, error) {
+	v, ok := r.verifiers[store]
+	if !ok {
+		return nil, ErrUnknownStore
+	}
+	return v, nil
+}
+
+// Stores returns the configured store identifiers in sorted order
+func (r *Registry) Stores() []string {
+	stores := make([]string, 0, len(r.verifiers))
+	for store := range r.verifiers {
+		stores = append(stores, store)
+	}
+	sort.Strings(stores)
+	return stores
+}
@@ -4,40 +4,100 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/cache"
+	"zombie-car-game-backend/internal/config"
 	"zombie-car-game-backend/internal/handlers"
 	"zombie-car-game-backend/internal/middleware"
+	"zombie-car-game-backend/internal/payments"
 	"zombie-car-game-backend/internal/services"
 )
 
-// SetupRoutes configures all API routes
-func SetupRoutes(r *gin.Engine, db *gorm.DB) {
+// SetupRoutes configures all API routes. jwtService is shared by every service
+// that issues or revokes tokens.
+func SetupRoutes(r *gin.Engine, db *gorm.DB, cfg *config.Config, jwtService *auth.JWTService) {
 	// Initialize services
-	playerService := services.NewPlayerService(db)
+	accountConfig := services.AccountConfig{
+		RefreshTokenTTL: cfg.Auth.RefreshTokenTTL,
+		AppBaseURL:      cfg.Mail.AppBaseURL,
+		TOTPIssuer:      cfg.Auth.TOTPIssuer,
+	}
+	playerService := services.NewPlayerService(db, jwtService, accountConfig)
 	gameStateService := services.NewGameStateService(db, playerService)
 	vehicleService := services.NewVehicleService(db, playerService)
-	jwtService := auth.NewJWTService()
+	promoService := services.NewPromoService(db, playerService)
+	walletService := services.NewWalletService(db)
+	purchaseService := services.NewPurchaseService(db, payments.NewRegistryFromConfig(cfg.PaymentsConfig()))
+	accountService := services.NewAccountService(db, jwtService, accountConfig)
+	privacyService := services.NewPrivacyService(db, jwtService, cfg.Retention.DeletionGracePeriod)
+	sessionService := services.NewSessionService(db, jwtService, cfg.Auth.RefreshTokenTTL)
+	adminService := services.NewAdminService(db, jwtService, cfg.Auth.RefreshTokenTTL)
+	sanctionService := services.NewSanctionService(db, jwtService, cfg.Auth.RefreshTokenTTL)
+	auditService := services.NewAuditService(db, cfg.Retention.Audit)
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(playerService)
 	playerHandler := handlers.NewPlayerHandler(playerService)
 	gameStateHandler := handlers.NewGameStateHandler(gameStateService)
 	vehicleHandler := handlers.NewVehicleHandler(vehicleService)
+	promoHandler := handlers.NewPromoHandler(promoService)
+	walletHandler := handlers.NewWalletHandler(walletService, purchaseService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	privacyHandler := handlers.NewPrivacyHandler(privacyService)
+	twoFactorHandler := handlers.NewTwoFactorHandler(playerService)
+	sessionHandler := handlers.NewSessionHandler(sessionService)
+	adminHandler := handlers.NewAdminHandler(adminService)
+	sanctionHandler := handlers.NewSanctionHandler(sanctionService, playerService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	jwksHandler := handlers.NewJWKSHandler(jwtService)
+	configHandler := handlers.NewConfigHandler(cfg)
+
+	// Admin permission checks
+	viewPlayers := middleware.RequirePermission(auth.PermissionViewPlayers)
+	editPlayers := middleware.RequirePermission(auth.PermissionEditPlayers)
+	sanctionPlayers := middleware.RequirePermission(auth.PermissionSanction)
+	manageRoles := middleware.RequirePermission(auth.PermissionManageRoles)
+	managePromos := middleware.RequirePermission(auth.PermissionManagePromos)
+	refundPayments := middleware.RequirePermission(auth.PermissionRefundPayments)
+	viewAudit := middleware.RequirePermission(auth.PermissionViewAudit)
+	viewConfig := middleware.RequirePermission(auth.PermissionViewConfig)
+
+	// Rate limits: strict on unauthenticated account endpoints, looser for signed-in reads
+	rateLimiter := middleware.NewRateLimiter(cache.GetClient())
+	authLimit := rateLimiter.Limit(cfg.RateLimitPolicy(middleware.RateLimitAuth))
+	registerLimit := rateLimiter.Limit(cfg.RateLimitPolicy(middleware.RateLimitRegister))
+	apiLimit := rateLimiter.LimitRoutes(
+		cfg.RateLimitPolicy(middleware.RateLimitRead),
+		cfg.RateLimitPolicy(middleware.RateLimitWrite),
+		map[string]middleware.RateLimitPolicy{
+			"PUT /api/v1/game/sessions/:id/score": cfg.RateLimitPolicy(middleware.RateLimitScore),
+		},
+	)
+
+	// Public signing keys for services verifying player tokens
+	r.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
 
 	// API v1 routes
 	api := r.Group("/api/v1")
 	{
 		// Public routes (no authentication required)
 		auth := api.Group("/auth")
+		auth.Use(authLimit)
 		{
-			auth.POST("/register", authHandler.Register)
+			auth.POST("/register", registerLimit, authHandler.Register)
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/guest", authHandler.GuestLogin)
+			auth.POST("/2fa/verify", twoFactorHandler.VerifyLogin)
 			auth.POST("/refresh", authHandler.RefreshToken)
-			auth.POST("/logout", authHandler.Logout)
+			auth.POST("/logout", middleware.OptionalAuthMiddleware(jwtService), authHandler.Logout)
+			auth.POST("/verify-email", accountHandler.VerifyEmail)
+			auth.POST("/password/forgot", accountHandler.ForgotPassword)
+			auth.POST("/password/reset", accountHandler.ResetPassword)
+			auth.POST("/appeal", sanctionHandler.Appeal)
 		}
 
 		// Protected routes (authentication required)
 		protected := api.Group("/")
-		protected.Use(middleware.AuthMiddleware(jwtService))
+		protected.Use(middleware.AuthMiddleware(jwtService, sanctionService), apiLimit)
 		{
 			// Player profile routes
 			players := protected.Group("/players")
@@ -47,6 +107,18 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB) {
 				players.PUT("/currency", playerHandler.UpdateCurrency)
 				players.PUT("/level", playerHandler.UpdateLevel)
 				players.PUT("/score", playerHandler.UpdateScore)
+				players.POST("/verify-email/resend", accountHandler.ResendVerification)
+				players.POST("/upgrade", authHandler.UpgradeGuest)
+				players.GET("/me/export", privacyHandler.ExportData)
+				players.DELETE("/me", privacyHandler.DeleteAccount)
+				players.POST("/me/deletion/cancel", privacyHandler.CancelDeletion)
+				players.POST("/2fa/enroll", twoFactorHandler.Enroll)
+				players.POST("/2fa/confirm", twoFactorHandler.Confirm)
+				players.POST("/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+				players.POST("/2fa/disable", twoFactorHandler.Disable)
+				players.GET("/sessions", sessionHandler.ListSessions)
+				players.DELETE("/sessions/:id", sessionHandler.RevokeSession)
+				players.POST("/sessions/revoke-others", sessionHandler.RevokeOtherSessions)
 			}
 
 			// Game state routes
@@ -58,6 +130,7 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB) {
 					sessions.POST("/", gameStateHandler.StartSession)
 					sessions.GET("/", gameStateHandler.GetPlayerSessions)
 					sessions.GET("/active", gameStateHandler.GetActiveSession)
+					sessions.POST("/import", gameStateHandler.ImportSessions)
 					sessions.GET("/:id", gameStateHandler.GetSession)
 					sessions.PUT("/:id/score", gameStateHandler.UpdateScore)
 					sessions.POST("/:id/end", gameStateHandler.EndSession)
@@ -74,11 +147,61 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB) {
 				vehicles.POST("/upgrade", vehicleHandler.UpgradeVehicle)
 			}
 
-			// Admin routes (for now, same as regular player routes)
-			// In the future, we can add admin-specific middleware
+			// Promo code routes
+			promo := protected.Group("/promo")
+			{
+				promo.POST("/redeem", promoHandler.RedeemCode)
+			}
+
+			// Wallet and store purchase routes
+			wallet := protected.Group("/wallet")
+			{
+				wallet.GET("/", walletHandler.GetWallet)
+				wallet.GET("/products", walletHandler.GetProducts)
+				wallet.POST("/purchases", walletHandler.VerifyPurchase)
+				wallet.POST("/store-accounts", walletHandler.LinkStoreAccount)
+			}
+
+			// Sync with the online server, only on the server embedded in the desktop build
+			if cfg.Offline.Enabled {
+				offlineHandler := handlers.NewOfflineHandler(services.NewSyncService(db, cfg.Offline.SyncURL))
+				offline := protected.Group("/offline")
+				{
+					offline.GET("/status", offlineHandler.GetStatus)
+					offline.POST("/sync", offlineHandler.Sync)
+				}
+			}
+
+			// Admin routes, staff roles only; each route also checks its own permission
 			admin := protected.Group("/admin")
+			admin.Use(middleware.AdminMiddleware())
 			{
-				admin.GET("/players/:id", playerHandler.GetPlayerByID)
+				admin.GET("/players", viewPlayers, adminHandler.SearchPlayers)
+				admin.GET("/players/:id", viewPlayers, playerHandler.GetPlayerByID)
+				admin.GET("/players/:id/sessions", viewPlayers, adminHandler.GetPlayerSessions)
+				admin.POST("/players/:id/currency", editPlayers, adminHandler.AdjustCurrency)
+				admin.PUT("/players/:id/level", editPlayers, adminHandler.SetLevel)
+				admin.POST("/players/:id/vehicles", editPlayers, adminHandler.GrantVehicle)
+				admin.DELETE("/players/:id/vehicles/:vehicleId", editPlayers, adminHandler.RevokeVehicle)
+				admin.PUT("/players/:id/role", manageRoles, adminHandler.SetRole)
+
+				admin.GET("/players/:id/sanctions", viewPlayers, sanctionHandler.ListPlayerSanctions)
+				admin.POST("/players/:id/sanctions", sanctionPlayers, sanctionHandler.IssueSanction)
+				admin.GET("/sanctions/appeals", viewPlayers, sanctionHandler.ListAppeals)
+				admin.POST("/sanctions/:id/lift", sanctionPlayers, sanctionHandler.LiftSanction)
+				admin.POST("/sanctions/:id/appeal/reject", sanctionPlayers, sanctionHandler.RejectAppeal)
+
+				admin.GET("/promo-codes", managePromos, promoHandler.ListCodes)
+				admin.POST("/promo-codes", managePromos, promoHandler.CreateCode)
+				admin.DELETE("/promo-codes/:code", managePromos, promoHandler.DeactivateCode)
+				admin.GET("/promo-codes/:code/redemptions", managePromos, promoHandler.GetRedemptions)
+
+				admin.POST("/purchases/refund", refundPayments, walletHandler.RefundPurchase)
+
+				admin.GET("/audit", viewAudit, auditHandler.ListEntries)
+				admin.GET("/audit/verify", viewAudit, auditHandler.VerifyChain)
+
+				admin.GET("/config", viewConfig, configHandler.GetConfig)
 			}
 		}
 	}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+)
+
+var (
+	ErrInvalidAccountToken  = errors.New("invalid or expired token")
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+)
+
+const (
+	emailVerificationTTL = 48 * time.Hour
+	passwordResetTTL     = time.Hour
+)
+
+// AccountService handles email verification and password recovery
+type AccountService struct {
+	db              *gorm.DB
+	passwordService *auth.PasswordService
+	jwtService      *auth.JWTService
+	tokenService    *TokenService
+	appBaseURL      string
+}
+
+// NewAccountService creates a new account service
+func NewAccountService(db *gorm.DB, jwtService *auth.JWTService, cfg AccountConfig) *AccountService {
+	return &AccountService{
+		db:              db,
+		passwordService: auth.NewPasswordService(),
+		jwtService:      jwtService,
+		tokenService:    NewTokenService(db, jwtService, cfg.RefreshTokenTTL),
+		appBaseURL:      cfg.AppBaseURL,
+	}
+}
+
+// VerifyEmailRequest represents an email verification request
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ForgotPasswordRequest represents a password reset request
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest represents a password reset with a mailed token
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+	// IPAddress and RequestID are filled in by the handler for the audit log
+	IPAddress string `json:"-"`
+	RequestID string `json:"-"`
+}
+
+// ResendVerification mails a fresh verification link to the player
+func (s *AccountService) ResendVerification(playerID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var player models.Player
+		if err := tx.First(&player, playerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPlayerNotFound
+			}
+			return fmt.Errorf("database error: %w", err)
+		}
+
+		if player.IsGuest {
+			return ErrGuestAccount
+		}
+		if player.EmailVerifiedAt != nil {
+			return ErrEmailAlreadyVerified
+		}
+
+		return queueEmailVerification(tx, &player, s.appBaseURL)
+	})
+}
+
+// VerifyEmail marks the player's email as verified
+func (s *AccountService) VerifyEmail(token string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		stored, err := redeemAccountToken(tx, token, models.AccountTokenEmailVerification)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Player{}).
+			Where("id = ? AND email_verified_at IS NULL", stored.PlayerID).
+			Update("email_verified_at", time.Now()).Error; err != nil {
+			return fmt.Errorf("failed to verify email: %w", err)
+		}
+		return nil
+	})
+}
+
+// RequestPasswordReset mails a reset link if the email belongs to a player.
+// Unknown emails are ignored so the endpoint cannot be used to enumerate accounts.
+func (s *AccountService) RequestPasswordReset(email string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var player models.Player
+		if err := tx.Where("LOWER(email) = ?", strings.ToLower(email)).First(&player).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return fmt.Errorf("database error: %w", err)
+		}
+
+		// Only the most recent reset link works
+		if err := invalidateAccountTokens(tx, player.ID, models.AccountTokenPasswordReset); err != nil {
+			return err
+		}
+
+		token, err := createAccountToken(tx, player.ID, models.AccountTokenPasswordReset, passwordResetTTL)
+		if err != nil {
+			return err
+		}
+
+		body := fmt.Sprintf("Hi %s,\n\n"+
+			"Someone asked to reset the password for your Zombie Car Game account.\n"+
+			"Use the link below within the next hour to choose a new one:\n\n%s\n\n"+
+			"If this wasn't you, you can ignore this email; your password has not been changed.\n",
+			player.Username, accountLink(s.appBaseURL, "/reset-password", token))
+
+		return enqueueEmail(tx, player.Email, "Reset your Zombie Car Game password", body)
+	})
+}
+
+// ResetPassword sets a new password and ends every existing session of the player
+func (s *AccountService) ResetPassword(req ResetPasswordRequest) error {
+	hashedPassword, err := s.passwordService.HashPassword(req.NewPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	var playerID uint
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		stored, err := redeemAccountToken(tx, req.Token, models.AccountTokenPasswordReset)
+		if err != nil {
+			return err
+		}
+		playerID = stored.PlayerID
+
+		// Receiving the reset mail proves ownership of the address as well
+		if err := tx.Model(&models.Player{}).Where("id = ?", stored.PlayerID).Updates(map[string]interface{}{
+			"password_hash":     hashedPassword,
+			"email_verified_at": gorm.Expr("COALESCE(email_verified_at, ?)", time.Now()),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+
+		return recordAudit(tx, AuditEntry{
+			Action:     models.AuditActionPasswordReset,
+			ActorID:    &stored.PlayerID,
+			TargetType: "player",
+			TargetID:   strconv.FormatUint(uint64(stored.PlayerID), 10),
+			RequestID:  req.RequestID,
+			IPAddress:  req.IPAddress,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.tokenService.RevokeAllForPlayer(playerID); err != nil {
+		return err
+	}
+	if err := s.jwtService.RevokeAllForPlayer(playerID); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+
+	return nil
+}
+
+// queueEmailVerification creates a verification token and queues the mail in tx
+func queueEmailVerification(tx *gorm.DB, player *models.Player, baseURL string) error {
+	if err := invalidateAccountTokens(tx, player.ID, models.AccountTokenEmailVerification); err != nil {
+		return err
+	}
+
+	token, err := createAccountToken(tx, player.ID, models.AccountTokenEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\n"+
+		"Welcome to Zombie Car Game! Please confirm your email address:\n\n%s\n\n"+
+		"The link is valid for 48 hours.\n",
+		player.Username, accountLink(baseURL, "/verify-email", token))
+
+	return enqueueEmail(tx, player.Email, "Confirm your Zombie Car Game email", body)
+}
+
+// createAccountToken stores a hashed single-use token and returns the plain value
+func createAccountToken(tx *gorm.DB, playerID uint, purpose models.AccountTokenPurpose, ttl time.Duration) (string, error) {
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	stored := models.AccountToken{
+		PlayerID:  playerID,
+		Purpose:   purpose,
+		TokenHash: hashOpaqueToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := tx.Create(&stored).Error; err != nil {
+		return "", fmt.Errorf("failed to store token: %w", err)
+	}
+
+	return token, nil
+}
+
+// redeemAccountToken consumes a token; a token can only be redeemed once
+func redeemAccountToken(tx *gorm.DB, token string, purpose models.AccountTokenPurpose) (*models.AccountToken, error) {
+	var stored models.AccountToken
+	if err := tx.Where("token_hash = ? AND purpose = ?", hashOpaqueToken(token), purpose).First(&stored).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAccountToken
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if stored.UsedAt != nil || stored.IsExpired(time.Now()) {
+		return nil, ErrInvalidAccountToken
+	}
+
+	result := tx.Model(&models.AccountToken{}).
+		Where("id = ? AND used_at IS NULL", stored.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to redeem token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidAccountToken
+	}
+
+	return &stored, nil
+}
+
+// invalidateAccountTokens burns every outstanding token of a purpose
+func invalidateAccountTokens(tx *gorm.DB, playerID uint, purpose models.AccountTokenPurpose) error {
+	if err := tx.Model(&models.AccountToken{}).
+		Where("player_id = ? AND purpose = ? AND used_at IS NULL", playerID, purpose).
+		Update("used_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to invalidate tokens: %w", err)
+	}
+	return nil
+}
+
+// accountLink builds a URL on the frontend at base carrying a mailed token
+func accountLink(base, path, token string) string {
+	return strings.TrimRight(base, "/") + path + "?token=" + url.QueryEscape(token)
+}
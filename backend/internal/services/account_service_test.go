@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/mail"
+	"zombie-car-game-backend/internal/models"
+)
+
+func setupAccountTestDB(t *testing.T) *gorm.DB {
+	return openTestDB(t, &models.Player{}, &models.RefreshToken{}, &models.DeviceSession{}, &models.Sanction{}, &models.AccountToken{}, &models.OutboxEmail{}, &models.AuditLog{})
+}
+
+// recordingSender captures delivered mail
+type recordingSender struct {
+	messages []mail.Message
+	err      error
+}
+
+func (s *recordingSender) Send(ctx context.Context, msg mail.Message) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+var mailedTokenPattern = regexp.MustCompile(`\?token=(\S+)`)
+
+// deliverAndExtractToken flushes the outbox and returns the token from the last mail
+func deliverAndExtractToken(t *testing.T, db *gorm.DB) string {
+	sender := &recordingSender{}
+	_, err := NewMailOutbox(db, sender).ProcessPending(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, sender.messages)
+
+	match := mailedTokenPattern.FindStringSubmatch(sender.messages[len(sender.messages)-1].Body)
+	require.Len(t, match, 2)
+	token, err := url.QueryUnescape(match[1])
+	require.NoError(t, err)
+	return token
+}
+
+func TestAccountService_VerifyEmail(t *testing.T) {
+	db := setupAccountTestDB(t)
+	playerService := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
+	accountService := NewAccountService(db, auth.NewJWTService(), testAccountConfig)
+
+	response, err := playerService.CreatePlayer(CreatePlayerRequest{
+		Username: "verifier",
+		Email:    "verifier@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+	assert.Nil(t, response.Player.EmailVerifiedAt)
+
+	token := deliverAndExtractToken(t, db)
+
+	// Only the hash is stored
+	var stored models.AccountToken
+	require.NoError(t, db.First(&stored).Error)
+	assert.NotEqual(t, token, stored.TokenHash)
+
+	require.NoError(t, accountService.VerifyEmail(token))
+
+	var player models.Player
+	require.NoError(t, db.First(&player, response.Player.ID).Error)
+	assert.NotNil(t, player.EmailVerifiedAt)
+
+	// Tokens are single-use
+	assert.ErrorIs(t, accountService.VerifyEmail(token), ErrInvalidAccountToken)
+	assert.ErrorIs(t, accountService.ResendVerification(player.ID), ErrEmailAlreadyVerified)
+}
+
+func TestAccountService_ResetPassword(t *testing.T) {
+	db := setupAccountTestDB(t)
+	playerService := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
+	accountService := NewAccountService(db, auth.NewJWTService(), testAccountConfig)
+	jwtService := accountService.jwtService
+
+	response, err := playerService.CreatePlayer(CreatePlayerRequest{
+		Username: "forgetful",
+		Email:    "forgetful@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+	deliverAndExtractToken(t, db) // drain the verification mail
+
+	require.NoError(t, accountService.RequestPasswordReset("Forgetful@Example.com"))
+	staleToken := deliverAndExtractToken(t, db)
+	require.NoError(t, accountService.RequestPasswordReset("forgetful@example.com"))
+	token := deliverAndExtractToken(t, db)
+
+	// Requesting a new link invalidates the previous one
+	err = accountService.ResetPassword(ResetPasswordRequest{Token: staleToken, NewPassword: "newpassword"})
+	assert.ErrorIs(t, err, ErrInvalidAccountToken)
+
+	require.NoError(t, accountService.ResetPassword(ResetPasswordRequest{Token: token, NewPassword: "newpassword"}))
+
+	// Existing sessions are gone
+	_, err = playerService.RefreshToken(response.RefreshToken, DeviceInfo{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	_, err = jwtService.ValidateToken(response.Token)
+	assert.ErrorIs(t, err, auth.ErrRevokedToken)
+
+	_, err = playerService.Login(LoginRequest{Username: "forgetful", Password: "password123"})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = playerService.Login(LoginRequest{Username: "forgetful", Password: "newpassword"})
+	assert.NoError(t, err)
+
+	// The reset link cannot be replayed
+	err = accountService.ResetPassword(ResetPasswordRequest{Token: token, NewPassword: "another"})
+	assert.ErrorIs(t, err, ErrInvalidAccountToken)
+}
+
+func TestAccountService_RequestPasswordReset_UnknownEmail(t *testing.T) {
+	db := setupAccountTestDB(t)
+	accountService := NewAccountService(db, auth.NewJWTService(), testAccountConfig)
+
+	require.NoError(t, accountService.RequestPasswordReset("nobody@example.com"))
+
+	var count int64
+	require.NoError(t, db.Model(&models.OutboxEmail{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestMailOutbox_RetriesFailedDelivery(t *testing.T) {
+	db := setupAccountTestDB(t)
+	require.NoError(t, enqueueEmail(db, "player@example.com", "Hello", "Body"))
+
+	sent, err := NewMailOutbox(db, &recordingSender{err: errors.New("relay down")}).ProcessPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	var email models.OutboxEmail
+	require.NoError(t, db.First(&email).Error)
+	assert.Equal(t, models.OutboxStatusPending, email.Status)
+	assert.Equal(t, 1, email.Attempts)
+	assert.Equal(t, "relay down", email.LastError)
+
+	// Not due again until the backoff has passed
+	sender := &recordingSender{}
+	sent, err = NewMailOutbox(db, sender).ProcessPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	require.NoError(t, db.Model(&email).Update("next_attempt_at", email.CreatedAt).Error)
+	sent, err = NewMailOutbox(db, sender).ProcessPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	require.Len(t, sender.messages, 1)
+	assert.Equal(t, "player@example.com", sender.messages[0].To)
+
+	require.NoError(t, db.First(&email).Error)
+	assert.Equal(t, models.OutboxStatusSent, email.Status)
+	assert.NotNil(t, email.SentAt)
+}
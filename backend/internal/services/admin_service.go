@@ -0,0 +1,459 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/metrics"
+	"zombie-car-game-backend/internal/models"
+)
+
+var (
+	ErrInvalidRole         = errors.New("invalid role")
+	ErrCannotChangeOwnRole = errors.New("cannot change own role")
+)
+
+const (
+	defaultAdminPageSize  = 20
+	maxAdminPageSize      = 100
+	adminGameSessionLimit = 20
+)
+
+// AdminService backs the staff-only admin API. Every change it makes is
+// written to the audit log in the same transaction.
+type AdminService struct {
+	db           *gorm.DB
+	jwtService   *auth.JWTService
+	tokenService *TokenService
+}
+
+// NewAdminService creates a new admin service
+func NewAdminService(db *gorm.DB, jwtService *auth.JWTService, refreshTTL time.Duration) *AdminService {
+	return &AdminService{
+		db:           db,
+		jwtService:   jwtService,
+		tokenService: NewTokenService(db, jwtService, refreshTTL),
+	}
+}
+
+// AdminActor identifies the staff member performing an admin action
+type AdminActor struct {
+	PlayerID  uint
+	IPAddress string
+	RequestID string
+}
+
+// PlayerSearchRequest filters the admin player search
+type PlayerSearchRequest struct {
+	Query string `form:"q" binding:"max=100"`
+	Role  string `form:"role" binding:"omitempty,oneof=player support moderator admin"`
+	Page  int    `form:"page" binding:"min=0"`
+	Limit int    `form:"limit" binding:"min=0,max=100"`
+}
+
+// PlayerSearchResult is one page of the admin player search
+type PlayerSearchResult struct {
+	Players []models.Player `json:"players"`
+	Total   int64           `json:"total"`
+	Page    int             `json:"page"`
+	Limit   int             `json:"limit"`
+}
+
+// PlayerSessions lists a player's logins and recent games for support staff
+type PlayerSessions struct {
+	DeviceSessions []models.DeviceSession `json:"device_sessions"`
+	GameSessions   []models.GameSession   `json:"game_sessions"`
+}
+
+// AdjustCurrencyRequest represents an admin credit or debit of a player's wallet
+type AdjustCurrencyRequest struct {
+	Currency models.CurrencyType `json:"currency" binding:"required,oneof=soft premium"`
+	Amount   int                 `json:"amount" binding:"required"`
+	Reason   string              `json:"reason" binding:"required,max=255"`
+}
+
+// SetLevelRequest represents an admin change of a player's level
+type SetLevelRequest struct {
+	Level  int    `json:"level" binding:"required,min=1,max=100"`
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+// ResetProgressRequest represents an admin reset of a player's progress
+type ResetProgressRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+}
+
+// GrantVehicleRequest represents an admin grant of a vehicle
+type GrantVehicleRequest struct {
+	VehicleType string `json:"vehicle_type" binding:"required"`
+	Reason      string `json:"reason" binding:"max=255"`
+}
+
+// SetRoleRequest represents an admin change of a player's role
+type SetRoleRequest struct {
+	Role   string `json:"role" binding:"required"`
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+// SearchPlayers finds players by username or email, newest first
+func (s *AdminService) SearchPlayers(req PlayerSearchRequest) (*PlayerSearchResult, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultAdminPageSize
+	}
+	if limit > maxAdminPageSize {
+		limit = maxAdminPageSize
+	}
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+
+	query := s.db.Model(&models.Player{})
+	if q := strings.ToLower(strings.TrimSpace(req.Query)); q != "" {
+		if id, err := strconv.ParseUint(q, 10, 32); err == nil {
+			query = query.Where("id = ?", id)
+		} else {
+			pattern := "%" + escapeLike(q) + "%"
+			query = query.Where("LOWER(username) LIKE ? ESCAPE '\\' OR LOWER(email) LIKE ? ESCAPE '\\'", pattern, pattern)
+		}
+	}
+	if req.Role != "" {
+		query = query.Where("role = ?", req.Role)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count players: %w", err)
+	}
+
+	players := []models.Player{}
+	if err := query.Order("created_at DESC, id DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&players).Error; err != nil {
+		return nil, fmt.Errorf("failed to search players: %w", err)
+	}
+
+	return &PlayerSearchResult{
+		Players: players,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+	}, nil
+}
+
+// GetPlayerSessions returns a player's device sessions, including ended ones, and recent game sessions
+func (s *AdminService) GetPlayerSessions(playerID uint) (*PlayerSessions, error) {
+	if err := ensurePlayerExists(s.db, playerID); err != nil {
+		return nil, err
+	}
+
+	sessions := &PlayerSessions{
+		DeviceSessions: []models.DeviceSession{},
+		GameSessions:   []models.GameSession{},
+	}
+	if err := s.db.Where("player_id = ?", playerID).
+		Order("last_seen_at DESC").
+		Find(&sessions.DeviceSessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get device sessions: %w", err)
+	}
+	if err := s.db.Where("player_id = ?", playerID).
+		Order("started_at DESC").
+		Limit(adminGameSessionLimit).
+		Find(&sessions.GameSessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get game sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// AdjustCurrency credits or debits a player's wallet and returns the new balance.
+// A debit may not take the balance below zero.
+func (s *AdminService) AdjustCurrency(actor AdminActor, playerID uint, req AdjustCurrencyRequest) (int, error) {
+	var balance int
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		balance, err = applyWalletChange(tx, playerID, req.Currency, req.Amount,
+			"admin_adjustment", "admin:"+strconv.FormatUint(uint64(actor.PlayerID), 10))
+		if err != nil {
+			return err
+		}
+		if balance < 0 {
+			return ErrInsufficientFunds
+		}
+
+		return recordAudit(tx, AuditEntry{
+			Action:     models.AuditActionCurrencyAdjusted,
+			ActorID:    &actor.PlayerID,
+			TargetType: "player",
+			TargetID:   strconv.FormatUint(uint64(playerID), 10),
+			Before:     map[string]interface{}{string(req.Currency): balance - req.Amount},
+			After:      map[string]interface{}{string(req.Currency): balance},
+			Details: map[string]interface{}{
+				"amount": req.Amount,
+				"reason": req.Reason,
+			},
+			RequestID: actor.RequestID,
+			IPAddress: actor.IPAddress,
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	if req.Amount > 0 {
+		metrics.CurrencyEarned(string(req.Currency), "admin_adjustment", req.Amount)
+	} else {
+		metrics.CurrencySpent(string(req.Currency), "admin_adjustment", -req.Amount)
+	}
+
+	return balance, nil
+}
+
+// SetLevel changes a player's level
+func (s *AdminService) SetLevel(actor AdminActor, playerID uint, req SetLevelRequest) (*models.Player, error) {
+	var player models.Player
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockPlayer(tx, playerID, &player); err != nil {
+			return err
+		}
+
+		before := player.Level
+		if err := tx.Model(&player).Update("level", req.Level).Error; err != nil {
+			return fmt.Errorf("failed to update level: %w", err)
+		}
+
+		return recordAudit(tx, AuditEntry{
+			Action:     models.AuditActionLevelChanged,
+			ActorID:    &actor.PlayerID,
+			TargetType: "player",
+			TargetID:   strconv.FormatUint(uint64(playerID), 10),
+			Before:     map[string]interface{}{"level": before},
+			After:      map[string]interface{}{"level": req.Level},
+			Details:    map[string]interface{}{"reason": req.Reason},
+			RequestID:  actor.RequestID,
+			IPAddress:  actor.IPAddress,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &player, nil
+}
+
+// ResetProgress puts a player back at level 1 with no score and no level
+// progress. Wallets, vehicles and game history are kept.
+func (s *AdminService) ResetProgress(actor AdminActor, playerID uint, req ResetProgressRequest) (*models.Player, error) {
+	var player models.Player
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockPlayer(tx, playerID, &player); err != nil {
+			return err
+		}
+
+		before := map[string]interface{}{"level": player.Level, "total_score": player.TotalScore}
+
+		// Removed outright, so replaying a level can record it afresh
+		result := tx.Unscoped().Where("player_id = ?", playerID).Delete(&models.LevelProgress{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete level progress: %w", result.Error)
+		}
+		before["levels"] = result.RowsAffected
+
+		if err := tx.Model(&player).Updates(map[string]interface{}{"level": 1, "total_score": 0}).Error; err != nil {
+			return fmt.Errorf("failed to reset player: %w", err)
+		}
+
+		return recordAudit(tx, AuditEntry{
+			Action:     models.AuditActionProgressReset,
+			ActorID:    &actor.PlayerID,
+			TargetType: "player",
+			TargetID:   strconv.FormatUint(uint64(playerID), 10),
+			Before:     before,
+			After:      map[string]interface{}{"level": 1, "total_score": 0, "levels": 0},
+			Details:    map[string]interface{}{"reason": req.Reason},
+			RequestID:  actor.RequestID,
+			IPAddress:  actor.IPAddress,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &player, nil
+}
+
+// GrantVehicle gives a player a vehicle without charging for it
+func (s *AdminService) GrantVehicle(actor AdminActor, playerID uint, req GrantVehicleRequest) (*models.OwnedVehicle, error) {
+	if _, exists := vehicleConfigs[req.VehicleType]; !exists {
+		return nil, ErrInvalidVehicleType
+	}
+
+	var vehicle models.OwnedVehicle
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var player models.Player
+		if err := lockPlayer(tx, playerID, &player); err != nil {
+			return err
+		}
+
+		var owned int64
+		if err := tx.Model(&models.OwnedVehicle{}).
+			Where("player_id = ? AND vehicle_type = ?", playerID, req.VehicleType).
+			Count(&owned).Error; err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		if owned > 0 {
+			return ErrVehicleAlreadyOwned
+		}
+
+		vehicle = models.OwnedVehicle{
+			PlayerID:    playerID,
+			VehicleType: req.VehicleType,
+			PurchasedAt: time.Now(),
+		}
+		if err := tx.Create(&vehicle).Error; err != nil {
+			return fmt.Errorf("failed to grant vehicle: %w", err)
+		}
+
+		return recordAudit(tx, AuditEntry{
+			Action:     models.AuditActionVehicleGranted,
+			ActorID:    &actor.PlayerID,
+			TargetType: "player",
+			TargetID:   strconv.FormatUint(uint64(playerID), 10),
+			After: map[string]interface{}{
+				"vehicle_id":   vehicle.ID,
+				"vehicle_type": vehicle.VehicleType,
+			},
+			Details:   map[string]interface{}{"reason": req.Reason},
+			RequestID: actor.RequestID,
+			IPAddress: actor.IPAddress,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &vehicle, nil
+}
+
+// RevokeVehicle removes a vehicle, with its upgrades, from a player's garage
+func (s *AdminService) RevokeVehicle(actor AdminActor, playerID, vehicleID uint, reason string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var vehicle models.OwnedVehicle
+		if err := tx.Where("id = ? AND player_id = ?", vehicleID, playerID).First(&vehicle).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrVehicleNotFound
+			}
+			return fmt.Errorf("database error: %w", err)
+		}
+
+		if err := tx.Delete(&vehicle).Error; err != nil {
+			return fmt.Errorf("failed to revoke vehicle: %w", err)
+		}
+
+		return recordAudit(tx, AuditEntry{
+			Action:     models.AuditActionVehicleRevoked,
+			ActorID:    &actor.PlayerID,
+			TargetType: "player",
+			TargetID:   strconv.FormatUint(uint64(playerID), 10),
+			Before: map[string]interface{}{
+				"vehicle_id":   vehicle.ID,
+				"vehicle_type": vehicle.VehicleType,
+				"upgrades":     vehicle.Upgrades,
+			},
+			Details:   map[string]interface{}{"reason": reason},
+			RequestID: actor.RequestID,
+			IPAddress: actor.IPAddress,
+		})
+	})
+}
+
+// SetRole changes a player's role. The player's existing logins are ended so
+// that no token keeps carrying the old role.
+func (s *AdminService) SetRole(actor AdminActor, playerID uint, req SetRoleRequest) (*models.Player, error) {
+	if !auth.ValidRole(req.Role) {
+		return nil, ErrInvalidRole
+	}
+	// Keeps the last admin from locking everyone out by accident
+	if actor.PlayerID == playerID {
+		return nil, ErrCannotChangeOwnRole
+	}
+
+	var player models.Player
+	changed := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockPlayer(tx, playerID, &player); err != nil {
+			return err
+		}
+
+		before := player.Role
+		if before == req.Role {
+			return nil
+		}
+		changed = true
+
+		if err := tx.Model(&player).Update("role", req.Role).Error; err != nil {
+			return fmt.Errorf("failed to update role: %w", err)
+		}
+
+		return recordAudit(tx, AuditEntry{
+			Action:     models.AuditActionRoleChanged,
+			ActorID:    &actor.PlayerID,
+			TargetType: "player",
+			TargetID:   strconv.FormatUint(uint64(playerID), 10),
+			Before:     map[string]interface{}{"role": before},
+			After:      map[string]interface{}{"role": req.Role},
+			Details:    map[string]interface{}{"reason": req.Reason},
+			RequestID:  actor.RequestID,
+			IPAddress:  actor.IPAddress,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		if err := s.tokenService.RevokeAllForPlayer(playerID); err != nil {
+			return nil, err
+		}
+		if err := s.jwtService.RevokeAllForPlayer(playerID); err != nil {
+			return nil, fmt.Errorf("failed to revoke access tokens: %w", err)
+		}
+	}
+
+	return &player, nil
+}
+
+// ensurePlayerExists returns ErrPlayerNotFound for unknown or deleted players
+func ensurePlayerExists(db *gorm.DB, playerID uint) error {
+	var count int64
+	if err := db.Model(&models.Player{}).Where("id = ?", playerID).Count(&count).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if count == 0 {
+		return ErrPlayerNotFound
+	}
+	return nil
+}
+
+// lockPlayer loads a player row for update inside tx
+func lockPlayer(tx *gorm.DB, playerID uint, player *models.Player) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(player, playerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPlayerNotFound
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// escapeLike escapes the LIKE wildcards in a user-supplied search term
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+)
+
+func TestAdminService_SearchPlayers(t *testing.T) {
+	db := setupGuestTestDB(t)
+	service := NewAdminService(db, auth.NewJWTService(), testAccountConfig.RefreshTokenTTL)
+
+	alice := createPromoTestPlayer(t, db, "alice")
+	createPromoTestPlayer(t, db, "bob")
+	carol := createPromoTestPlayer(t, db, "carol")
+	require.NoError(t, db.Model(carol).Update("role", auth.RoleAdmin).Error)
+
+	result, err := service.SearchPlayers(PlayerSearchRequest{Query: "ALI"})
+	require.NoError(t, err)
+	require.Len(t, result.Players, 1)
+	assert.Equal(t, alice.ID, result.Players[0].ID)
+
+	result, err = service.SearchPlayers(PlayerSearchRequest{Role: auth.RoleAdmin})
+	require.NoError(t, err)
+	require.Len(t, result.Players, 1)
+	assert.Equal(t, carol.ID, result.Players[0].ID)
+
+	result, err = service.SearchPlayers(PlayerSearchRequest{Limit: 2, Page: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), result.Total)
+	assert.Len(t, result.Players, 1)
+
+	// Wildcards are matched literally
+	result, err = service.SearchPlayers(PlayerSearchRequest{Query: "%"})
+	require.NoError(t, err)
+	assert.Empty(t, result.Players)
+}
+
+func TestAdminService_AdjustCurrency(t *testing.T) {
+	db := setupGuestTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AuditLog{}))
+	service := NewAdminService(db, auth.NewJWTService(), testAccountConfig.RefreshTokenTTL)
+
+	admin := createPromoTestPlayer(t, db, "admin")
+	player := createPromoTestPlayer(t, db, "player")
+	actor := AdminActor{PlayerID: admin.ID, IPAddress: "203.0.113.7"}
+
+	balance, err := service.AdjustCurrency(actor, player.ID, AdjustCurrencyRequest{
+		Currency: models.CurrencySoft, Amount: 250, Reason: "compensation for outage",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1250, balance)
+
+	_, err = service.AdjustCurrency(actor, player.ID, AdjustCurrencyRequest{
+		Currency: models.CurrencySoft, Amount: -5000, Reason: "clawback",
+	})
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = service.AdjustCurrency(actor, 9999, AdjustCurrencyRequest{
+		Currency: models.CurrencySoft, Amount: 1, Reason: "typo",
+	})
+	assert.ErrorIs(t, err, ErrPlayerNotFound)
+
+	var stored models.Player
+	require.NoError(t, db.First(&stored, player.ID).Error)
+	assert.Equal(t, 1250, stored.Currency)
+	assert.Equal(t, int64(1), countRows(t, db, "wallet_transactions", player.ID))
+
+	var audits []models.AuditLog
+	require.NoError(t, db.Where("action = ?", models.AuditActionCurrencyAdjusted).Find(&audits).Error)
+	require.Len(t, audits, 1)
+	assert.Equal(t, admin.ID, *audits[0].ActorID)
+	assert.Equal(t, "203.0.113.7", audits[0].IPAddress)
+
+	var before, after map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(audits[0].Before), &before))
+	require.NoError(t, json.Unmarshal([]byte(audits[0].After), &after))
+	assert.EqualValues(t, 1000, before["soft"])
+	assert.EqualValues(t, 1250, after["soft"])
+}
+
+func TestAdminService_LevelAndVehicles(t *testing.T) {
+	db := setupGuestTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AuditLog{}))
+	service := NewAdminService(db, auth.NewJWTService(), testAccountConfig.RefreshTokenTTL)
+
+	admin := createPromoTestPlayer(t, db, "admin")
+	player := createPromoTestPlayer(t, db, "player")
+	actor := AdminActor{PlayerID: admin.ID}
+
+	updated, err := service.SetLevel(actor, player.ID, SetLevelRequest{Level: 7})
+	require.NoError(t, err)
+	assert.Equal(t, 7, updated.Level)
+
+	_, err = service.GrantVehicle(actor, player.ID, GrantVehicleRequest{VehicleType: "hovercraft"})
+	assert.ErrorIs(t, err, ErrInvalidVehicleType)
+
+	vehicle, err := service.GrantVehicle(actor, player.ID, GrantVehicleRequest{VehicleType: "truck"})
+	require.NoError(t, err)
+	assert.Equal(t, "truck", vehicle.VehicleType)
+
+	_, err = service.GrantVehicle(actor, player.ID, GrantVehicleRequest{VehicleType: "truck"})
+	assert.ErrorIs(t, err, ErrVehicleAlreadyOwned)
+
+	// Another player's vehicle cannot be revoked through this player
+	assert.ErrorIs(t, service.RevokeVehicle(actor, admin.ID, vehicle.ID, ""), ErrVehicleNotFound)
+	require.NoError(t, service.RevokeVehicle(actor, player.ID, vehicle.ID, "granted by mistake"))
+	assert.ErrorIs(t, service.RevokeVehicle(actor, player.ID, vehicle.ID, ""), ErrVehicleNotFound)
+
+	var count int64
+	require.NoError(t, db.Model(&models.OwnedVehicle{}).Where("player_id = ?", player.ID).Count(&count).Error)
+	assert.Zero(t, count)
+
+	var actions []string
+	require.NoError(t, db.Model(&models.AuditLog{}).Order("id").Pluck("action", &actions).Error)
+	assert.Equal(t, []string{
+		models.AuditActionLevelChanged,
+		models.AuditActionVehicleGranted,
+		models.AuditActionVehicleRevoked,
+	}, actions)
+
+	sessions, err := service.GetPlayerSessions(player.ID)
+	require.NoError(t, err)
+	assert.Empty(t, sessions.GameSessions)
+
+	_, err = service.GetPlayerSessions(9999)
+	assert.ErrorIs(t, err, ErrPlayerNotFound)
+}
+
+func TestAdminService_ResetProgress(t *testing.T) {
+	db := setupGuestTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AuditLog{}))
+	service := NewAdminService(db, auth.NewJWTService(), testAccountConfig.RefreshTokenTTL)
+
+	admin := createPromoTestPlayer(t, db, "admin")
+	player := createPromoTestPlayer(t, db, "player")
+	require.NoError(t, db.Model(player).Updates(map[string]interface{}{"level": 12, "total_score": 48000}).Error)
+	require.NoError(t, db.Create(&models.LevelProgress{PlayerID: player.ID, LevelID: "city_1", BestScore: 900, Completed: true}).Error)
+	require.NoError(t, db.Create(&models.LevelProgress{PlayerID: admin.ID, LevelID: "city_1", BestScore: 100}).Error)
+	_, err := service.GrantVehicle(AdminActor{PlayerID: admin.ID}, player.ID, GrantVehicleRequest{VehicleType: "truck"})
+	require.NoError(t, err)
+
+	reset, err := service.ResetProgress(AdminActor{PlayerID: admin.ID}, player.ID, ResetProgressRequest{Reason: "corrupted save"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, reset.Level)
+	assert.Zero(t, reset.TotalScore)
+
+	// Only the player's progress goes; their garage stays
+	var levels int64
+	require.NoError(t, db.Unscoped().Model(&models.LevelProgress{}).Where("player_id = ?", player.ID).Count(&levels).Error)
+	assert.Zero(t, levels)
+	require.NoError(t, db.Model(&models.LevelProgress{}).Where("player_id = ?", admin.ID).Count(&levels).Error)
+	assert.Equal(t, int64(1), levels)
+	assert.Equal(t, int64(1), countRows(t, db, "owned_vehicles", player.ID))
+
+	var audit models.AuditLog
+	require.NoError(t, db.Where("action = ?", models.AuditActionProgressReset).First(&audit).Error)
+	var before map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(audit.Before), &before))
+	assert.EqualValues(t, 12, before["level"])
+	assert.EqualValues(t, 1, before["levels"])
+
+	_, err = service.ResetProgress(AdminActor{PlayerID: admin.ID}, 9999, ResetProgressRequest{Reason: "typo"})
+	assert.ErrorIs(t, err, ErrPlayerNotFound)
+}
+
+func TestAdminService_SetRole(t *testing.T) {
+	db := setupGuestTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AuditLog{}))
+	playerService := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
+	service := NewAdminService(db, auth.NewJWTService(), testAccountConfig.RefreshTokenTTL)
+	jwtService := service.jwtService
+
+	admin := createPromoTestPlayer(t, db, "admin")
+	registered, err := playerService.CreatePlayer(CreatePlayerRequest{
+		Username: "helper",
+		Email:    "helper@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, auth.RolePlayer, registered.Player.Role)
+	helperID := registered.Player.ID
+
+	actor := AdminActor{PlayerID: admin.ID}
+
+	_, err = service.SetRole(actor, admin.ID, SetRoleRequest{Role: auth.RolePlayer})
+	assert.ErrorIs(t, err, ErrCannotChangeOwnRole)
+	_, err = service.SetRole(actor, helperID, SetRoleRequest{Role: "superuser"})
+	assert.ErrorIs(t, err, ErrInvalidRole)
+
+	updated, err := service.SetRole(actor, helperID, SetRoleRequest{Role: auth.RoleSupport, Reason: "joined support team"})
+	require.NoError(t, err)
+	assert.Equal(t, auth.RoleSupport, updated.Role)
+
+	// Logins from before the change are ended
+	_, err = playerService.RefreshToken(registered.RefreshToken, DeviceInfo{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	_, err = jwtService.ValidateToken(registered.Token)
+	assert.ErrorIs(t, err, auth.ErrRevokedToken)
+
+	// New tokens carry the new role
+	loggedIn, err := playerService.Login(LoginRequest{Identifier: "helper", Password: "password123"})
+	require.NoError(t, err)
+	claims, err := jwtService.ValidateToken(loggedIn.Token)
+	require.NoError(t, err)
+	assert.Equal(t, auth.RoleSupport, claims.Role)
+
+	var audits int64
+	require.NoError(t, db.Model(&models.AuditLog{}).Where("action = ?", models.AuditActionRoleChanged).Count(&audits).Error)
+	assert.Equal(t, int64(1), audits)
+}
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/models"
+)
+
+const (
+	// auditChainLockKey serializes appends to the hash chain across server instances (Postgres only)
+	auditChainLockKey = 0x61756469 // "audi"
+	// auditVerifyBatchSize bounds how many entries chain verification loads at once
+	auditVerifyBatchSize = 500
+)
+
+// AuditEntry describes an event to record in the audit log
+type AuditEntry struct {
+	Action     string
+	ActorID    *uint
+	TargetType string
+	TargetID   string
+	// Before and After hold the values the event changed, as they were and as they became
+	Before    map[string]interface{}
+	After     map[string]interface{}
+	Details   map[string]interface{}
+	RequestID string
+	IPAddress string
+}
+
+// recordAudit appends an entry to the audit log using tx, so it commits with the audited change.
+// Appends are serialized so every entry links to the hash of the one before it.
+func recordAudit(tx *gorm.DB, entry AuditEntry) error {
+	before, err := encodeAuditValues(entry.Before)
+	if err != nil {
+		return err
+	}
+	after, err := encodeAuditValues(entry.After)
+	if err != nil {
+		return err
+	}
+	details, err := encodeAuditValues(entry.Details)
+	if err != nil {
+		return err
+	}
+
+	return tx.Transaction(func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "postgres" {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditChainLockKey).Error; err != nil {
+				return fmt.Errorf("failed to lock audit log: %w", err)
+			}
+		}
+
+		var prevHash []string
+		if err := tx.Model(&models.AuditLog{}).Order("id DESC").Limit(1).Pluck("hash", &prevHash).Error; err != nil {
+			return fmt.Errorf("failed to read audit chain: %w", err)
+		}
+
+		record := models.AuditLog{
+			Action:     entry.Action,
+			ActorID:    entry.ActorID,
+			TargetType: entry.TargetType,
+			TargetID:   entry.TargetID,
+			Before:     before,
+			After:      after,
+			Details:    details,
+			RequestID:  entry.RequestID,
+			IPAddress:  entry.IPAddress,
+			// Stored at the precision every supported database keeps, so the hash can be recomputed
+			CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		}
+		if len(prevHash) > 0 {
+			record.PrevHash = prevHash[0]
+		}
+		record.Hash = auditHash(&record)
+
+		if err := tx.Create(&record).Error; err != nil {
+			return fmt.Errorf("failed to record audit log: %w", err)
+		}
+		return nil
+	})
+}
+
+// encodeAuditValues renders audit values as JSON, or an empty string when there are none
+func encodeAuditValues(values map[string]interface{}) (string, error) {
+	if len(values) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit details: %w", err)
+	}
+	return string(data), nil
+}
+
+// auditHash computes the chain hash of an entry from its contents and the previous hash
+func auditHash(entry *models.AuditLog) string {
+	actorID := ""
+	if entry.ActorID != nil {
+		actorID = strconv.FormatUint(uint64(*entry.ActorID), 10)
+	}
+
+	// Field order is fixed by the struct, so the encoding is stable
+	data, _ := json.Marshal(struct {
+		PrevHash   string `json:"prev_hash"`
+		Action     string `json:"action"`
+		ActorID    string `json:"actor_id"`
+		TargetType string `json:"target_type"`
+		TargetID   string `json:"target_id"`
+		Before     string `json:"before"`
+		After      string `json:"after"`
+		Details    string `json:"details"`
+		RequestID  string `json:"request_id"`
+		IPAddress  string `json:"ip_address"`
+		CreatedAt  string `json:"created_at"`
+	}{
+		PrevHash:   entry.PrevHash,
+		Action:     entry.Action,
+		ActorID:    actorID,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Before:     entry.Before,
+		After:      entry.After,
+		Details:    entry.Details,
+		RequestID:  entry.RequestID,
+		IPAddress:  entry.IPAddress,
+		CreatedAt:  entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditService lets staff search the audit log and check it for tampering, and prunes old entries
+type AuditService struct {
+	db        *gorm.DB
+	retention time.Duration
+}
+
+// NewAuditService creates a new audit service; a retention of zero keeps entries forever
+func NewAuditService(db *gorm.DB, retention time.Duration) *AuditService {
+	return &AuditService{
+		db:        db,
+		retention: retention,
+	}
+}
+
+// AuditQuery filters the audit log search
+type AuditQuery struct {
+	Action     string    `form:"action" binding:"max=64"`
+	ActorID    uint      `form:"actor_id"`
+	TargetType string    `form:"target_type" binding:"max=32"`
+	TargetID   string    `form:"target_id" binding:"max=255"`
+	RequestID  string    `form:"request_id" binding:"max=64"`
+	From       time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page       int       `form:"page" binding:"min=0"`
+	Limit      int       `form:"limit" binding:"min=0,max=100"`
+}
+
+// AuditQueryResult is one page of audit entries, newest first
+type AuditQueryResult struct {
+	Entries []models.AuditLog `json:"entries"`
+	Total   int64             `json:"total"`
+	Page    int               `json:"page"`
+	Limit   int               `json:"limit"`
+}
+
+// AuditChainReport is the outcome of checking the audit log's hash chain
+type AuditChainReport struct {
+	Valid   bool `json:"valid"`
+	Checked int  `json:"checked"`
+	// BrokenAt is the first entry whose hash or link does not match
+	BrokenAt *uint  `json:"broken_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Query searches the audit log
+func (s *AuditService) Query(req AuditQuery) (*AuditQueryResult, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultAdminPageSize
+	}
+	if limit > maxAdminPageSize {
+		limit = maxAdminPageSize
+	}
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+
+	query := s.db.Model(&models.AuditLog{})
+	if req.Action != "" {
+		query = query.Where("action = ?", req.Action)
+	}
+	if req.ActorID != 0 {
+		query = query.Where("actor_id = ?", req.ActorID)
+	}
+	if req.TargetType != "" {
+		query = query.Where("target_type = ?", req.TargetType)
+	}
+	if req.TargetID != "" {
+		query = query.Where("target_id = ?", req.TargetID)
+	}
+	if req.RequestID != "" {
+		query = query.Where("request_id = ?", req.RequestID)
+	}
+	if !req.From.IsZero() {
+		query = query.Where("created_at >= ?", req.From)
+	}
+	if !req.To.IsZero() {
+		query = query.Where("created_at < ?", req.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+
+	entries := []models.AuditLog{}
+	if err := query.Order("id DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to search audit log: %w", err)
+	}
+
+	return &AuditQueryResult{
+		Entries: entries,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+	}, nil
+}
+
+// VerifyChain walks the audit log from its oldest retained entry and checks that
+// every entry still matches its hash and links to the entry before it
+func (s *AuditService) VerifyChain(ctx context.Context) (*AuditChainReport, error) {
+	report := &AuditChainReport{Valid: true}
+	var prev *models.AuditLog
+	var lastID uint
+
+	for {
+		var batch []models.AuditLog
+		if err := s.db.WithContext(ctx).
+			Where("id > ?", lastID).
+			Order("id").
+			Limit(auditVerifyBatchSize).
+			Find(&batch).Error; err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+
+		for i := range batch {
+			entry := &batch[i]
+			if prev != nil && entry.PrevHash != prev.Hash {
+				report.fail(entry.ID, "entry does not link to the previous entry")
+				return report, nil
+			}
+			if auditHash(entry) != entry.Hash {
+				report.fail(entry.ID, "entry contents do not match its hash")
+				return report, nil
+			}
+			report.Checked++
+			prev = entry
+		}
+
+		if len(batch) < auditVerifyBatchSize {
+			return report, nil
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+}
+
+func (r *AuditChainReport) fail(id uint, reason string) {
+	r.Valid = false
+	r.BrokenAt = &id
+	r.Reason = reason
+}
+
+// Run prunes expired entries periodically until ctx is cancelled
+func (s *AuditService) Run(ctx context.Context, interval time.Duration) {
+	if s.retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if removed, err := s.PruneExpired(ctx); err != nil {
+			slog.WarnContext(ctx, "Audit retention failed", "error", err)
+		} else if removed > 0 {
+			slog.InfoContext(ctx, "Audit retention removed expired entries", "removed", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PruneExpired deletes entries older than the retention period. Only the oldest
+// part of the log is removed, so the remaining entries still form one chain;
+// the prune itself is recorded along with the hash of the last entry removed.
+func (s *AuditService) PruneExpired(ctx context.Context) (int64, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+
+	cutoff := time.Now().Add(-s.retention)
+	var removed int64
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Everything before the first entry that is still within retention goes
+		var boundary models.AuditLog
+		err := tx.Where("created_at >= ?", cutoff).Order("id").First(&boundary).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("database error: %w", err)
+		}
+
+		var lastPruned models.AuditLog
+		query := tx.Order("id DESC")
+		if err == nil {
+			query = query.Where("id < ?", boundary.ID)
+		}
+		if err := query.First(&lastPruned).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return fmt.Errorf("database error: %w", err)
+		}
+
+		result := tx.Session(&gorm.Session{SkipHooks: true}).
+			Where("id <= ?", lastPruned.ID).
+			Delete(&models.AuditLog{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to prune audit log: %w", result.Error)
+		}
+		removed = result.RowsAffected
+
+		return recordAudit(tx, AuditEntry{
+			Action: models.AuditActionAuditPruned,
+			Details: map[string]interface{}{
+				"removed":        removed,
+				"cutoff":         cutoff.UTC().Format(time.RFC3339),
+				"last_pruned_id": lastPruned.ID,
+				"last_hash":      lastPruned.Hash,
+			},
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+)
+
+func TestAuditService_HashChainDetectsTampering(t *testing.T) {
+	db := setupGuestTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AuditLog{}))
+	service := NewAuditService(db, 0)
+
+	player := createPromoTestPlayer(t, db, "auditee")
+	playerService := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
+	require.NoError(t, playerService.UpdatePlayerCurrency(player.ID, 250))
+	require.NoError(t, playerService.UpdatePlayerCurrency(player.ID, -100))
+	assert.ErrorIs(t, playerService.UpdatePlayerCurrency(player.ID, -10000), ErrInsufficientFunds)
+
+	var entries []models.AuditLog
+	require.NoError(t, db.Order("id").Find(&entries).Error)
+	require.Len(t, entries, 2)
+	assert.Empty(t, entries[0].PrevHash)
+	assert.Equal(t, entries[0].Hash, entries[1].PrevHash)
+	assert.JSONEq(t, `{"currency":1000}`, entries[0].Before)
+	assert.JSONEq(t, `{"currency":1250}`, entries[0].After)
+
+	report, err := service.VerifyChain(context.Background())
+	require.NoError(t, err)
+	assert.True(t, report.Valid)
+	assert.Equal(t, 2, report.Checked)
+
+	// Entries cannot be edited through the model
+	edited := entries[0]
+	edited.After = `{"currency":999999}`
+	assert.ErrorIs(t, db.Save(&edited).Error, models.ErrAuditLogImmutable)
+	assert.ErrorIs(t, db.Delete(&edited).Error, models.ErrAuditLogImmutable)
+
+	// An edit made behind the application's back breaks the chain
+	require.NoError(t, db.Exec("UPDATE audit_logs SET after = ? WHERE id = ?", edited.After, edited.ID).Error)
+	report, err = service.VerifyChain(context.Background())
+	require.NoError(t, err)
+	assert.False(t, report.Valid)
+	require.NotNil(t, report.BrokenAt)
+	assert.Equal(t, entries[0].ID, *report.BrokenAt)
+
+	// So does removing an entry from the middle of the log
+	require.NoError(t, db.Exec("UPDATE audit_logs SET after = ? WHERE id = ?", entries[0].After, entries[0].ID).Error)
+	require.NoError(t, playerService.UpdatePlayerCurrency(player.ID, 1))
+	require.NoError(t, db.Exec("DELETE FROM audit_logs WHERE id = ?", entries[1].ID).Error)
+	report, err = service.VerifyChain(context.Background())
+	require.NoError(t, err)
+	assert.False(t, report.Valid)
+	assert.Equal(t, 1, report.Checked)
+}
+
+func TestAuditService_QueryAndRetention(t *testing.T) {
+	db := setupGuestTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AuditLog{}))
+
+	admin := createPromoTestPlayer(t, db, "admin")
+	player := createPromoTestPlayer(t, db, "target")
+	adminService := NewAdminService(db, auth.NewJWTService(), testAccountConfig.RefreshTokenTTL)
+	actor := AdminActor{PlayerID: admin.ID, IPAddress: "203.0.113.7", RequestID: "req-1"}
+
+	_, err := adminService.SetLevel(actor, player.ID, SetLevelRequest{Level: 7, Reason: "support ticket"})
+	require.NoError(t, err)
+	actor.RequestID = "req-2"
+	_, err = adminService.AdjustCurrency(actor, player.ID, AdjustCurrencyRequest{
+		Currency: models.CurrencySoft, Amount: 50, Reason: "goodwill",
+	})
+	require.NoError(t, err)
+	require.NoError(t, NewPlayerService(db, auth.NewJWTService(), testAccountConfig).UpdatePlayerCurrency(player.ID, 5))
+
+	// The oldest entry falls outside retention
+	require.NoError(t, db.Exec("UPDATE audit_logs SET created_at = ? WHERE request_id = ?",
+		time.Now().Add(-48*time.Hour), "req-1").Error)
+
+	service := NewAuditService(db, 24*time.Hour)
+
+	result, err := service.Query(AuditQuery{ActorID: admin.ID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.Total)
+	assert.Equal(t, models.AuditActionCurrencyAdjusted, result.Entries[0].Action)
+
+	result, err = service.Query(AuditQuery{RequestID: "req-1"})
+	require.NoError(t, err)
+	require.Len(t, result.Entries, 1)
+	assert.Equal(t, models.AuditActionLevelChanged, result.Entries[0].Action)
+	assert.JSONEq(t, `{"level":7}`, result.Entries[0].After)
+
+	result, err = service.Query(AuditQuery{From: time.Now().Add(-time.Hour), TargetType: "player"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.Total)
+
+	removed, err := service.PruneExpired(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), removed)
+
+	removed, err = service.PruneExpired(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+
+	// The remaining entries, including the record of the prune, still verify
+	report, err := service.VerifyChain(context.Background())
+	require.NoError(t, err)
+	assert.True(t, report.Valid)
+	assert.Equal(t, 3, report.Checked)
+
+	result, err = service.Query(AuditQuery{Action: models.AuditActionAuditPruned})
+	require.NoError(t, err)
+	require.Len(t, result.Entries, 1)
+	assert.Contains(t, result.Entries[0].Details, `"removed":1`)
+}
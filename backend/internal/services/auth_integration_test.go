@@ -34,23 +34,12 @@ func TestAuthIntegration_JWTAndPassword(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, uint(1), claims.PlayerID)
 	assert.Equal(t, "testuser", claims.Username)
-
-	// Test token refresh
-	newToken, err := jwtService.RefreshToken(token)
-	require.NoError(t, err)
-	assert.NotEmpty(t, newToken)
-
-	// Validate refreshed token
-	newClaims, err := jwtService.ValidateToken(newToken)
-	require.NoError(t, err)
-	assert.Equal(t, uint(1), newClaims.PlayerID)
-	assert.Equal(t, "testuser", newClaims.Username)
 }
 
 // TestPlayerServiceStructure tests that the player service structure is correct
 func TestPlayerServiceStructure(t *testing.T) {
 	// Test that we can create a player service (even with nil DB for structure testing)
-	service := NewPlayerService(nil)
+	service := NewPlayerService(nil, auth.NewJWTService(), testAccountConfig)
 	assert.NotNil(t, service)
 
 	// Test request structures
@@ -1,13 +1,18 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"zombie-car-game-backend/internal/metrics"
 	"zombie-car-game-backend/internal/models"
+	"zombie-car-game-backend/internal/tracing"
 )
 
 var (
@@ -22,6 +27,8 @@ var (
 type GameStateService struct {
 	db            *gorm.DB
 	playerService *PlayerService
+	// ctx is set by WithContext and parents the spans of the service's methods
+	ctx context.Context
 }
 
 // NewGameStateService creates a new game state service
@@ -32,6 +39,28 @@ func NewGameStateService(db *gorm.DB, playerService *PlayerService) *GameStateSe
 	}
 }
 
+// WithContext returns a copy of the service whose queries, and the spans of its
+// methods, belong to ctx, typically the request context
+func (s *GameStateService) WithContext(ctx context.Context) *GameStateService {
+	copied := *s
+	copied.ctx = ctx
+	copied.db = s.db.WithContext(ctx)
+	if s.playerService != nil {
+		copied.playerService = s.playerService.WithContext(ctx)
+	}
+	return &copied
+}
+
+// startSpan starts a span for method and returns a copy of the service scoped to it
+func (s *GameStateService) startSpan(method string) (*GameStateService, trace.Span) {
+	ctx := s.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := tracing.Start(ctx, "GameStateService."+method)
+	return s.WithContext(ctx), span
+}
+
 // StartSessionRequest represents the request to start a new game session
 type StartSessionRequest struct {
 	LevelID string `json:"level_id" binding:"required"`
@@ -65,6 +94,9 @@ type GameResult struct {
 
 // StartSession creates a new game session for a player
 func (s *GameStateService) StartSession(playerID uint, req StartSessionRequest) (*models.GameSession, error) {
+	s, span := s.startSpan("StartSession")
+	defer span.End()
+
 	// Check if player exists
 	_, err := s.playerService.GetPlayer(playerID)
 	if err != nil {
@@ -90,12 +122,16 @@ func (s *GameStateService) StartSession(playerID uint, req StartSessionRequest)
 	if err := s.db.Create(session).Error; err != nil {
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
+	metrics.SessionStarted()
 
 	return session, nil
 }
 
 // GetSession retrieves a game session by ID
 func (s *GameStateService) GetSession(sessionID uuid.UUID) (*models.GameSession, error) {
+	s, span := s.startSpan("GetSession")
+	defer span.End()
+
 	var session models.GameSession
 	if err := s.db.Preload("Player").First(&session, "id = ?", sessionID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -108,6 +144,9 @@ func (s *GameStateService) GetSession(sessionID uuid.UUID) (*models.GameSession,
 
 // UpdateScore updates the score and stats for an active game session
 func (s *GameStateService) UpdateScore(sessionID uuid.UUID, req UpdateScoreRequest) (*models.GameSession, error) {
+	s, span := s.startSpan("UpdateScore")
+	defer span.End()
+
 	var session models.GameSession
 	if err := s.db.First(&session, "id = ?", sessionID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -140,6 +179,9 @@ func (s *GameStateService) UpdateScore(sessionID uuid.UUID, req UpdateScoreReque
 
 // EndSession ends a game session and calculates rewards
 func (s *GameStateService) EndSession(sessionID uuid.UUID, req EndSessionRequest) (*GameResult, error) {
+	s, span := s.startSpan("EndSession")
+	defer span.End()
+
 	var session models.GameSession
 	if err := s.db.First(&session, "id = ?", sessionID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -169,12 +211,6 @@ func (s *GameStateService) EndSession(sessionID uuid.UUID, req EndSessionRequest
 	session.DistanceTraveled = req.DistanceTraveled
 	session.End(models.SessionState(req.SessionState))
 
-	// Calculate currency earned (10% of score)
-	currencyEarned := req.FinalScore / 10
-	if currencyEarned < 0 {
-		currencyEarned = 0
-	}
-
 	// Start transaction for atomic updates
 	tx := s.db.Begin()
 	defer func() {
@@ -189,32 +225,19 @@ func (s *GameStateService) EndSession(sessionID uuid.UUID, req EndSessionRequest
 		return nil, fmt.Errorf("failed to save session: %w", err)
 	}
 
-	// Update player currency and total score
-	if currencyEarned > 0 {
-		if err := s.playerService.UpdatePlayerCurrency(session.PlayerID, currencyEarned); err != nil {
-			tx.Rollback()
-			return nil, fmt.Errorf("failed to update player currency: %w", err)
-		}
-	}
-
-	if err := s.playerService.UpdatePlayerScore(session.PlayerID, int64(req.FinalScore)); err != nil {
+	currencyEarned, err := s.creditSession(tx, &session)
+	if err != nil {
 		tx.Rollback()
-		return nil, fmt.Errorf("failed to update player score: %w", err)
-	}
-
-	// Update level progress if session was completed
-	levelCompleted := req.SessionState == "completed"
-	if levelCompleted {
-		if err := s.updateLevelProgress(tx, session.PlayerID, session.LevelID, req.FinalScore); err != nil {
-			tx.Rollback()
-			return nil, fmt.Errorf("failed to update level progress: %w", err)
-		}
+		return nil, err
 	}
+	levelCompleted := session.SessionState == models.SessionStateCompleted
 
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	metrics.SessionsEnded(req.SessionState, 1)
+	metrics.CurrencyEarned(string(models.CurrencySoft), "game_session", currencyEarned)
 
 	return &GameResult{
 		SessionID:        session.ID,
@@ -229,6 +252,9 @@ func (s *GameStateService) EndSession(sessionID uuid.UUID, req EndSessionRequest
 
 // GetPlayerSessions retrieves recent game sessions for a player
 func (s *GameStateService) GetPlayerSessions(playerID uint, limit int) ([]models.GameSession, error) {
+	s, span := s.startSpan("GetPlayerSessions")
+	defer span.End()
+
 	var sessions []models.GameSession
 	query := s.db.Where("player_id = ?", playerID).Order("started_at DESC")
 	
@@ -245,6 +271,9 @@ func (s *GameStateService) GetPlayerSessions(playerID uint, limit int) ([]models
 
 // GetActiveSession retrieves the active session for a player
 func (s *GameStateService) GetActiveSession(playerID uint) (*models.GameSession, error) {
+	s, span := s.startSpan("GetActiveSession")
+	defer span.End()
+
 	var session models.GameSession
 	if err := s.db.Where("player_id = ? AND session_state = ?", playerID, models.SessionStateActive).
 		First(&session).Error; err != nil {
@@ -258,42 +287,86 @@ func (s *GameStateService) GetActiveSession(playerID uint) (*models.GameSession,
 
 // endActiveSessions ends all active sessions for a player
 func (s *GameStateService) endActiveSessions(playerID uint) error {
-	return s.db.Model(&models.GameSession{}).
+	result := s.db.Model(&models.GameSession{}).
 		Where("player_id = ? AND session_state = ?", playerID, models.SessionStateActive).
 		Updates(map[string]interface{}{
 			"session_state": models.SessionStateAbandoned,
 			"ended_at":      time.Now(),
-		}).Error
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	metrics.SessionsEnded(string(models.SessionStateAbandoned), int(result.RowsAffected))
+	return nil
 }
 
 // validateScore implements anti-cheat measures for score validation
 func (s *GameStateService) validateScore(session *models.GameSession, req UpdateScoreRequest) error {
 	// Basic validation: score should not decrease
 	if req.Score < session.Score {
+		metrics.ScoreRejected("score_decreased")
 		return ErrScoreValidation
 	}
 
+	return validateScoreRate(time.Since(session.StartedAt), req)
+}
+
+// validateScoreRate checks that req is achievable in the time played
+func validateScoreRate(played time.Duration, req UpdateScoreRequest) error {
 	// Validate zombies killed vs score ratio (minimum 5 points per zombie)
 	if req.ZombiesKilled > 0 && req.Score < req.ZombiesKilled*5 {
+		metrics.ScoreRejected("zombie_score_ratio")
 		return ErrScoreValidation
 	}
 
 	// Validate distance vs time ratio (max 100 units per second)
-	sessionDuration := time.Since(session.StartedAt).Seconds()
+	sessionDuration := played.Seconds()
 	maxDistance := sessionDuration * 100
 	if req.DistanceTraveled > maxDistance {
+		metrics.ScoreRejected("distance_rate")
 		return ErrScoreValidation
 	}
 
 	// Validate score vs time ratio (max 1000 points per second)
 	maxScore := int(sessionDuration * 1000)
 	if req.Score > maxScore {
+		metrics.ScoreRejected("score_rate")
 		return ErrScoreValidation
 	}
 
 	return nil
 }
 
+// creditSession rewards the player for an ended session within tx: currency,
+// total score and, when the level was completed, level progress
+func (s *GameStateService) creditSession(tx *gorm.DB, session *models.GameSession) (int, error) {
+	// Calculate currency earned (10% of score)
+	currencyEarned := session.Score / 10
+	if currencyEarned < 0 {
+		currencyEarned = 0
+	}
+
+	// Update player currency and total score in the same transaction as the session
+	if currencyEarned > 0 {
+		if err := updateCurrency(tx, session.PlayerID, currencyEarned, "game_session", session.ID.String()); err != nil {
+			return 0, fmt.Errorf("failed to update player currency: %w", err)
+		}
+	}
+
+	if err := addScore(tx, session.PlayerID, int64(session.Score)); err != nil {
+		return 0, fmt.Errorf("failed to update player score: %w", err)
+	}
+
+	// Update level progress if session was completed
+	if session.SessionState == models.SessionStateCompleted {
+		if err := s.updateLevelProgress(tx, session.PlayerID, session.LevelID, session.Score); err != nil {
+			return 0, fmt.Errorf("failed to update level progress: %w", err)
+		}
+	}
+
+	return currencyEarned, nil
+}
+
 // updateLevelProgress updates or creates level progress record
 func (s *GameStateService) updateLevelProgress(tx *gorm.DB, playerID uint, levelID string, score int) error {
 	var progress models.LevelProgress
@@ -335,4 +408,233 @@ func (s *GameStateService) calculateStars(score int) int {
 		return 1
 	}
 	return 0
-}
\ No newline at end of file
+}
+const (
+	// maxImportedSessionAge bounds how long a session played offline may wait
+	// to be imported
+	maxImportedSessionAge = 30 * 24 * time.Hour
+	// importClockSkew tolerates an offline machine's clock running ahead of ours
+	importClockSkew = 5 * time.Minute
+	// maxImportedSessionLength caps an imported session at the longest real run.
+	// The score checks allow more the longer a session lasts, so a longer one is
+	// idle time claimed to unlock a bigger score.
+	maxImportedSessionLength = 2 * time.Hour
+	// maxDailyPlaytime caps a player's playtime, online and imported, within one
+	// UTC day, so sessions cannot be packed back to back without end
+	maxDailyPlaytime = 12 * time.Hour
+)
+
+var (
+	// errSessionIDTaken means an imported session's ID belongs to another player
+	errSessionIDTaken = errors.New("session ID belongs to another player")
+	// errSessionOverlaps means an imported session overlaps one the player already has
+	errSessionOverlaps = errors.New("session overlaps another session")
+	// errDailyPlaytimeExceeded means an imported session takes the player over maxDailyPlaytime
+	errDailyPlaytimeExceeded = errors.New("too much playtime on the day the session was played")
+)
+
+// ImportedSession is a finished session played against an offline server
+type ImportedSession struct {
+	ID               uuid.UUID `json:"id" binding:"required"`
+	LevelID          string    `json:"level_id" binding:"required,max=50"`
+	Score            int       `json:"score" binding:"min=0"`
+	ZombiesKilled    int       `json:"zombies_killed" binding:"min=0"`
+	DistanceTraveled float64   `json:"distance_traveled" binding:"min=0"`
+	SessionState     string    `json:"session_state" binding:"required,oneof=completed failed abandoned"`
+	StartedAt        time.Time `json:"started_at" binding:"required"`
+	EndedAt          time.Time `json:"ended_at" binding:"required"`
+}
+
+// ImportSessionsRequest represents the request to import sessions played offline
+type ImportSessionsRequest struct {
+	Sessions []ImportedSession `json:"sessions" binding:"required,min=1,max=100,dive"`
+}
+
+// RejectedSession is an imported session that failed validation
+type RejectedSession struct {
+	ID     uuid.UUID `json:"id"`
+	Reason string    `json:"reason"`
+}
+
+// ImportResult reports what happened to each imported session. Sessions keep
+// their offline IDs, so a retried upload reports them as duplicates instead of
+// rewarding them twice.
+type ImportResult struct {
+	Imported       []uuid.UUID       `json:"imported"`
+	Duplicates     []uuid.UUID       `json:"duplicates"`
+	Rejected       []RejectedSession `json:"rejected"`
+	CurrencyEarned int               `json:"currency_earned"`
+}
+
+// ImportSessions records sessions a player finished against an offline server
+// and rewards them as if they had been played online. Sessions are imported in
+// order, so one overlapping an earlier session of the same upload is rejected
+// like one overlapping a session the player already has.
+func (s *GameStateService) ImportSessions(playerID uint, req ImportSessionsRequest) (*ImportResult, error) {
+	s, span := s.startSpan("ImportSessions")
+	defer span.End()
+
+	// Check if player exists
+	if _, err := s.playerService.GetPlayer(playerID); err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{
+		Imported:   []uuid.UUID{},
+		Duplicates: []uuid.UUID{},
+		Rejected:   []RejectedSession{},
+	}
+	now := time.Now()
+
+	for _, imported := range req.Sessions {
+		if reason := checkImportedSession(imported, now); reason != "" {
+			result.Rejected = append(result.Rejected, RejectedSession{ID: imported.ID, Reason: reason})
+			continue
+		}
+
+		currencyEarned, err := s.importSession(playerID, imported, now)
+		switch {
+		case err == nil:
+			result.Imported = append(result.Imported, imported.ID)
+			result.CurrencyEarned += currencyEarned
+			metrics.SessionsEnded(imported.SessionState, 1)
+			metrics.CurrencyEarned(string(models.CurrencySoft), "game_session", currencyEarned)
+		case errors.Is(err, ErrSessionAlreadyEnded):
+			result.Duplicates = append(result.Duplicates, imported.ID)
+		case errors.Is(err, errSessionIDTaken), errors.Is(err, errSessionOverlaps), errors.Is(err, errDailyPlaytimeExceeded):
+			result.Rejected = append(result.Rejected, RejectedSession{ID: imported.ID, Reason: err.Error()})
+		default:
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// checkImportedSession returns why an imported session is rejected, or "" if it is plausible
+func checkImportedSession(session ImportedSession, now time.Time) string {
+	switch {
+	case !session.EndedAt.After(session.StartedAt):
+		return "session ended before it started"
+	case session.EndedAt.After(now.Add(importClockSkew)):
+		return "session ended in the future"
+	case session.StartedAt.Before(now.Add(-maxImportedSessionAge)):
+		return "session is too old to import"
+	case session.EndedAt.Sub(session.StartedAt) > maxImportedSessionLength:
+		return "session is longer than any real run"
+	}
+
+	score := UpdateScoreRequest{
+		Score:            session.Score,
+		ZombiesKilled:    session.ZombiesKilled,
+		DistanceTraveled: session.DistanceTraveled,
+	}
+	if err := validateScoreRate(session.EndedAt.Sub(session.StartedAt), score); err != nil {
+		return "score is not achievable in the time played"
+	}
+	return ""
+}
+
+// importSession creates and rewards one imported session, returning
+// ErrSessionAlreadyEnded if the player has already imported it
+func (s *GameStateService) importSession(playerID uint, imported ImportedSession, now time.Time) (int, error) {
+	// Stored like the server's own timestamps, so SQLite compares them correctly
+	startedAt, endedAt := imported.StartedAt.Local(), imported.EndedAt.Local()
+
+	var currencyEarned int
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Imports for the same player run one at a time, so two concurrent
+		// uploads cannot both pass the overlap and playtime checks
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Player{}, playerID).Error; err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+
+		var existing models.GameSession
+		err := tx.Unscoped().Select("id", "player_id").First(&existing, "id = ?", imported.ID).Error
+		if err == nil {
+			if existing.PlayerID != playerID {
+				return errSessionIDTaken
+			}
+			return ErrSessionAlreadyEnded
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("database error: %w", err)
+		}
+
+		var overlapping int64
+		if err := tx.Model(&models.GameSession{}).
+			Where("player_id = ? AND started_at < ? AND (ended_at IS NULL OR ended_at > ?)", playerID, endedAt, startedAt).
+			Count(&overlapping).Error; err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		if overlapping > 0 {
+			return errSessionOverlaps
+		}
+
+		if err := checkDailyPlaytime(tx, playerID, startedAt, endedAt, now); err != nil {
+			return err
+		}
+
+		session := models.GameSession{
+			ID:               imported.ID,
+			PlayerID:         playerID,
+			LevelID:          imported.LevelID,
+			Score:            imported.Score,
+			ZombiesKilled:    imported.ZombiesKilled,
+			DistanceTraveled: imported.DistanceTraveled,
+			SessionState:     models.SessionState(imported.SessionState),
+			StartedAt:        startedAt,
+			EndedAt:          &endedAt,
+		}
+		if err := tx.Create(&session).Error; err != nil {
+			return fmt.Errorf("failed to create session: %w", err)
+		}
+
+		currencyEarned, err = s.creditSession(tx, &session)
+		return err
+	})
+	return currencyEarned, err
+}
+
+// checkDailyPlaytime returns errDailyPlaytimeExceeded if a session from startedAt
+// to endedAt takes the player over maxDailyPlaytime on any UTC day it touches.
+// Sessions still active count as running until now.
+func checkDailyPlaytime(tx *gorm.DB, playerID uint, startedAt, endedAt, now time.Time) error {
+	for day := startedAt.UTC().Truncate(24 * time.Hour); day.Before(endedAt); day = day.Add(24 * time.Hour) {
+		dayEnd := day.Add(24 * time.Hour)
+
+		var sessions []models.GameSession
+		if err := tx.Select("started_at", "ended_at").
+			Where("player_id = ? AND started_at < ? AND (ended_at IS NULL OR ended_at > ?)", playerID, dayEnd.Local(), day.Local()).
+			Find(&sessions).Error; err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+
+		played := overlap(startedAt, endedAt, day, dayEnd)
+		for _, session := range sessions {
+			end := now
+			if session.EndedAt != nil {
+				end = *session.EndedAt
+			}
+			played += overlap(session.StartedAt, end, day, dayEnd)
+		}
+		if played > maxDailyPlaytime {
+			return errDailyPlaytimeExceeded
+		}
+	}
+	return nil
+}
+
+// overlap returns how much of start to end falls between from and to
+func overlap(start, end, from, to time.Time) time.Duration {
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
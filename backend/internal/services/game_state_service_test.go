@@ -8,44 +8,43 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/auth"
 	"zombie-car-game-backend/internal/models"
 )
 
 func setupGameStateTestDB(t *testing.T) *gorm.DB {
-	// Skip tests if CGO is not available
-	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
-	if err != nil {
-		t.Skip("SQLite requires CGO, skipping database tests")
-		return nil
-	}
-
-	// Auto migrate the schema
-	err = db.AutoMigrate(&models.Player{}, &models.GameSession{}, &models.LevelProgress{}, &models.OwnedVehicle{})
-	if err != nil {
-		t.Fatalf("Failed to migrate database: %v", err)
-	}
-
-	return db
+	return openTestDB(t, &models.Player{}, &models.GameSession{}, &models.LevelProgress{}, &models.OwnedVehicle{}, &models.AuditLog{}, &models.WalletTransaction{})
 }
 
 func createTestPlayerForGameState(t *testing.T, db *gorm.DB, currency int) *models.Player {
+	// Usernames are unique, so each player created in a test gets its own
+	var existing int64
+	require.NoError(t, db.Unscoped().Model(&models.Player{}).Count(&existing).Error)
+	username := fmt.Sprintf("testplayer%d", existing+1)
+
 	player := &models.Player{
-		Username:     "testplayer",
-		Email:        "test@example.com",
+		Username:     username,
+		Email:        username + "@example.com",
 		PasswordHash: "hashedpassword",
 		Currency:     currency,
 		Level:        1,
 		TotalScore:   0,
 	}
-	db.Create(player)
+	require.NoError(t, db.Create(player).Error)
 	return player
 }
 
+// backdateSession moves a session's start a minute into the past, so the scores
+// the tests submit are plausible for the time played
+func backdateSession(t *testing.T, db *gorm.DB, session *models.GameSession) {
+	session.StartedAt = session.StartedAt.Add(-time.Minute)
+	require.NoError(t, db.Model(session).Update("started_at", session.StartedAt).Error)
+}
+
 func TestGameStateService_StartSession(t *testing.T) {
 	db := setupGameStateTestDB(t)
-	playerService := NewPlayerService(db)
+	playerService := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 	gameStateService := NewGameStateService(db, playerService)
 
 	player := createTestPlayerForGameState(t, db, 1000)
@@ -104,7 +103,7 @@ func TestGameStateService_StartSession(t *testing.T) {
 
 func TestGameStateService_GetSession(t *testing.T) {
 	db := setupGameStateTestDB(t)
-	playerService := NewPlayerService(db)
+	playerService := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 	gameStateService := NewGameStateService(db, playerService)
 
 	player := createTestPlayerForGameState(t, db, 1000)
@@ -137,7 +136,7 @@ func TestGameStateService_GetSession(t *testing.T) {
 
 func TestGameStateService_UpdateScore(t *testing.T) {
 	db := setupGameStateTestDB(t)
-	playerService := NewPlayerService(db)
+	playerService := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 	gameStateService := NewGameStateService(db, playerService)
 
 	player := createTestPlayerForGameState(t, db, 1000)
@@ -147,6 +146,7 @@ func TestGameStateService_UpdateScore(t *testing.T) {
 		req := StartSessionRequest{LevelID: "level_1"}
 		session, err := gameStateService.StartSession(player.ID, req)
 		require.NoError(t, err)
+		backdateSession(t, db, session)
 
 		// Update score
 		updateReq := UpdateScoreRequest{
@@ -184,6 +184,7 @@ func TestGameStateService_UpdateScore(t *testing.T) {
 		req := StartSessionRequest{LevelID: "level_1"}
 		session, err := gameStateService.StartSession(player.ID, req)
 		require.NoError(t, err)
+		backdateSession(t, db, session)
 
 		endReq := EndSessionRequest{
 			FinalScore:       50,
@@ -211,7 +212,7 @@ func TestGameStateService_UpdateScore(t *testing.T) {
 
 func TestGameStateService_ValidateScore(t *testing.T) {
 	db := setupGameStateTestDB(t)
-	playerService := NewPlayerService(db)
+	playerService := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 	gameStateService := NewGameStateService(db, playerService)
 
 	player := createTestPlayerForGameState(t, db, 1000)
@@ -220,6 +221,7 @@ func TestGameStateService_ValidateScore(t *testing.T) {
 	req := StartSessionRequest{LevelID: "level_1"}
 	session, err := gameStateService.StartSession(player.ID, req)
 	require.NoError(t, err)
+	backdateSession(t, db, session)
 
 	t.Run("valid score update", func(t *testing.T) {
 		updateReq := UpdateScoreRequest{
@@ -239,7 +241,7 @@ func TestGameStateService_ValidateScore(t *testing.T) {
 			ZombiesKilled:    10,
 			DistanceTraveled: 50.0,
 		}
-		_, err := gameStateService.UpdateScore(session.ID, updateReq1)
+		updated, err := gameStateService.UpdateScore(session.ID, updateReq1)
 		require.NoError(t, err)
 
 		// Try to decrease score
@@ -249,7 +251,7 @@ func TestGameStateService_ValidateScore(t *testing.T) {
 			DistanceTraveled: 25.0,
 		}
 
-		err = gameStateService.validateScore(session, updateReq2)
+		err = gameStateService.validateScore(updated, updateReq2)
 		assert.Error(t, err)
 		assert.Equal(t, ErrScoreValidation, err)
 	})
@@ -293,7 +295,7 @@ func TestGameStateService_ValidateScore(t *testing.T) {
 
 func TestGameStateService_EndSession(t *testing.T) {
 	db := setupGameStateTestDB(t)
-	playerService := NewPlayerService(db)
+	playerService := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 	gameStateService := NewGameStateService(db, playerService)
 
 	player := createTestPlayerForGameState(t, db, 1000)
@@ -303,6 +305,7 @@ func TestGameStateService_EndSession(t *testing.T) {
 		req := StartSessionRequest{LevelID: "level_1"}
 		session, err := gameStateService.StartSession(player.ID, req)
 		require.NoError(t, err)
+		backdateSession(t, db, session)
 
 		// End session
 		endReq := EndSessionRequest{
@@ -328,6 +331,13 @@ func TestGameStateService_EndSession(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, 1050, updatedPlayer.Currency) // 1000 + 50
 
+		// Check that the reward is in the wallet ledger
+		var entry models.WalletTransaction
+		require.NoError(t, db.Where("reference = ?", session.ID.String()).First(&entry).Error)
+		assert.Equal(t, "game_session", entry.Reason)
+		assert.Equal(t, 50, entry.Amount)
+		assert.Equal(t, 1050, entry.BalanceAfter)
+
 		// Check that player total score was updated
 		assert.Equal(t, int64(500), updatedPlayer.TotalScore)
 
@@ -359,6 +369,7 @@ func TestGameStateService_EndSession(t *testing.T) {
 		req := StartSessionRequest{LevelID: "level_1"}
 		session, err := gameStateService.StartSession(player.ID, req)
 		require.NoError(t, err)
+		backdateSession(t, db, session)
 
 		endReq := EndSessionRequest{
 			FinalScore:       500,
@@ -382,6 +393,7 @@ func TestGameStateService_EndSession(t *testing.T) {
 		req := StartSessionRequest{LevelID: "level_2"}
 		session, err := gameStateService.StartSession(player.ID, req)
 		require.NoError(t, err)
+		backdateSession(t, db, session)
 
 		// End session as failed
 		endReq := EndSessionRequest{
@@ -401,7 +413,7 @@ func TestGameStateService_EndSession(t *testing.T) {
 
 func TestGameStateService_GetPlayerSessions(t *testing.T) {
 	db := setupGameStateTestDB(t)
-	playerService := NewPlayerService(db)
+	playerService := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 	gameStateService := NewGameStateService(db, playerService)
 
 	player := createTestPlayerForGameState(t, db, 1000)
@@ -412,6 +424,7 @@ func TestGameStateService_GetPlayerSessions(t *testing.T) {
 			req := StartSessionRequest{LevelID: "level_1"}
 			session, err := gameStateService.StartSession(player.ID, req)
 			require.NoError(t, err)
+			backdateSession(t, db, session)
 
 			// End each session
 			endReq := EndSessionRequest{
@@ -450,7 +463,7 @@ func TestGameStateService_GetPlayerSessions(t *testing.T) {
 
 func TestGameStateService_GetActiveSession(t *testing.T) {
 	db := setupGameStateTestDB(t)
-	playerService := NewPlayerService(db)
+	playerService := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 	gameStateService := NewGameStateService(db, playerService)
 
 	player := createTestPlayerForGameState(t, db, 1000)
@@ -460,6 +473,7 @@ func TestGameStateService_GetActiveSession(t *testing.T) {
 		req := StartSessionRequest{LevelID: "level_1"}
 		createdSession, err := gameStateService.StartSession(player.ID, req)
 		require.NoError(t, err)
+		backdateSession(t, db, createdSession)
 
 		// Get active session
 		activeSession, err := gameStateService.GetActiveSession(player.ID)
@@ -495,7 +509,7 @@ func TestGameStateService_GetActiveSession(t *testing.T) {
 
 func TestGameStateService_CalculateStars(t *testing.T) {
 	db := setupGameStateTestDB(t)
-	playerService := NewPlayerService(db)
+	playerService := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 	gameStateService := NewGameStateService(db, playerService)
 
 	tests := []struct {
@@ -517,4 +531,184 @@ func TestGameStateService_CalculateStars(t *testing.T) {
 			assert.Equal(t, test.expected, stars)
 		})
 	}
-}
\ No newline at end of file
+}
+func TestGameStateService_ImportSessions(t *testing.T) {
+	db := setupGameStateTestDB(t)
+	playerService := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
+	gameStateService := NewGameStateService(db, playerService)
+
+	player := createTestPlayerForGameState(t, db, 1000)
+	other := &models.Player{Username: "otherplayer", Email: "other@example.com", PasswordHash: "hashedpassword"}
+	require.NoError(t, db.Create(other).Error)
+
+	endedAt := time.Now().Add(-time.Hour)
+	played := ImportedSession{
+		ID:               uuid.New(),
+		LevelID:          "level_1",
+		Score:            1000,
+		ZombiesKilled:    10,
+		DistanceTraveled: 500,
+		SessionState:     "completed",
+		StartedAt:        endedAt.Add(-5 * time.Minute),
+		EndedAt:          endedAt,
+	}
+
+	t.Run("imports and rewards new sessions", func(t *testing.T) {
+		result, err := gameStateService.ImportSessions(player.ID, ImportSessionsRequest{Sessions: []ImportedSession{played}})
+
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{played.ID}, result.Imported)
+		assert.Empty(t, result.Duplicates)
+		assert.Empty(t, result.Rejected)
+		assert.Equal(t, 100, result.CurrencyEarned)
+
+		var session models.GameSession
+		require.NoError(t, db.First(&session, "id = ?", played.ID).Error)
+		assert.Equal(t, player.ID, session.PlayerID)
+		assert.Equal(t, models.SessionStateCompleted, session.SessionState)
+
+		var updatedPlayer models.Player
+		require.NoError(t, db.First(&updatedPlayer, player.ID).Error)
+		assert.Equal(t, 1100, updatedPlayer.Currency)
+		assert.Equal(t, int64(1000), updatedPlayer.TotalScore)
+
+		var entry models.WalletTransaction
+		require.NoError(t, db.Where("reference = ?", played.ID.String()).First(&entry).Error)
+		assert.Equal(t, "game_session", entry.Reason)
+		assert.Equal(t, 100, entry.Amount)
+
+		var progress models.LevelProgress
+		require.NoError(t, db.Where("player_id = ? AND level_id = ?", player.ID, "level_1").First(&progress).Error)
+		assert.True(t, progress.Completed)
+	})
+
+	t.Run("reports a retried upload as duplicates", func(t *testing.T) {
+		result, err := gameStateService.ImportSessions(player.ID, ImportSessionsRequest{Sessions: []ImportedSession{played}})
+
+		require.NoError(t, err)
+		assert.Empty(t, result.Imported)
+		assert.Equal(t, []uuid.UUID{played.ID}, result.Duplicates)
+		assert.Equal(t, 0, result.CurrencyEarned)
+
+		var updatedPlayer models.Player
+		require.NoError(t, db.First(&updatedPlayer, player.ID).Error)
+		assert.Equal(t, 1100, updatedPlayer.Currency)
+	})
+
+	t.Run("rejects implausible sessions", func(t *testing.T) {
+		backwards := played
+		backwards.ID = uuid.New()
+		backwards.StartedAt, backwards.EndedAt = played.EndedAt, played.StartedAt
+
+		future := played
+		future.ID = uuid.New()
+		future.StartedAt = time.Now().Add(time.Hour)
+		future.EndedAt = future.StartedAt.Add(5 * time.Minute)
+
+		stale := played
+		stale.ID = uuid.New()
+		stale.EndedAt = time.Now().Add(-maxImportedSessionAge - time.Hour)
+		stale.StartedAt = stale.EndedAt.Add(-5 * time.Minute)
+
+		tooFast := played
+		tooFast.ID = uuid.New()
+		tooFast.StartedAt = played.EndedAt.Add(-time.Second)
+
+		// Started long ago so the score checks allow a huge score
+		tooLong := played
+		tooLong.ID = uuid.New()
+		tooLong.Score = 10000000
+		tooLong.EndedAt = time.Now().Add(-2 * time.Hour)
+		tooLong.StartedAt = tooLong.EndedAt.Add(-maxImportedSessionAge + time.Hour)
+
+		req := ImportSessionsRequest{Sessions: []ImportedSession{backwards, future, stale, tooFast, tooLong}}
+		result, err := gameStateService.ImportSessions(player.ID, req)
+
+		require.NoError(t, err)
+		assert.Empty(t, result.Imported)
+		require.Len(t, result.Rejected, 5)
+		for i, session := range req.Sessions {
+			assert.Equal(t, session.ID, result.Rejected[i].ID)
+			assert.NotEmpty(t, result.Rejected[i].Reason)
+		}
+	})
+
+	t.Run("rejects overlapping sessions", func(t *testing.T) {
+		// Overlaps the session imported above
+		overlapsExisting := played
+		overlapsExisting.ID = uuid.New()
+		overlapsExisting.StartedAt = played.StartedAt.Add(time.Minute)
+		overlapsExisting.EndedAt = played.EndedAt.Add(time.Minute)
+
+		first := played
+		first.ID = uuid.New()
+		first.StartedAt = played.EndedAt.Add(10 * time.Minute)
+		first.EndedAt = first.StartedAt.Add(5 * time.Minute)
+
+		// Overlaps the first session of the same upload
+		second := first
+		second.ID = uuid.New()
+		second.StartedAt = first.StartedAt.Add(2 * time.Minute)
+		second.EndedAt = first.EndedAt.Add(2 * time.Minute)
+
+		req := ImportSessionsRequest{Sessions: []ImportedSession{overlapsExisting, first, second}}
+		result, err := gameStateService.ImportSessions(player.ID, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{first.ID}, result.Imported)
+		require.Len(t, result.Rejected, 2)
+		assert.Equal(t, overlapsExisting.ID, result.Rejected[0].ID)
+		assert.Equal(t, second.ID, result.Rejected[1].ID)
+		assert.Equal(t, errSessionOverlaps.Error(), result.Rejected[1].Reason)
+	})
+
+	t.Run("caps playtime per day", func(t *testing.T) {
+		// Fill a day with back to back sessions up to the cap
+		day := time.Now().UTC().Truncate(24*time.Hour).Add(-3 * 24 * time.Hour)
+		for start := day; start.Before(day.Add(maxDailyPlaytime)); start = start.Add(maxImportedSessionLength) {
+			end := start.Add(maxImportedSessionLength).Local()
+			require.NoError(t, db.Create(&models.GameSession{
+				PlayerID:     player.ID,
+				LevelID:      "level_1",
+				SessionState: models.SessionStateFailed,
+				StartedAt:    start.Local(),
+				EndedAt:      &end,
+			}).Error)
+		}
+
+		sameDay := played
+		sameDay.ID = uuid.New()
+		sameDay.StartedAt = day.Add(20 * time.Hour)
+		sameDay.EndedAt = sameDay.StartedAt.Add(5 * time.Minute)
+
+		nextDay := sameDay
+		nextDay.ID = uuid.New()
+		nextDay.StartedAt = sameDay.StartedAt.Add(24 * time.Hour)
+		nextDay.EndedAt = nextDay.StartedAt.Add(5 * time.Minute)
+
+		req := ImportSessionsRequest{Sessions: []ImportedSession{sameDay, nextDay}}
+		result, err := gameStateService.ImportSessions(player.ID, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{nextDay.ID}, result.Imported)
+		require.Len(t, result.Rejected, 1)
+		assert.Equal(t, sameDay.ID, result.Rejected[0].ID)
+		assert.Equal(t, errDailyPlaytimeExceeded.Error(), result.Rejected[0].Reason)
+	})
+
+	t.Run("rejects another player's session ID", func(t *testing.T) {
+		result, err := gameStateService.ImportSessions(other.ID, ImportSessionsRequest{Sessions: []ImportedSession{played}})
+
+		require.NoError(t, err)
+		assert.Empty(t, result.Imported)
+		assert.Empty(t, result.Duplicates)
+		require.Len(t, result.Rejected, 1)
+		assert.Equal(t, played.ID, result.Rejected[0].ID)
+	})
+
+	t.Run("unknown player", func(t *testing.T) {
+		_, err := gameStateService.ImportSessions(99999, ImportSessionsRequest{Sessions: []ImportedSession{played}})
+
+		assert.ErrorIs(t, err, ErrPlayerNotFound)
+	})
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"zombie-car-game-backend/internal/models"
+)
+
+var (
+	ErrNotGuest     = errors.New("player is not a guest")
+	ErrGuestAccount = errors.New("guest accounts have no credentials")
+)
+
+const (
+	// guestCleanupBatchSize bounds how many guests one cleanup pass removes
+	guestCleanupBatchSize = 100
+	// guestEmailDomain is reserved (RFC 2606) so placeholder addresses can never receive mail
+	guestEmailDomain = "guest.invalid"
+)
+
+// GuestLoginRequest represents a device login for an anonymous player
+type GuestLoginRequest struct {
+	DeviceID string `json:"device_id" binding:"required,min=16,max=128"`
+	// IPAddress and UserAgent are filled in by the handler to describe the device session
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// UpgradeGuestRequest attaches credentials to a guest account
+type UpgradeGuestRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=50"`
+	Email    string `json:"email" binding:"required,email,max=100"`
+	Password string `json:"password" binding:"required,min=6"`
+	// IPAddress and UserAgent are filled in by the handler to describe the device session
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// LoginAsGuest returns tokens for the guest bound to a device, creating it on first use
+func (s *PlayerService) LoginAsGuest(req GuestLoginRequest) (*AuthResponse, error) {
+	deviceHash := hashOpaqueToken(req.DeviceID)
+
+	var player models.Player
+	err := s.db.Where("device_id_hash = ?", deviceHash).First(&player).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		player, err = s.createGuest(deviceHash)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.newAuthResponse(&player, DeviceInfo{UserAgent: req.UserAgent, IPAddress: req.IPAddress})
+}
+
+// createGuest creates an anonymous player for a device
+func (s *PlayerService) createGuest(deviceHash string) (models.Player, error) {
+	suffix := make([]byte, 5)
+	if _, err := rand.Read(suffix); err != nil {
+		return models.Player{}, fmt.Errorf("failed to generate guest name: %w", err)
+	}
+
+	player := models.Player{
+		Username:     "guest_" + hex.EncodeToString(suffix),
+		Email:        uuid.NewString() + "@" + guestEmailDomain,
+		PasswordHash: "", // no password can ever match
+		IsGuest:      true,
+		DeviceIDHash: &deviceHash,
+		Currency:     1000, // Starting currency
+		Level:        1,
+	}
+
+	if err := s.db.Create(&player).Error; err != nil {
+		// Two first requests from the same device can race; the loser picks up the winner's guest
+		var existing models.Player
+		if findErr := s.db.Where("device_id_hash = ?", deviceHash).First(&existing).Error; findErr == nil {
+			return existing, nil
+		}
+		return models.Player{}, fmt.Errorf("failed to create guest: %w", err)
+	}
+
+	return player, nil
+}
+
+// UpgradeGuest turns a guest into a full account in place, so vehicles, sessions
+// and progress stay attached to the same player ID.
+func (s *PlayerService) UpgradeGuest(playerID uint, req UpgradeGuestRequest) (*AuthResponse, error) {
+	hashedPassword, err := s.passwordService.HashPassword(req.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	var player models.Player
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&player, playerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPlayerNotFound
+			}
+			return fmt.Errorf("database error: %w", err)
+		}
+
+		if !player.IsGuest {
+			return ErrNotGuest
+		}
+
+		var existing models.Player
+		if err := tx.Where("LOWER(username) = ? AND id <> ?", strings.ToLower(req.Username), player.ID).
+			First(&existing).Error; err == nil {
+			return ErrUsernameExists
+		}
+		if err := tx.Where("LOWER(email) = ? AND id <> ?", strings.ToLower(req.Email), player.ID).
+			First(&existing).Error; err == nil {
+			return ErrEmailExists
+		}
+
+		// The device no longer signs in on its own once the account has credentials
+		if err := tx.Model(&player).Updates(map[string]interface{}{
+			"username":       req.Username,
+			"email":          req.Email,
+			"password_hash":  hashedPassword,
+			"is_guest":       false,
+			"device_id_hash": nil,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to upgrade guest: %w", err)
+		}
+		player.Username = req.Username
+		player.Email = req.Email
+		player.PasswordHash = hashedPassword
+		player.IsGuest = false
+		player.DeviceIDHash = nil
+
+		return queueEmailVerification(tx, &player, s.appBaseURL)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Device-bound sessions end; the player continues with the new credentials
+	if err := s.tokenService.RevokeAllForPlayer(player.ID); err != nil {
+		return nil, err
+	}
+
+	return s.newAuthResponse(&player, DeviceInfo{UserAgent: req.UserAgent, IPAddress: req.IPAddress})
+}
+
+// GuestCleanup deletes guest accounts that have not been used for a while
+type GuestCleanup struct {
+	db         *gorm.DB
+	inactivity time.Duration
+}
+
+// NewGuestCleanup creates a guest cleanup worker; an inactivity period of zero disables it
+func NewGuestCleanup(db *gorm.DB, inactivity time.Duration) *GuestCleanup {
+	return &GuestCleanup{
+		db:         db,
+		inactivity: inactivity,
+	}
+}
+
+// Run cleans up periodically until ctx is cancelled
+func (g *GuestCleanup) Run(ctx context.Context, interval time.Duration) {
+	if g.inactivity <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if removed, err := g.CleanupInactiveGuests(ctx); err != nil {
+			slog.WarnContext(ctx, "Guest cleanup failed", "error", err)
+		} else if removed > 0 {
+			slog.InfoContext(ctx, "Guest cleanup removed inactive guest accounts", "removed", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CleanupInactiveGuests permanently deletes inactive guests and everything they own.
+// Guests that made a store purchase are kept so paid currency is never destroyed,
+// and sanctioned guests are kept so a banned device cannot start over.
+func (g *GuestCleanup) CleanupInactiveGuests(ctx context.Context) (int, error) {
+	if g.inactivity <= 0 {
+		return 0, nil
+	}
+
+	cutoff := time.Now().Add(-g.inactivity)
+	removed := 0
+
+	for ctx.Err() == nil {
+		var ids []uint
+		if err := g.db.WithContext(ctx).Model(&models.Player{}).
+			Where("is_guest = ? AND COALESCE(last_active_at, created_at) < ?", true, cutoff).
+			Where("NOT EXISTS (SELECT 1 FROM purchases WHERE purchases.player_id = players.id)").
+			Where("NOT EXISTS (SELECT 1 FROM sanctions WHERE sanctions.player_id = players.id)").
+			Order("id").
+			Limit(guestCleanupBatchSize).
+			Pluck("id", &ids).Error; err != nil {
+			return removed, fmt.Errorf("database error: %w", err)
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		if err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return deletePlayerData(tx, ids)
+		}); err != nil {
+			return removed, err
+		}
+		removed += len(ids)
+
+		if len(ids) < guestCleanupBatchSize {
+			break
+		}
+	}
+
+	return removed, nil
+}
+
+// deletePlayerData hard-deletes players together with every row that references them
+func deletePlayerData(tx *gorm.DB, playerIDs []uint) error {
+	owned := []interface{}{
+		&models.OwnedVehicle{},
+		&models.GameSession{},
+		&models.LevelProgress{},
+		&models.InventoryItem{},
+		&models.PromoRedemption{},
+		&models.WalletTransaction{},
+		&models.StoreAccount{},
+		&models.RefreshToken{},
+		&models.DeviceSession{},
+		&models.AccountToken{},
+	}
+	for _, model := range owned {
+		if err := tx.Unscoped().Where("player_id IN ?", playerIDs).Delete(model).Error; err != nil {
+			return fmt.Errorf("failed to delete player data: %w", err)
+		}
+	}
+
+	if err := tx.Unscoped().Where("id IN ?", playerIDs).Delete(&models.Player{}).Error; err != nil {
+		return fmt.Errorf("failed to delete players: %w", err)
+	}
+	return nil
+}
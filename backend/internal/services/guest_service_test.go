@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+)
+
+func setupGuestTestDB(t *testing.T) *gorm.DB {
+	return openTestDB(t, &models.Player{}, &models.OwnedVehicle{}, &models.GameSession{}, &models.LevelProgress{}, &models.InventoryItem{},
+		&models.PromoRedemption{}, &models.WalletTransaction{}, &models.Purchase{}, &models.StoreAccount{},
+		&models.RefreshToken{}, &models.DeviceSession{}, &models.Sanction{}, &models.AccountToken{}, &models.OutboxEmail{},
+		&models.TwoFactorCredential{}, &models.RecoveryCode{})
+}
+
+func countRows(t *testing.T, db *gorm.DB, table string, playerID uint) int64 {
+	var count int64
+	require.NoError(t, db.Table(table).Where("player_id = ?", playerID).Count(&count).Error)
+	return count
+}
+
+func TestPlayerService_LoginAsGuest(t *testing.T) {
+	db := setupGuestTestDB(t)
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
+
+	first, err := service.LoginAsGuest(GuestLoginRequest{DeviceID: "device-0123456789abcdef"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, first.Token)
+	assert.NotEmpty(t, first.RefreshToken)
+	assert.True(t, first.Player.IsGuest)
+	assert.Regexp(t, `^guest_[0-9a-f]{10}$`, first.Player.Username)
+
+	// The same device gets the same guest back
+	second, err := service.LoginAsGuest(GuestLoginRequest{DeviceID: "device-0123456789abcdef"})
+	require.NoError(t, err)
+	assert.Equal(t, first.Player.ID, second.Player.ID)
+
+	other, err := service.LoginAsGuest(GuestLoginRequest{DeviceID: "device-fedcba9876543210"})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Player.ID, other.Player.ID)
+
+	// The raw device ID is never stored
+	var stored models.Player
+	require.NoError(t, db.First(&stored, first.Player.ID).Error)
+	require.NotNil(t, stored.DeviceIDHash)
+	assert.NotContains(t, *stored.DeviceIDHash, "device-")
+
+	// Guests cannot sign in with a password
+	_, err = service.Login(LoginRequest{Identifier: first.Player.Username, Password: ""})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestPlayerService_UpgradeGuest(t *testing.T) {
+	db := setupGuestTestDB(t)
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
+
+	guest, err := service.LoginAsGuest(GuestLoginRequest{DeviceID: "device-0123456789abcdef"})
+	require.NoError(t, err)
+	guestID := guest.Player.ID
+
+	require.NoError(t, db.Create(&models.OwnedVehicle{PlayerID: guestID, VehicleType: "sedan"}).Error)
+	require.NoError(t, db.Create(&models.LevelProgress{PlayerID: guestID, LevelID: "level-1", BestScore: 900}).Error)
+	require.NoError(t, db.Exec("INSERT INTO game_sessions (id, player_id, level_id) VALUES (?, ?, ?)",
+		"session-1", guestID, "level-1").Error)
+
+	upgraded, err := service.UpgradeGuest(guestID, UpgradeGuestRequest{
+		Username: "Survivor",
+		Email:    "survivor@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, guestID, upgraded.Player.ID)
+	assert.False(t, upgraded.Player.IsGuest)
+	assert.Equal(t, "Survivor", upgraded.Player.Username)
+
+	// Everything the guest earned stays with the account
+	assert.Equal(t, int64(1), countRows(t, db, "owned_vehicles", guestID))
+	assert.Equal(t, int64(1), countRows(t, db, "level_progress", guestID))
+	assert.Equal(t, int64(1), countRows(t, db, "game_sessions", guestID))
+
+	// The guest's device session is over, the new credentials work
+	_, err = service.RefreshToken(guest.RefreshToken, DeviceInfo{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	_, err = service.Login(LoginRequest{Identifier: "survivor@example.com", Password: "password123"})
+	assert.NoError(t, err)
+
+	// The device starts a fresh guest instead of signing into the full account
+	again, err := service.LoginAsGuest(GuestLoginRequest{DeviceID: "device-0123456789abcdef"})
+	require.NoError(t, err)
+	assert.NotEqual(t, guestID, again.Player.ID)
+
+	_, err = service.UpgradeGuest(guestID, UpgradeGuestRequest{Username: "x", Email: "x@example.com", Password: "password123"})
+	assert.ErrorIs(t, err, ErrNotGuest)
+
+	_, err = service.UpgradeGuest(again.Player.ID, UpgradeGuestRequest{
+		Username: "survivor",
+		Email:    "new@example.com",
+		Password: "password123",
+	})
+	assert.ErrorIs(t, err, ErrUsernameExists)
+}
+
+func TestGuestCleanup_CleanupInactiveGuests(t *testing.T) {
+	db := setupGuestTestDB(t)
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
+
+	stale, err := service.LoginAsGuest(GuestLoginRequest{DeviceID: "device-stale-000000000"})
+	require.NoError(t, err)
+	payer, err := service.LoginAsGuest(GuestLoginRequest{DeviceID: "device-payer-000000000"})
+	require.NoError(t, err)
+	active, err := service.LoginAsGuest(GuestLoginRequest{DeviceID: "device-active-00000000"})
+	require.NoError(t, err)
+	registered := createPromoTestPlayer(t, db, "registered")
+
+	longAgo := time.Now().Add(-90 * 24 * time.Hour)
+	require.NoError(t, db.Model(&models.Player{}).
+		Where("id IN ?", []uint{stale.Player.ID, payer.Player.ID, registered.ID}).
+		UpdateColumn("last_active_at", longAgo).Error)
+
+	require.NoError(t, db.Create(&models.OwnedVehicle{PlayerID: stale.Player.ID, VehicleType: "sedan"}).Error)
+	require.NoError(t, db.Exec("INSERT INTO game_sessions (id, player_id, level_id) VALUES (?, ?, ?)",
+		"session-1", stale.Player.ID, "level-1").Error)
+	require.NoError(t, db.Create(&models.Purchase{
+		PlayerID: payer.Player.ID, Store: "fake", TransactionID: "txn-1", ProductID: "1001",
+		Quantity: 1, PremiumAmount: 100, Status: models.PurchaseStatusCredited, PurchasedAt: longAgo,
+	}).Error)
+
+	removed, err := NewGuestCleanup(db, 30*24*time.Hour).CleanupInactiveGuests(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	var remaining []uint
+	require.NoError(t, db.Unscoped().Model(&models.Player{}).Order("id").Pluck("id", &remaining).Error)
+	assert.Equal(t, []uint{payer.Player.ID, active.Player.ID, registered.ID}, remaining)
+	assert.Equal(t, int64(0), countRows(t, db, "owned_vehicles", stale.Player.ID))
+	assert.Equal(t, int64(0), countRows(t, db, "game_sessions", stale.Player.ID))
+	assert.Equal(t, int64(0), countRows(t, db, "refresh_tokens", stale.Player.ID))
+
+	// A zero period disables cleanup
+	removed, err = NewGuestCleanup(db, 0).CleanupInactiveGuests(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, removed)
+}
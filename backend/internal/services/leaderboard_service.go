@@ -0,0 +1,68 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+var ErrLeaderboardsUnsupported = errors.New("leaderboards require Postgres")
+
+// LeaderboardService maintains the leaderboard table and the views built on it
+type LeaderboardService struct {
+	db *gorm.DB
+}
+
+// NewLeaderboardService creates a new leaderboard service
+func NewLeaderboardService(db *gorm.DB) *LeaderboardService {
+	return &LeaderboardService{
+		db: db,
+	}
+}
+
+// Rebuild replaces the leaderboard with every active player's best score on
+// each level, taken from their level progress, then refreshes the
+// materialized leaderboard. It returns the number of entries written.
+// Sanctioned players are filtered out by the views, not here.
+func (s *LeaderboardService) Rebuild() (int64, error) {
+	if s.db.Dialector.Name() != "postgres" {
+		return 0, ErrLeaderboardsUnsupported
+	}
+
+	var entries int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM leaderboard").Error; err != nil {
+			return fmt.Errorf("failed to clear leaderboard: %w", err)
+		}
+
+		result := tx.Exec(`
+			INSERT INTO leaderboard (player_id, level_id, score, achieved_at)
+			SELECT lp.player_id, lp.level_id, lp.best_score, COALESCE(lp.completed_at, NOW())
+			FROM level_progress lp
+			JOIN players p ON p.id = lp.player_id
+			WHERE lp.best_score > 0 AND lp.deleted_at IS NULL AND p.deleted_at IS NULL`)
+		if result.Error != nil {
+			return fmt.Errorf("failed to fill leaderboard: %w", result.Error)
+		}
+		entries = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Refreshed concurrently, which cannot happen inside the transaction above
+	if err := refreshLeaderboardView(s.db); err != nil {
+		return 0, err
+	}
+	return entries, nil
+}
+
+// refreshLeaderboardView rebuilds the materialized leaderboard from the leaderboard table
+func refreshLeaderboardView(db *gorm.DB) error {
+	if err := db.Exec("SELECT refresh_leaderboard()").Error; err != nil {
+		return fmt.Errorf("failed to refresh leaderboards: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,452 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrTooManyLoginAttempts is the sentinel wrapped by LoginThrottledError
+var ErrTooManyLoginAttempts = errors.New("too many login attempts")
+
+// LoginThrottledError is returned while a username or IP has to wait before trying again
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrTooManyLoginAttempts, e.RetryAfter)
+}
+
+// Unwrap lets callers match the error with errors.Is(err, ErrTooManyLoginAttempts)
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrTooManyLoginAttempts
+}
+
+// ThrottlePolicy configures backoff for one kind of subject (username or IP)
+type ThrottlePolicy struct {
+	// FreeAttempts is how many failures are allowed before any delay kicks in
+	FreeAttempts int64
+	// LockoutAttempts is the failure count at which the subject is locked out
+	LockoutAttempts int64
+	// BaseDelay doubles with every failure past FreeAttempts
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay
+	MaxDelay time.Duration
+	// LockoutDuration is how long a lockout lasts
+	LockoutDuration time.Duration
+	// Window is how long failures are remembered
+	Window time.Duration
+}
+
+var (
+	// usernameThrottlePolicy protects individual accounts against password guessing
+	usernameThrottlePolicy = ThrottlePolicy{
+		FreeAttempts:    5,
+		LockoutAttempts: 10,
+		BaseDelay:       time.Second,
+		MaxDelay:        5 * time.Minute,
+		LockoutDuration: 15 * time.Minute,
+		Window:          15 * time.Minute,
+	}
+	// ipThrottlePolicy is looser so players behind a shared NAT are not punished for each other
+	ipThrottlePolicy = ThrottlePolicy{
+		FreeAttempts:    20,
+		LockoutAttempts: 100,
+		BaseDelay:       time.Second,
+		MaxDelay:        5 * time.Minute,
+		LockoutDuration: time.Hour,
+		Window:          time.Hour,
+	}
+)
+
+// delayAfter returns how long a subject must wait after its n-th failure
+func (p ThrottlePolicy) delayAfter(failures int64) (time.Duration, bool) {
+	if failures >= p.LockoutAttempts {
+		return p.LockoutDuration, true
+	}
+	if failures < p.FreeAttempts {
+		return 0, false
+	}
+
+	delay := p.BaseDelay
+	for i := p.FreeAttempts; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay, false
+}
+
+// LockoutEvent describes a subject that just got locked out
+type LockoutEvent struct {
+	Subject  string // "username" or "ip"
+	Value    string
+	Failures int64
+	Duration time.Duration
+}
+
+// throttleSubject is one budget a login attempt is charged to
+type throttleSubject struct {
+	name   string // "username" or "ip"
+	value  string
+	policy ThrottlePolicy
+}
+
+func (s throttleSubject) failKey() string  { return "login:fail:" + s.name + ":" + s.value }
+func (s throttleSubject) blockKey() string { return "login:block:" + s.name + ":" + s.value }
+
+// subjectCharge is what a reservation charged to one subject
+type subjectCharge struct {
+	failures int64
+	// delay is how long the reservation blocked the subject for, or 0
+	delay time.Duration
+}
+
+// attemptStore keeps failure counters and block deadlines
+type attemptStore interface {
+	// Reserve charges one failure to every subject and blocks each for the delay its
+	// policy imposes after that many failures, as one atomic step. If any subject is
+	// still blocked nothing is charged and the longest remaining block is returned.
+	Reserve(ctx context.Context, subjects []throttleSubject) ([]subjectCharge, time.Duration, error)
+	// Refund takes back a reservation, lifting the blocks it set
+	Refund(ctx context.Context, subjects []throttleSubject, charges []subjectCharge) error
+	Reset(ctx context.Context, key string) error
+}
+
+// LoginThrottle applies exponential backoff and lockout to failed logins.
+//
+// Every attempt is charged to its username and IP before the credentials are
+// checked, and released again if they turn out right, so a burst of concurrent
+// attempts cannot all slip in before the first failure is counted.
+//
+// Counters live in Redis so every replica sees the same state. When Redis is
+// not configured or fails, the throttle degrades to per-process counters so
+// login keeps working with weaker protection instead of failing closed.
+type LoginThrottle struct {
+	primary  attemptStore
+	fallback attemptStore
+	username ThrottlePolicy
+	ip       ThrottlePolicy
+}
+
+// NewLoginThrottle creates a login throttle; client may be nil
+func NewLoginThrottle(client *redis.Client) *LoginThrottle {
+	t := &LoginThrottle{
+		fallback: newMemoryAttemptStore(),
+		username: usernameThrottlePolicy,
+		ip:       ipThrottlePolicy,
+	}
+	if client != nil {
+		t.primary = &redisAttemptStore{client: client}
+	}
+	return t
+}
+
+// loginThrottleTimeout bounds every Redis round trip made on the login path
+const loginThrottleTimeout = 250 * time.Millisecond
+
+// LoginAttempt is an attempt charged to a username and IP by Reserve. It counts
+// as a failure unless it is released.
+type LoginAttempt struct {
+	store    attemptStore
+	subjects []throttleSubject
+	charges  []subjectCharge
+}
+
+// Reserve charges an attempt to the username and IP, or returns a
+// LoginThrottledError if either has to wait
+func (t *LoginThrottle) Reserve(username, ip string) (*LoginAttempt, error) {
+	subjects := []throttleSubject{{"username", normalizeUsername(username), t.username}}
+	if ip != "" {
+		subjects = append(subjects, throttleSubject{"ip", ip, t.ip})
+	}
+
+	store, charges, wait := t.reserve(subjects)
+	if wait > 0 {
+		return nil, &LoginThrottledError{RetryAfter: wait}
+	}
+	return &LoginAttempt{store: store, subjects: subjects, charges: charges}, nil
+}
+
+// Failed keeps the attempt as a failure and returns any lockouts it triggered
+func (a *LoginAttempt) Failed() []LockoutEvent {
+	var events []LockoutEvent
+	for i, subject := range a.subjects {
+		// Only the attempt that crosses the threshold reports the lockout
+		if a.charges[i].failures == subject.policy.LockoutAttempts {
+			events = append(events, LockoutEvent{
+				Subject:  subject.name,
+				Value:    subject.value,
+				Failures: a.charges[i].failures,
+				Duration: a.charges[i].delay,
+			})
+		}
+	}
+	return events
+}
+
+// Release takes back an attempt whose credentials were right
+func (a *LoginAttempt) Release() {
+	ctx, cancel := context.WithTimeout(context.Background(), loginThrottleTimeout)
+	defer cancel()
+	if err := a.store.Refund(ctx, a.subjects, a.charges); err != nil {
+		slog.Warn("Failed to release login attempt", "error", err)
+	}
+}
+
+// RecordSuccess clears the username's failure history; the IP counter is kept
+// so one valid account cannot be used to reset an IP that is spraying others.
+func (t *LoginThrottle) RecordSuccess(username string) {
+	name := normalizeUsername(username)
+	t.reset("login:fail:username:" + name)
+	t.reset("login:block:username:" + name)
+}
+
+func (t *LoginThrottle) reserve(subjects []throttleSubject) (attemptStore, []subjectCharge, time.Duration) {
+	if t.primary != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), loginThrottleTimeout)
+		defer cancel()
+		charges, wait, err := t.primary.Reserve(ctx, subjects)
+		if err == nil {
+			return t.primary, charges, wait
+		}
+		slog.Warn("Login throttle degraded, Redis unavailable", "error", err)
+	}
+	charges, wait, _ := t.fallback.Reserve(context.Background(), subjects)
+	return t.fallback, charges, wait
+}
+
+func (t *LoginThrottle) reset(key string) {
+	if t.primary != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), loginThrottleTimeout)
+		defer cancel()
+		t.primary.Reset(ctx, key)
+	}
+	t.fallback.Reset(context.Background(), key)
+}
+
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// redisAttemptStore keeps counters in Redis
+type redisAttemptStore struct {
+	client *redis.Client
+}
+
+// reserveScript checks and charges every subject in one step. KEYS holds the block
+// and failure key of each subject in turn, ARGV its window, free attempts, lockout
+// attempts, base delay, max delay and lockout duration, durations in milliseconds.
+// The delay mirrors ThrottlePolicy.delayAfter.
+var reserveScript = redis.NewScript(`
+local wait = 0
+for i = 1, #KEYS, 2 do
+  local ttl = redis.call('PTTL', KEYS[i])
+  if ttl > wait then wait = ttl end
+end
+if wait > 0 then
+  return {wait}
+end
+
+local result = {0}
+for i = 1, #KEYS, 2 do
+  local a = (i - 1) * 3
+  local window, free, lockout = tonumber(ARGV[a + 1]), tonumber(ARGV[a + 2]), tonumber(ARGV[a + 3])
+  local base, max, lockout_ms = tonumber(ARGV[a + 4]), tonumber(ARGV[a + 5]), tonumber(ARGV[a + 6])
+
+  local failures = redis.call('INCR', KEYS[i + 1])
+  redis.call('PEXPIRE', KEYS[i + 1], window)
+
+  local delay = 0
+  if failures >= lockout then
+    delay = lockout_ms
+  elseif failures >= free then
+    delay = base
+    for _ = free, failures - 1 do
+      if delay >= max then break end
+      delay = delay * 2
+    end
+    if delay > max then delay = max end
+  end
+  if delay > 0 then
+    redis.call('SET', KEYS[i], 1, 'PX', delay)
+  end
+
+  table.insert(result, failures)
+  table.insert(result, delay)
+end
+return result
+`)
+
+// refundScript takes a reservation back. KEYS is laid out as for reserveScript,
+// ARGV holds 1 for every subject whose block the reservation set.
+var refundScript = redis.NewScript(`
+for i = 1, #KEYS, 2 do
+  local failures = tonumber(redis.call('GET', KEYS[i + 1]) or '0')
+  if failures > 0 then
+    redis.call('DECR', KEYS[i + 1])
+  end
+  if ARGV[(i + 1) / 2] == '1' then
+    redis.call('DEL', KEYS[i])
+  end
+end
+return 0
+`)
+
+func (s *redisAttemptStore) Reserve(ctx context.Context, subjects []throttleSubject) ([]subjectCharge, time.Duration, error) {
+	keys := make([]string, 0, 2*len(subjects))
+	args := make([]interface{}, 0, 6*len(subjects))
+	for _, subject := range subjects {
+		p := subject.policy
+		keys = append(keys, subject.blockKey(), subject.failKey())
+		args = append(args, p.Window.Milliseconds(), p.FreeAttempts, p.LockoutAttempts,
+			p.BaseDelay.Milliseconds(), p.MaxDelay.Milliseconds(), p.LockoutDuration.Milliseconds())
+	}
+
+	values, err := reserveScript.Run(ctx, s.client, keys, args...).Int64Slice()
+	if err != nil {
+		return nil, 0, err
+	}
+	if values[0] > 0 {
+		return nil, time.Duration(values[0]) * time.Millisecond, nil
+	}
+	if len(values) != 1+2*len(subjects) {
+		return nil, 0, fmt.Errorf("unexpected reserve result %v", values)
+	}
+
+	charges := make([]subjectCharge, len(subjects))
+	for i := range charges {
+		charges[i] = subjectCharge{
+			failures: values[1+2*i],
+			delay:    time.Duration(values[2+2*i]) * time.Millisecond,
+		}
+	}
+	return charges, 0, nil
+}
+
+func (s *redisAttemptStore) Refund(ctx context.Context, subjects []throttleSubject, charges []subjectCharge) error {
+	keys := make([]string, 0, 2*len(subjects))
+	args := make([]interface{}, 0, len(subjects))
+	for i, subject := range subjects {
+		keys = append(keys, subject.blockKey(), subject.failKey())
+		blocked := 0
+		if charges[i].delay > 0 {
+			blocked = 1
+		}
+		args = append(args, blocked)
+	}
+	return refundScript.Run(ctx, s.client, keys, args...).Err()
+}
+
+func (s *redisAttemptStore) Reset(ctx context.Context, key string) error {
+	return s.client.Del(ctx, key).Err()
+}
+
+// memoryAttemptStore keeps counters in process memory
+type memoryAttemptStore struct {
+	mu        sync.Mutex
+	counters  map[string]memoryCounter
+	blocks    map[string]time.Time
+	lastSweep time.Time
+}
+
+type memoryCounter struct {
+	count     int64
+	expiresAt time.Time
+}
+
+func newMemoryAttemptStore() *memoryAttemptStore {
+	return &memoryAttemptStore{
+		counters: make(map[string]memoryCounter),
+		blocks:   make(map[string]time.Time),
+	}
+}
+
+func (s *memoryAttemptStore) Reserve(ctx context.Context, subjects []throttleSubject) ([]subjectCharge, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	var wait time.Duration
+	for _, subject := range subjects {
+		if remaining := s.blocks[subject.blockKey()].Sub(now); remaining > wait {
+			wait = remaining
+		}
+	}
+	if wait > 0 {
+		return nil, wait, nil
+	}
+
+	charges := make([]subjectCharge, len(subjects))
+	for i, subject := range subjects {
+		key := subject.failKey()
+		counter := s.counters[key]
+		if now.After(counter.expiresAt) {
+			counter = memoryCounter{}
+		}
+		counter.count++
+		counter.expiresAt = now.Add(subject.policy.Window)
+		s.counters[key] = counter
+
+		delay, _ := subject.policy.delayAfter(counter.count)
+		if delay > 0 {
+			s.blocks[subject.blockKey()] = now.Add(delay)
+		}
+		charges[i] = subjectCharge{failures: counter.count, delay: delay}
+	}
+	return charges, 0, nil
+}
+
+func (s *memoryAttemptStore) Refund(ctx context.Context, subjects []throttleSubject, charges []subjectCharge) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, subject := range subjects {
+		key := subject.failKey()
+		if counter, ok := s.counters[key]; ok && counter.count > 0 {
+			counter.count--
+			s.counters[key] = counter
+		}
+		if charges[i].delay > 0 {
+			delete(s.blocks, subject.blockKey())
+		}
+	}
+	return nil
+}
+
+func (s *memoryAttemptStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.counters, key)
+	delete(s.blocks, key)
+	return nil
+}
+
+// sweep drops expired entries so the maps cannot grow without bound
+func (s *memoryAttemptStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, counter := range s.counters {
+		if now.After(counter.expiresAt) {
+			delete(s.counters, key)
+		}
+	}
+	for key, until := range s.blocks {
+		if now.After(until) {
+			delete(s.blocks, key)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+)
+
+func TestThrottlePolicy_DelayAfter(t *testing.T) {
+	policy := ThrottlePolicy{
+		FreeAttempts:    3,
+		LockoutAttempts: 8,
+		BaseDelay:       time.Second,
+		MaxDelay:        10 * time.Second,
+		LockoutDuration: time.Hour,
+	}
+
+	cases := []struct {
+		failures int64
+		delay    time.Duration
+		locked   bool
+	}{
+		{1, 0, false},
+		{2, 0, false},
+		{3, time.Second, false},
+		{4, 2 * time.Second, false},
+		{5, 4 * time.Second, false},
+		{6, 8 * time.Second, false},
+		{7, 10 * time.Second, false},
+		{8, time.Hour, true},
+		{20, time.Hour, true},
+	}
+
+	for _, tc := range cases {
+		delay, locked := policy.delayAfter(tc.failures)
+		assert.Equal(t, tc.delay, delay, "failures=%d", tc.failures)
+		assert.Equal(t, tc.locked, locked, "failures=%d", tc.failures)
+	}
+}
+
+// failLogin reserves an attempt and keeps it as a failure
+func failLogin(t *testing.T, throttle *LoginThrottle, username, ip string) []LockoutEvent {
+	t.Helper()
+	attempt, err := throttle.Reserve(username, ip)
+	require.NoError(t, err)
+	return attempt.Failed()
+}
+
+func TestLoginThrottle_BackoffAndLockout(t *testing.T) {
+	throttle := NewLoginThrottle(nil)
+	throttle.username = ThrottlePolicy{
+		FreeAttempts:    2,
+		LockoutAttempts: 3,
+		BaseDelay:       time.Minute,
+		MaxDelay:        time.Minute,
+		LockoutDuration: time.Hour,
+		Window:          time.Hour,
+	}
+
+	assert.Empty(t, failLogin(t, throttle, "Victim", "10.0.0.1"))
+	assert.Empty(t, failLogin(t, throttle, "victim", "10.0.0.1"))
+
+	_, err := throttle.Reserve("VICTIM", "10.0.0.2")
+	var throttled *LoginThrottledError
+	require.True(t, errors.As(err, &throttled))
+	assert.ErrorIs(t, err, ErrTooManyLoginAttempts)
+	assert.InDelta(t, time.Minute.Seconds(), throttled.RetryAfter.Seconds(), 1)
+
+	// Wait out the delay
+	require.NoError(t, throttle.fallback.Reset(context.Background(), "login:block:username:victim"))
+	events := failLogin(t, throttle, "victim", "10.0.0.1")
+	require.Len(t, events, 1)
+	assert.Equal(t, "username", events[0].Subject)
+	assert.Equal(t, "victim", events[0].Value)
+	assert.Equal(t, time.Hour, events[0].Duration)
+
+	// Other accounts are unaffected by one account's lockout
+	_, err = throttle.Reserve("bystander", "10.0.0.3")
+	assert.NoError(t, err)
+
+	throttle.RecordSuccess("victim")
+	_, err = throttle.Reserve("victim", "10.0.0.3")
+	assert.NoError(t, err)
+}
+
+func TestLoginThrottle_ReleaseRefundsAttempt(t *testing.T) {
+	throttle := NewLoginThrottle(nil)
+	throttle.ip = ThrottlePolicy{
+		FreeAttempts:    2,
+		LockoutAttempts: 10,
+		BaseDelay:       time.Minute,
+		MaxDelay:        time.Minute,
+		LockoutDuration: time.Hour,
+		Window:          time.Hour,
+	}
+
+	// Successful logins from a shared address do not use up its budget
+	for i := 0; i < 5; i++ {
+		attempt, err := throttle.Reserve(fmt.Sprintf("player%d", i), "10.0.0.9")
+		require.NoError(t, err)
+		attempt.Release()
+	}
+
+	failLogin(t, throttle, "mallory", "10.0.0.9")
+	_, err := throttle.Reserve("mallory", "10.0.0.9")
+	assert.NoError(t, err)
+}
+
+func TestLoginThrottle_ConcurrentBurst(t *testing.T) {
+	throttle := NewLoginThrottle(nil)
+	throttle.username.FreeAttempts = 3
+
+	var allowed atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if attempt, err := throttle.Reserve("victim", ""); err == nil {
+				allowed.Add(1)
+				attempt.Failed()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The attempts are charged before the password is checked, so the burst cannot overrun the budget
+	assert.Equal(t, int64(3), allowed.Load())
+}
+
+func TestLoginThrottle_IPLimit(t *testing.T) {
+	throttle := NewLoginThrottle(nil)
+	throttle.ip = ThrottlePolicy{
+		FreeAttempts:    2,
+		LockoutAttempts: 10,
+		BaseDelay:       time.Minute,
+		MaxDelay:        time.Minute,
+		LockoutDuration: time.Hour,
+		Window:          time.Hour,
+	}
+
+	// Spraying different usernames from one IP still trips the IP counter
+	failLogin(t, throttle, "alice", "10.0.0.9")
+	failLogin(t, throttle, "bob", "10.0.0.9")
+
+	_, err := throttle.Reserve("carol", "10.0.0.9")
+	assert.ErrorIs(t, err, ErrTooManyLoginAttempts)
+	_, err = throttle.Reserve("carol", "10.0.0.10")
+	assert.NoError(t, err)
+}
+
+func TestLoginThrottle_DegradedWithoutRedis(t *testing.T) {
+	// Nothing listens on this port, so every Redis call fails fast
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer client.Close()
+
+	throttle := NewLoginThrottle(client)
+	throttle.username.FreeAttempts = 1
+
+	failLogin(t, throttle, "player", "10.0.0.1")
+
+	// Protection continues with in-process counters
+	_, err := throttle.Reserve("player", "10.0.0.1")
+	assert.ErrorIs(t, err, ErrTooManyLoginAttempts)
+}
+
+func TestPlayerService_Login_LockoutIsAudited(t *testing.T) {
+	db := setupAccountTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AuditLog{}))
+
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
+	service.loginThrottle.username.FreeAttempts = 2
+	service.loginThrottle.username.LockoutAttempts = 2
+
+	_, err := service.CreatePlayer(CreatePlayerRequest{
+		Username: "target",
+		Email:    "target@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = service.Login(LoginRequest{Username: "target", Password: "wrong", IPAddress: "203.0.113.7"})
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+
+	// Even the right password is refused while locked out
+	_, err = service.Login(LoginRequest{Username: "target", Password: "password123", IPAddress: "203.0.113.7"})
+	assert.ErrorIs(t, err, ErrTooManyLoginAttempts)
+
+	var logs []models.AuditLog
+	require.NoError(t, db.Find(&logs).Error)
+	require.Len(t, logs, 1)
+	assert.Equal(t, models.AuditActionLoginLockout, logs[0].Action)
+	assert.Equal(t, "username", logs[0].TargetType)
+	assert.Equal(t, "target", logs[0].TargetID)
+	assert.Equal(t, "203.0.113.7", logs[0].IPAddress)
+	assert.Contains(t, logs[0].Details, `"failures":2`)
+}
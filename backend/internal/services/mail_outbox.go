@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/mail"
+	"zombie-car-game-backend/internal/models"
+)
+
+const (
+	// outboxBatchSize bounds how many emails one poll delivers
+	outboxBatchSize = 20
+	// outboxMaxAttempts is how often delivery is retried before a message is marked failed
+	outboxMaxAttempts = 5
+	// outboxSendTimeout bounds a single delivery attempt
+	outboxSendTimeout = 30 * time.Second
+	// outboxStaleAfter releases messages claimed by a worker that died mid-send
+	outboxStaleAfter = 10 * time.Minute
+)
+
+// enqueueEmail queues an email inside the caller's transaction so it is only
+// sent if the surrounding change commits.
+func enqueueEmail(tx *gorm.DB, recipient, subject, body string) error {
+	email := models.OutboxEmail{
+		Recipient:     recipient,
+		Subject:       subject,
+		Body:          body,
+		Status:        models.OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := tx.Create(&email).Error; err != nil {
+		return fmt.Errorf("failed to queue email: %w", err)
+	}
+	return nil
+}
+
+// MailOutbox delivers queued emails through a mail sender
+type MailOutbox struct {
+	db     *gorm.DB
+	sender mail.Sender
+}
+
+// NewMailOutbox creates a new mail outbox worker
+func NewMailOutbox(db *gorm.DB, sender mail.Sender) *MailOutbox {
+	return &MailOutbox{
+		db:     db,
+		sender: sender,
+	}
+}
+
+// Run polls the outbox until ctx is cancelled
+func (o *MailOutbox) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := o.ProcessPending(ctx); err != nil {
+			slog.WarnContext(ctx, "Mail outbox processing failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessPending delivers due emails and returns how many were sent
+func (o *MailOutbox) ProcessPending(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	var due []models.OutboxEmail
+	if err := o.db.WithContext(ctx).
+		Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND updated_at < ?)",
+			models.OutboxStatusPending, now, models.OutboxStatusSending, now.Add(-outboxStaleAfter)).
+		Order("id").
+		Limit(outboxBatchSize).
+		Find(&due).Error; err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+
+	sent := 0
+	for i := range due {
+		if ctx.Err() != nil {
+			break
+		}
+
+		claimed, err := o.claim(&due[i])
+		if err != nil {
+			return sent, err
+		}
+		if !claimed {
+			continue // another worker got it
+		}
+
+		if o.deliver(ctx, &due[i]) {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// claim marks a message as sending; only one worker wins the conditional update
+func (o *MailOutbox) claim(email *models.OutboxEmail) (bool, error) {
+	result := o.db.Model(&models.OutboxEmail{}).
+		Where("id = ? AND status = ? AND attempts = ?", email.ID, email.Status, email.Attempts).
+		Updates(map[string]interface{}{
+			"status":     models.OutboxStatusSending,
+			"attempts":   email.Attempts + 1,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim email: %w", result.Error)
+	}
+	email.Attempts++
+	return result.RowsAffected == 1, nil
+}
+
+// deliver sends a claimed message and records the outcome
+func (o *MailOutbox) deliver(ctx context.Context, email *models.OutboxEmail) bool {
+	sendCtx, cancel := context.WithTimeout(ctx, outboxSendTimeout)
+	defer cancel()
+
+	err := o.sender.Send(sendCtx, mail.Message{
+		To:      email.Recipient,
+		Subject: email.Subject,
+		Body:    email.Body,
+	})
+
+	updates := map[string]interface{}{"updated_at": time.Now()}
+	switch {
+	case err == nil:
+		now := time.Now()
+		updates["status"] = models.OutboxStatusSent
+		updates["sent_at"] = &now
+		updates["last_error"] = ""
+	case email.Attempts >= outboxMaxAttempts:
+		updates["status"] = models.OutboxStatusFailed
+		updates["last_error"] = truncate(err.Error(), 500)
+	default:
+		// Exponential backoff: 1m, 2m, 4m, ...
+		backoff := time.Minute << (email.Attempts - 1)
+		updates["status"] = models.OutboxStatusPending
+		updates["next_attempt_at"] = time.Now().Add(backoff)
+		updates["last_error"] = truncate(err.Error(), 500)
+	}
+
+	if dbErr := o.db.Model(&models.OutboxEmail{}).Where("id = ?", email.ID).Updates(updates).Error; dbErr != nil {
+		slog.WarnContext(ctx, "Mail outbox failed to record delivery", "email_id", email.ID, "error", dbErr)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "Mail outbox failed to send email", "email_id", email.ID, "attempt", email.Attempts, "error", err)
+	}
+
+	return err == nil
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+)
+
+func TestPlayerService_Login_ByEmailOrUsername(t *testing.T) {
+	db := setupAccountTestDB(t)
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
+
+	created, err := service.CreatePlayer(CreatePlayerRequest{
+		Username: "RoadWarrior",
+		Email:    "Warrior@Example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+
+	for _, identifier := range []string{"RoadWarrior", "roadwarrior", "warrior@example.com", "WARRIOR@EXAMPLE.COM"} {
+		response, err := service.Login(LoginRequest{Identifier: identifier, Password: "password123"})
+		require.NoError(t, err, identifier)
+		assert.Equal(t, created.Player.ID, response.Player.ID)
+	}
+
+	// Older clients sending "username" can use an email too
+	response, err := service.Login(LoginRequest{Username: "warrior@example.com", Password: "password123"})
+	require.NoError(t, err)
+	assert.Equal(t, created.Player.ID, response.Player.ID)
+
+	_, err = service.Login(LoginRequest{Identifier: "warrior@example.com", Password: "wrong"})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestPlayerService_CreatePlayer_CaseInsensitiveDuplicates(t *testing.T) {
+	db := setupAccountTestDB(t)
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
+
+	_, err := service.CreatePlayer(CreatePlayerRequest{Username: "Driver", Email: "driver@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	_, err = service.CreatePlayer(CreatePlayerRequest{Username: "driver", Email: "other@example.com", Password: "password123"})
+	assert.ErrorIs(t, err, ErrUsernameExists)
+
+	_, err = service.CreatePlayer(CreatePlayerRequest{Username: "another", Email: "DRIVER@example.com", Password: "password123"})
+	assert.ErrorIs(t, err, ErrEmailExists)
+}
+
+func TestPlayerService_Login_UpgradesBcryptHash(t *testing.T) {
+	db := setupAccountTestDB(t)
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	player := &models.Player{
+		Username:     "veteran",
+		Email:        "veteran@example.com",
+		PasswordHash: string(legacy),
+	}
+	require.NoError(t, db.Create(player).Error)
+
+	_, err = service.Login(LoginRequest{Identifier: "veteran", Password: "password123"})
+	require.NoError(t, err)
+
+	var stored models.Player
+	require.NoError(t, db.First(&stored, player.ID).Error)
+	assert.Regexp(t, `^\$argon2id\$`, stored.PasswordHash)
+
+	// The upgraded hash keeps working
+	_, err = service.Login(LoginRequest{Identifier: "veteran", Password: "password123"})
+	assert.NoError(t, err)
+}
@@ -1,12 +1,20 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
 
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/cache"
 	"zombie-car-game-backend/internal/models"
+	"zombie-car-game-backend/internal/tracing"
 )
 
 var (
@@ -17,20 +25,59 @@ var (
 	ErrInsufficientFunds  = errors.New("insufficient funds")
 )
 
+// AccountConfig holds the account settings the player and account services share
+type AccountConfig struct {
+	// RefreshTokenTTL is how long a login stays alive without activity
+	RefreshTokenTTL time.Duration
+	// AppBaseURL is the frontend that links in account emails point to
+	AppBaseURL string
+	// TOTPIssuer is the account name shown in authenticator apps
+	TOTPIssuer string
+}
+
 // PlayerService handles player-related operations
 type PlayerService struct {
 	db              *gorm.DB
 	passwordService *auth.PasswordService
 	jwtService      *auth.JWTService
+	tokenService    *TokenService
+	loginThrottle   *LoginThrottle
+	appBaseURL      string
+	totpIssuer      string
+	// ctx is set by WithContext and parents the spans of the service's methods
+	ctx context.Context
 }
 
 // NewPlayerService creates a new player service
-func NewPlayerService(db *gorm.DB) *PlayerService {
+func NewPlayerService(db *gorm.DB, jwtService *auth.JWTService, cfg AccountConfig) *PlayerService {
 	return &PlayerService{
 		db:              db,
 		passwordService: auth.NewPasswordService(),
-		jwtService:      auth.NewJWTService(),
+		jwtService:      jwtService,
+		tokenService:    NewTokenService(db, jwtService, cfg.RefreshTokenTTL),
+		loginThrottle:   NewLoginThrottle(cache.GetClient()),
+		appBaseURL:      cfg.AppBaseURL,
+		totpIssuer:      cfg.TOTPIssuer,
+	}
+}
+
+// WithContext returns a copy of the service whose queries, and the spans of its
+// methods, belong to ctx, typically the request context
+func (s *PlayerService) WithContext(ctx context.Context) *PlayerService {
+	copied := *s
+	copied.ctx = ctx
+	copied.db = s.db.WithContext(ctx)
+	return &copied
+}
+
+// startSpan starts a span for method and returns a copy of the service scoped to it
+func (s *PlayerService) startSpan(method string) (*PlayerService, trace.Span) {
+	ctx := s.ctx
+	if ctx == nil {
+		ctx = context.Background()
 	}
+	ctx, span := tracing.Start(ctx, "PlayerService."+method)
+	return s.WithContext(ctx), span
 }
 
 // CreatePlayerRequest represents the request to create a new player
@@ -38,30 +85,45 @@ type CreatePlayerRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=50"`
 	Email    string `json:"email" binding:"required,email,max=100"`
 	Password string `json:"password" binding:"required,min=6"`
+	// IPAddress and UserAgent are filled in by the handler to describe the device session
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
 // LoginRequest represents the login request
 type LoginRequest struct {
-	Username string `json:"username" binding:"required"`
+	// Identifier is a username or an email address, matched case-insensitively
+	Identifier string `json:"identifier" binding:"required_without=Username"`
+	// Username is kept for older clients; it may also hold an email address
+	Username string `json:"username" binding:"required_without=Identifier"`
 	Password string `json:"password" binding:"required"`
+	// IPAddress is filled in by the handler for brute-force protection and the device session
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
-// AuthResponse represents the authentication response
+// AuthResponse represents the authentication response.
+// When the player has 2FA enabled, a password login only returns a challenge token
+// that must be completed with a code before any access token is issued.
 type AuthResponse struct {
-	Token  string        `json:"token"`
-	Player *models.Player `json:"player"`
+	Token             string         `json:"token,omitempty"`
+	RefreshToken      string         `json:"refresh_token,omitempty"`
+	ExpiresIn         int64          `json:"expires_in"`
+	Player            *models.Player `json:"player,omitempty"`
+	TwoFactorRequired bool           `json:"two_factor_required,omitempty"`
+	ChallengeToken    string         `json:"challenge_token,omitempty"`
 }
 
 // CreatePlayer creates a new player account
 func (s *PlayerService) CreatePlayer(req CreatePlayerRequest) (*AuthResponse, error) {
-	// Check if username already exists
+	// Check if username already exists; names differing only in case would make login ambiguous
 	var existingPlayer models.Player
-	if err := s.db.Where("username = ?", req.Username).First(&existingPlayer).Error; err == nil {
+	if err := s.db.Where("LOWER(username) = ?", strings.ToLower(req.Username)).First(&existingPlayer).Error; err == nil {
 		return nil, ErrUsernameExists
 	}
 
 	// Check if email already exists
-	if err := s.db.Where("email = ?", req.Email).First(&existingPlayer).Error; err == nil {
+	if err := s.db.Where("LOWER(email) = ?", strings.ToLower(req.Email)).First(&existingPlayer).Error; err == nil {
 		return nil, ErrEmailExists
 	}
 
@@ -80,51 +142,145 @@ func (s *PlayerService) CreatePlayer(req CreatePlayerRequest) (*AuthResponse, er
 		Level:        1,
 	}
 
-	if err := s.db.Create(&player).Error; err != nil {
-		return nil, fmt.Errorf("failed to create player: %w", err)
-	}
-
-	// Generate JWT token
-	token, err := s.jwtService.GenerateToken(player.ID, player.Username)
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&player).Error; err != nil {
+			return fmt.Errorf("failed to create player: %w", err)
+		}
+		return queueEmailVerification(tx, &player, s.appBaseURL)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, err
 	}
 
-	return &AuthResponse{
-		Token:  token,
-		Player: &player,
-	}, nil
+	return s.newAuthResponse(&player, DeviceInfo{UserAgent: req.UserAgent, IPAddress: req.IPAddress})
 }
 
-// Login authenticates a player and returns a JWT token
+// Login authenticates a player by username or email and returns a JWT token
 func (s *PlayerService) Login(req LoginRequest) (*AuthResponse, error) {
-	var player models.Player
-	if err := s.db.Where("username = ?", req.Username).First(&player).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrInvalidCredentials
-		}
-		return nil, fmt.Errorf("database error: %w", err)
+	identifier := strings.TrimSpace(req.Identifier)
+	if identifier == "" {
+		identifier = strings.TrimSpace(req.Username)
+	}
+
+	player, attempt, err := s.reserveLogin(identifier, req.IPAddress)
+	if err != nil {
+		return nil, err
+	}
+	if player == nil {
+		s.recordLoginFailure(attempt, identifier, req.IPAddress)
+		return nil, ErrInvalidCredentials
 	}
 
 	// Verify password
 	if err := s.passwordService.VerifyPassword(player.PasswordHash, req.Password); err != nil {
+		s.recordLoginFailure(attempt, identifier, req.IPAddress)
 		return nil, ErrInvalidCredentials
 	}
+	attempt.Release()
+
+	s.upgradePasswordHash(player, req.Password)
+
+	// The throttle keeps counting until the second factor is in, so code guesses share its budget
+	if player.TwoFactorEnabled {
+		return s.newTwoFactorChallenge(player)
+	}
 
-	// Generate JWT token
-	token, err := s.jwtService.GenerateToken(player.ID, player.Username)
+	s.loginThrottle.RecordSuccess(player.Username)
+
+	return s.newAuthResponse(player, DeviceInfo{UserAgent: req.UserAgent, IPAddress: req.IPAddress})
+}
+
+// reserveLogin looks up the player behind an identifier and charges the attempt
+// to the login throttle before any password is checked, so throttled attempts
+// cost no hashing. The player is nil if nobody matches.
+func (s *PlayerService) reserveLogin(identifier, ipAddress string) (*models.Player, *LoginAttempt, error) {
+	player, err := s.findByIdentifier(identifier)
+	if err != nil && !errors.Is(err, ErrPlayerNotFound) {
+		return nil, nil, err
+	}
+
+	// Throttle per account, not per spelling, so username and email share one budget
+	subject := identifier
+	if player != nil {
+		subject = player.Username
+	}
+	attempt, err := s.loginThrottle.Reserve(subject, ipAddress)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, nil, err
 	}
+	return player, attempt, nil
+}
 
-	return &AuthResponse{
-		Token:  token,
-		Player: &player,
-	}, nil
+// findByIdentifier looks a player up by username or email, ignoring case.
+// If the identifier matches one player's username and another's email, the username wins.
+func (s *PlayerService) findByIdentifier(identifier string) (*models.Player, error) {
+	lowered := strings.ToLower(identifier)
+
+	var candidates []models.Player
+	if err := s.db.Where("LOWER(username) = ? OR LOWER(email) = ?", lowered, lowered).
+		Limit(2).Find(&candidates).Error; err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if len(candidates) == 0 {
+		return nil, ErrPlayerNotFound
+	}
+	for i := range candidates {
+		if strings.ToLower(candidates[i].Username) == lowered {
+			return &candidates[i], nil
+		}
+	}
+	return &candidates[0], nil
+}
+
+// upgradePasswordHash re-hashes a verified password stored with an outdated algorithm or cost.
+// Failures are logged only; the player is already authenticated.
+func (s *PlayerService) upgradePasswordHash(player *models.Player, password string) {
+	if !s.passwordService.NeedsRehash(player.PasswordHash) {
+		return
+	}
+
+	hashedPassword, err := s.passwordService.HashPassword(password)
+	if err != nil {
+		slog.Warn("Failed to rehash password", "player_id", player.ID, "error", err)
+		return
+	}
+
+	// Conditional on the old hash so a concurrent password change is never overwritten
+	if err := s.db.Model(&models.Player{}).
+		Where("id = ? AND password_hash = ?", player.ID, player.PasswordHash).
+		Update("password_hash", hashedPassword).Error; err != nil {
+		slog.Warn("Failed to store upgraded password hash", "player_id", player.ID, "error", err)
+		return
+	}
+	player.PasswordHash = hashedPassword
+}
+
+// recordLoginFailure keeps a reserved attempt as a failure and audits any lockout it triggers
+func (s *PlayerService) recordLoginFailure(attempt *LoginAttempt, identifier, ipAddress string) {
+	for _, event := range attempt.Failed() {
+		err := recordAudit(s.db, AuditEntry{
+			Action:     models.AuditActionLoginLockout,
+			TargetType: event.Subject,
+			TargetID:   event.Value,
+			IPAddress:  ipAddress,
+			Details: map[string]interface{}{
+				"failures":        event.Failures,
+				"lockout_seconds": int64(event.Duration.Seconds()),
+				"identifier":      identifier,
+			},
+		})
+		if err != nil {
+			slog.Warn("Failed to audit login lockout", "error", err)
+		}
+	}
 }
 
 // GetPlayer retrieves a player by ID
 func (s *PlayerService) GetPlayer(playerID uint) (*models.Player, error) {
+	s, span := s.startSpan("GetPlayer")
+	defer span.End()
+
 	var player models.Player
 	if err := s.db.Preload("OwnedVehicles").Preload("LevelProgress").First(&player, playerID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -135,30 +291,65 @@ func (s *PlayerService) GetPlayer(playerID uint) (*models.Player, error) {
 	return &player, nil
 }
 
-// UpdatePlayerCurrency updates a player's currency
+// UpdatePlayerCurrency updates a player's currency and records the change in the audit log
 func (s *PlayerService) UpdatePlayerCurrency(playerID uint, amount int) error {
-	var player models.Player
-	if err := s.db.First(&player, playerID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrPlayerNotFound
-		}
-		return fmt.Errorf("database error: %w", err)
+	s, span := s.startSpan("UpdatePlayerCurrency")
+	defer span.End()
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return updateCurrency(tx, playerID, amount, "player_update", "")
+	})
+}
+
+// updateCurrency adds amount to a player's currency inside tx, recording it in
+// the wallet ledger under reason and reference, and audits the change
+func updateCurrency(tx *gorm.DB, playerID uint, amount int, reason, reference string) error {
+	before, after, err := changeCurrency(tx, playerID, amount, reason, reference)
+	if err != nil {
+		return err
 	}
 
-	newCurrency := player.Currency + amount
-	if newCurrency < 0 {
-		return ErrInsufficientFunds
+	return recordAudit(tx, AuditEntry{
+		Action:     models.AuditActionCurrencyUpdated,
+		ActorID:    &playerID,
+		TargetType: "player",
+		TargetID:   strconv.FormatUint(uint64(playerID), 10),
+		Before:     map[string]interface{}{"currency": before},
+		After:      map[string]interface{}{"currency": after},
+		Details:    map[string]interface{}{"amount": amount},
+	})
+}
+
+// changeCurrency adds amount to a player's currency inside tx, recording it in
+// the wallet ledger under reason and reference, and returns the balance before
+// and after. The balance may not go below zero.
+func changeCurrency(tx *gorm.DB, playerID uint, amount int, reason, reference string) (int, int, error) {
+	var player models.Player
+	if err := lockPlayer(tx, playerID, &player); err != nil {
+		return 0, 0, err
 	}
 
-	if err := s.db.Model(&player).Update("currency", newCurrency).Error; err != nil {
-		return fmt.Errorf("failed to update currency: %w", err)
+	before := player.Currency
+	after := before + amount
+	if after < 0 {
+		return 0, 0, ErrInsufficientFunds
+	}
+	if amount == 0 {
+		return before, after, nil
 	}
 
-	return nil
+	after, err := applyWalletChange(tx, playerID, models.CurrencySoft, amount, reason, reference)
+	if err != nil {
+		return 0, 0, err
+	}
+	return before, after, nil
 }
 
 // UpdatePlayerLevel updates a player's level
 func (s *PlayerService) UpdatePlayerLevel(playerID uint, level int) error {
+	s, span := s.startSpan("UpdatePlayerLevel")
+	defer span.End()
+
 	if err := s.db.Model(&models.Player{}).Where("id = ?", playerID).Update("level", level).Error; err != nil {
 		return fmt.Errorf("failed to update level: %w", err)
 	}
@@ -167,7 +358,15 @@ func (s *PlayerService) UpdatePlayerLevel(playerID uint, level int) error {
 
 // UpdatePlayerScore updates a player's total score
 func (s *PlayerService) UpdatePlayerScore(playerID uint, scoreToAdd int64) error {
-	if err := s.db.Model(&models.Player{}).Where("id = ?", playerID).
+	s, span := s.startSpan("UpdatePlayerScore")
+	defer span.End()
+
+	return addScore(s.db, playerID, scoreToAdd)
+}
+
+// addScore adds to a player's total score using db, which may be a transaction
+func addScore(db *gorm.DB, playerID uint, scoreToAdd int64) error {
+	if err := db.Model(&models.Player{}).Where("id = ?", playerID).
 		Update("total_score", gorm.Expr("total_score + ?", scoreToAdd)).Error; err != nil {
 		return fmt.Errorf("failed to update score: %w", err)
 	}
@@ -176,11 +375,15 @@ func (s *PlayerService) UpdatePlayerScore(playerID uint, scoreToAdd int64) error
 
 // GetPlayerProgress retrieves a player's progress including owned vehicles and level progress
 func (s *PlayerService) GetPlayerProgress(playerID uint) (*models.Player, error) {
+	s, span := s.startSpan("GetPlayerProgress")
+	defer span.End()
+
 	var player models.Player
 	if err := s.db.Preload("OwnedVehicles").
 		Preload("LevelProgress").
+		Preload("Inventory").
 		Preload("GameSessions", func(db *gorm.DB) *gorm.DB {
-			return db.Order("created_at DESC").Limit(10) // Last 10 sessions
+			return db.Order("started_at DESC").Limit(10) // Last 10 sessions
 		}).
 		First(&player, playerID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -191,27 +394,78 @@ func (s *PlayerService) GetPlayerProgress(playerID uint) (*models.Player, error)
 	return &player, nil
 }
 
-// RefreshToken generates a new token for the player
-func (s *PlayerService) RefreshToken(oldToken string) (*AuthResponse, error) {
-	claims, err := s.jwtService.ValidateToken(oldToken)
+// RefreshToken rotates a refresh token and returns a new token pair
+func (s *PlayerService) RefreshToken(refreshToken string, device DeviceInfo) (*AuthResponse, error) {
+	pair, player, err := s.tokenService.RotateRefreshToken(refreshToken, device)
 	if err != nil {
-		return nil, fmt.Errorf("invalid token: %w", err)
+		return nil, err
 	}
 
+	// Issuing a ban revokes refresh tokens; this covers one rotated concurrently with the ban
+	if err := s.checkAccessSanction(player.ID); err != nil {
+		return nil, err
+	}
+
+	s.touchLastActive(player.ID)
+
 	// Get updated player data
-	player, err := s.GetPlayer(claims.PlayerID)
+	fullPlayer, err := s.GetPlayer(player.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Generate new token
-	token, err := s.jwtService.GenerateToken(player.ID, player.Username)
+	return &AuthResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresIn:    pair.ExpiresIn,
+		Player:       fullPlayer,
+	}, nil
+}
+
+// Logout revokes the presented access token and the refresh token family it belongs to.
+// Either argument may be empty; unknown refresh tokens are ignored so logout is idempotent.
+func (s *PlayerService) Logout(refreshToken string, claims *auth.Claims) error {
+	if claims != nil {
+		if err := s.tokenService.RevokeAccessToken(claims); err != nil {
+			return err
+		}
+	}
+
+	if refreshToken != "" {
+		if err := s.tokenService.RevokeRefreshToken(refreshToken); err != nil && !errors.Is(err, ErrInvalidRefreshToken) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newAuthResponse issues a fresh token pair for a player that just authenticated
+func (s *PlayerService) newAuthResponse(player *models.Player, device DeviceInfo) (*AuthResponse, error) {
+	if err := s.checkAccessSanction(player.ID); err != nil {
+		return nil, err
+	}
+
+	pair, err := s.tokenService.IssueTokens(player, device)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, err
 	}
 
+	s.touchLastActive(player.ID)
+
 	return &AuthResponse{
-		Token:  token,
-		Player: player,
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresIn:    pair.ExpiresIn,
+		Player:       player,
 	}, nil
-}
\ No newline at end of file
+}
+
+// touchLastActive records that a player just signed in or renewed a session.
+// It drives inactive guest cleanup, so a failure is logged but never blocks sign-in.
+func (s *PlayerService) touchLastActive(playerID uint) {
+	if err := s.db.Model(&models.Player{}).Where("id = ?", playerID).
+		UpdateColumn("last_active_at", time.Now()).Error; err != nil {
+		slog.Warn("Failed to record player activity", "player_id", playerID, "error", err)
+	}
+}
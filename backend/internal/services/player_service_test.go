@@ -5,32 +5,19 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	"zombie-car-game-backend/internal/auth"
 	"zombie-car-game-backend/internal/models"
 )
 
 func setupTestDB(t *testing.T) *gorm.DB {
-	// Skip tests if CGO is not available
-	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
-	})
-	if err != nil {
-		t.Skip("SQLite requires CGO, skipping database tests")
-		return nil
-	}
-
-	// Auto migrate the schema
-	err = db.AutoMigrate(&models.Player{}, &models.OwnedVehicle{}, &models.GameSession{}, &models.LevelProgress{})
-	require.NoError(t, err)
-
-	return db
+	return openTestDB(t, &models.Player{}, &models.OwnedVehicle{}, &models.GameSession{}, &models.LevelProgress{}, &models.InventoryItem{}, &models.RefreshToken{}, &models.DeviceSession{}, &models.Sanction{},
+		&models.AccountToken{}, &models.OutboxEmail{}, &models.AuditLog{}, &models.WalletTransaction{})
 }
 
 func TestPlayerService_CreatePlayer(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewPlayerService(db)
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 
 	req := CreatePlayerRequest{
 		Username: "testuser",
@@ -50,7 +37,7 @@ func TestPlayerService_CreatePlayer(t *testing.T) {
 
 func TestPlayerService_CreatePlayer_DuplicateUsername(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewPlayerService(db)
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 
 	req := CreatePlayerRequest{
 		Username: "testuser",
@@ -71,7 +58,7 @@ func TestPlayerService_CreatePlayer_DuplicateUsername(t *testing.T) {
 
 func TestPlayerService_CreatePlayer_DuplicateEmail(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewPlayerService(db)
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 
 	req := CreatePlayerRequest{
 		Username: "testuser",
@@ -92,7 +79,7 @@ func TestPlayerService_CreatePlayer_DuplicateEmail(t *testing.T) {
 
 func TestPlayerService_Login(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewPlayerService(db)
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 
 	// Create a player first
 	createReq := CreatePlayerRequest{
@@ -118,7 +105,7 @@ func TestPlayerService_Login(t *testing.T) {
 
 func TestPlayerService_Login_InvalidCredentials(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewPlayerService(db)
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 
 	// Create a player first
 	createReq := CreatePlayerRequest{
@@ -148,7 +135,7 @@ func TestPlayerService_Login_InvalidCredentials(t *testing.T) {
 
 func TestPlayerService_GetPlayer(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewPlayerService(db)
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 
 	// Create a player first
 	createReq := CreatePlayerRequest{
@@ -168,7 +155,7 @@ func TestPlayerService_GetPlayer(t *testing.T) {
 
 func TestPlayerService_GetPlayer_NotFound(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewPlayerService(db)
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 
 	// Try to get non-existent player
 	_, err := service.GetPlayer(999)
@@ -178,7 +165,7 @@ func TestPlayerService_GetPlayer_NotFound(t *testing.T) {
 
 func TestPlayerService_UpdatePlayerCurrency(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewPlayerService(db)
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 
 	// Create a player first
 	createReq := CreatePlayerRequest{
@@ -207,11 +194,20 @@ func TestPlayerService_UpdatePlayerCurrency(t *testing.T) {
 	player, err = service.GetPlayer(playerID)
 	require.NoError(t, err)
 	assert.Equal(t, 1300, player.Currency) // 1500 - 200
+
+	// Both changes are in the wallet ledger
+	var entries []models.WalletTransaction
+	require.NoError(t, db.Where("player_id = ?", playerID).Order("id").Find(&entries).Error)
+	require.Len(t, entries, 2)
+	assert.Equal(t, 500, entries[0].Amount)
+	assert.Equal(t, 1500, entries[0].BalanceAfter)
+	assert.Equal(t, -200, entries[1].Amount)
+	assert.Equal(t, 1300, entries[1].BalanceAfter)
 }
 
 func TestPlayerService_UpdatePlayerCurrency_InsufficientFunds(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewPlayerService(db)
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 
 	// Create a player first
 	createReq := CreatePlayerRequest{
@@ -232,7 +228,7 @@ func TestPlayerService_UpdatePlayerCurrency_InsufficientFunds(t *testing.T) {
 
 func TestPlayerService_UpdatePlayerLevel(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewPlayerService(db)
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 
 	// Create a player first
 	createReq := CreatePlayerRequest{
@@ -257,7 +253,7 @@ func TestPlayerService_UpdatePlayerLevel(t *testing.T) {
 
 func TestPlayerService_UpdatePlayerScore(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewPlayerService(db)
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 
 	// Create a player first
 	createReq := CreatePlayerRequest{
@@ -290,7 +286,7 @@ func TestPlayerService_UpdatePlayerScore(t *testing.T) {
 
 func TestPlayerService_GetPlayerProgress(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewPlayerService(db)
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 
 	// Create a player first
 	createReq := CreatePlayerRequest{
@@ -314,7 +310,7 @@ func TestPlayerService_GetPlayerProgress(t *testing.T) {
 
 func TestPlayerService_RefreshToken(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewPlayerService(db)
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 
 	// Create a player first
 	createReq := CreatePlayerRequest{
@@ -326,10 +322,11 @@ func TestPlayerService_RefreshToken(t *testing.T) {
 	require.NoError(t, err)
 
 	// Refresh token
-	newResponse, err := service.RefreshToken(response.Token)
+	newResponse, err := service.RefreshToken(response.RefreshToken, DeviceInfo{})
 	require.NoError(t, err)
 	assert.NotEmpty(t, newResponse.Token)
 	assert.NotEqual(t, response.Token, newResponse.Token)
+	assert.NotEqual(t, response.RefreshToken, newResponse.RefreshToken)
 	assert.Equal(t, response.Player.ID, newResponse.Player.ID)
 	assert.Equal(t, response.Player.Username, newResponse.Player.Username)
 }
\ No newline at end of file
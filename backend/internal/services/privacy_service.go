@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+)
+
+var (
+	ErrDeletionNotScheduled = errors.New("account deletion is not scheduled")
+)
+
+const (
+	// accountPurgeBatchSize bounds how many accounts one purge pass anonymizes
+	accountPurgeBatchSize = 100
+	// deletedEmailDomain is reserved (RFC 2606) so anonymized addresses can never receive mail
+	deletedEmailDomain = "deleted.invalid"
+)
+
+// DeleteAccountRequest confirms an account deletion. Guests have no password and may omit it.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+// PlayerDataExport is the archive returned for a personal data export request
+type PlayerDataExport struct {
+	ExportedAt         time.Time                  `json:"exported_at"`
+	Player             models.Player              `json:"player"`
+	OwnedVehicles      []models.OwnedVehicle      `json:"owned_vehicles"`
+	GameSessions       []models.GameSession       `json:"game_sessions"`
+	LevelProgress      []models.LevelProgress     `json:"level_progress"`
+	Inventory          []models.InventoryItem     `json:"inventory"`
+	WalletTransactions []models.WalletTransaction `json:"wallet_transactions"`
+	Purchases          []models.Purchase          `json:"purchases"`
+	StoreAccounts      []models.StoreAccount      `json:"store_accounts"`
+	PromoRedemptions   []models.PromoRedemption   `json:"promo_redemptions"`
+	DeviceSessions     []models.DeviceSession     `json:"device_sessions"`
+}
+
+// PrivacyService handles personal data exports and account deletion.
+//
+// Deleting an account only schedules it; once the grace period has passed the
+// account is anonymized in place. Credentials, tokens, linked store accounts,
+// queued mail and stored IP addresses are purged, while sessions, progress, vehicles and the wallet ledger
+// stay attached to the anonymized player so aggregate statistics remain correct.
+type PrivacyService struct {
+	db              *gorm.DB
+	passwordService *auth.PasswordService
+	jwtService      *auth.JWTService
+	gracePeriod     time.Duration
+}
+
+// NewPrivacyService creates a new privacy service
+func NewPrivacyService(db *gorm.DB, jwtService *auth.JWTService, gracePeriod time.Duration) *PrivacyService {
+	return &PrivacyService{
+		db:              db,
+		passwordService: auth.NewPasswordService(),
+		jwtService:      jwtService,
+		gracePeriod:     gracePeriod,
+	}
+}
+
+// ExportPlayerData collects everything stored about a player
+func (s *PrivacyService) ExportPlayerData(playerID uint) (*PlayerDataExport, error) {
+	export := PlayerDataExport{ExportedAt: time.Now().UTC()}
+
+	if err := s.db.First(&export.Player, playerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPlayerNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	queries := []struct {
+		dest  interface{}
+		order string
+	}{
+		{&export.OwnedVehicles, "id"},
+		{&export.GameSessions, "started_at"},
+		{&export.LevelProgress, "id"},
+		{&export.Inventory, "id"},
+		{&export.WalletTransactions, "id"},
+		{&export.Purchases, "id"},
+		{&export.StoreAccounts, "id"},
+		{&export.PromoRedemptions, "id"},
+		{&export.DeviceSessions, "created_at"},
+	}
+	for _, q := range queries {
+		if err := s.db.Where("player_id = ?", playerID).Order(q.order).Find(q.dest).Error; err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+	}
+
+	return &export, nil
+}
+
+// RequestDeletion schedules the player's account for anonymization after the grace
+// period. Repeating the request keeps the original date.
+func (s *PrivacyService) RequestDeletion(playerID uint, req DeleteAccountRequest, ipAddress string) (time.Time, error) {
+	var dueAt time.Time
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var player models.Player
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&player, playerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPlayerNotFound
+			}
+			return fmt.Errorf("database error: %w", err)
+		}
+
+		if !player.IsGuest {
+			if err := s.passwordService.VerifyPassword(player.PasswordHash, req.Password); err != nil {
+				return ErrInvalidCredentials
+			}
+		}
+
+		if player.DeletionDueAt != nil {
+			dueAt = *player.DeletionDueAt
+			return nil
+		}
+
+		dueAt = time.Now().Add(s.gracePeriod)
+		if err := tx.Model(&player).Update("deletion_due_at", dueAt).Error; err != nil {
+			return fmt.Errorf("failed to schedule deletion: %w", err)
+		}
+
+		if err := recordAudit(tx, AuditEntry{
+			Action:     models.AuditActionDeletionRequested,
+			ActorID:    &player.ID,
+			TargetType: "player",
+			TargetID:   strconv.FormatUint(uint64(player.ID), 10),
+			Details:    map[string]interface{}{"due_at": dueAt.UTC()},
+			IPAddress:  ipAddress,
+		}); err != nil {
+			return err
+		}
+
+		if player.IsGuest {
+			return nil
+		}
+
+		body := fmt.Sprintf("Hi %s,\n\n"+
+			"We received a request to delete your Zombie Car Game account.\n"+
+			"Your personal data will be erased on %s. Until then you can sign in and cancel the deletion.\n\n"+
+			"If this wasn't you, sign in and change your password right away.\n",
+			player.Username, dueAt.UTC().Format("January 2, 2006"))
+
+		return enqueueEmail(tx, player.Email, "Your Zombie Car Game account will be deleted", body)
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return dueAt, nil
+}
+
+// CancelDeletion keeps an account that is still within its grace period
+func (s *PrivacyService) CancelDeletion(playerID uint, ipAddress string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Player{}).
+			Where("id = ? AND deletion_due_at IS NOT NULL", playerID).
+			Update("deletion_due_at", nil)
+		if result.Error != nil {
+			return fmt.Errorf("failed to cancel deletion: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrDeletionNotScheduled
+		}
+
+		return recordAudit(tx, AuditEntry{
+			Action:     models.AuditActionDeletionCancelled,
+			ActorID:    &playerID,
+			TargetType: "player",
+			TargetID:   strconv.FormatUint(uint64(playerID), 10),
+			IPAddress:  ipAddress,
+		})
+	})
+}
+
+// Run purges accounts whose grace period has ended until ctx is cancelled
+func (s *PrivacyService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := s.PurgeDueAccounts(ctx); err != nil {
+			slog.WarnContext(ctx, "Account purge failed", "error", err)
+		} else if purged > 0 {
+			slog.InfoContext(ctx, "Account purge anonymized deleted accounts", "purged", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeDueAccounts anonymizes every account whose deletion date has passed
+func (s *PrivacyService) PurgeDueAccounts(ctx context.Context) (int, error) {
+	purged := 0
+
+	for ctx.Err() == nil {
+		var ids []uint
+		if err := s.db.WithContext(ctx).Model(&models.Player{}).
+			Where("deletion_due_at IS NOT NULL AND deletion_due_at <= ?", time.Now()).
+			Order("id").
+			Limit(accountPurgeBatchSize).
+			Pluck("id", &ids).Error; err != nil {
+			return purged, fmt.Errorf("database error: %w", err)
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		for _, id := range ids {
+			if err := s.anonymizePlayer(ctx, id); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+
+		if len(ids) < accountPurgeBatchSize {
+			break
+		}
+	}
+
+	return purged, nil
+}
+
+// anonymizePlayer erases a player's personal data while keeping their gameplay rows
+func (s *PrivacyService) anonymizePlayer(ctx context.Context, playerID uint) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var player models.Player
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&player, playerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return fmt.Errorf("database error: %w", err)
+		}
+
+		// The player may have cancelled since the batch was selected
+		if player.DeletionDueAt == nil || player.DeletionDueAt.After(time.Now()) {
+			return nil
+		}
+
+		for _, model := range []interface{}{&models.RefreshToken{}, &models.AccountToken{},
+			&models.TwoFactorCredential{}, &models.RecoveryCode{}, &models.DeviceSession{},
+			&models.StoreAccount{}} {
+			if err := tx.Unscoped().Where("player_id = ?", playerID).Delete(model).Error; err != nil {
+				return fmt.Errorf("failed to purge credentials: %w", err)
+			}
+		}
+
+		if err := tx.Where("recipient = ?", player.Email).Delete(&models.OutboxEmail{}).Error; err != nil {
+			return fmt.Errorf("failed to purge queued mail: %w", err)
+		}
+
+		if err := tx.Model(&models.PromoRedemption{}).
+			Where("player_id = ?", playerID).
+			Update("ip_address", "").Error; err != nil {
+			return fmt.Errorf("failed to purge redemption addresses: %w", err)
+		}
+
+		// The row stays (soft-deleted) so sessions, progress and the ledger keep their owner
+		if err := tx.Model(&player).Updates(map[string]interface{}{
+			"username":           fmt.Sprintf("deleted_%d", player.ID),
+			"email":              fmt.Sprintf("deleted-%d@%s", player.ID, deletedEmailDomain),
+			"password_hash":      "",
+			"email_verified_at":  nil,
+			"device_id_hash":     nil,
+			"two_factor_enabled": false,
+			"last_active_at":     nil,
+			"deletion_due_at":    nil,
+			"deleted_at":         time.Now(),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to anonymize player: %w", err)
+		}
+
+		return recordAudit(tx, AuditEntry{
+			Action:     models.AuditActionAccountAnonymized,
+			TargetType: "player",
+			TargetID:   strconv.FormatUint(uint64(player.ID), 10),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	// Refresh tokens are gone with the rows above; outstanding access tokens die here
+	if err := s.jwtService.RevokeAllForPlayer(playerID); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+)
+
+func setupPrivacyTestDB(t *testing.T) (*gorm.DB, *models.Player) {
+	db := setupGuestTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AuditLog{}, &models.PromoCode{}))
+
+	auth, err := NewPlayerService(db, auth.NewJWTService(), testAccountConfig).CreatePlayer(CreatePlayerRequest{
+		Username: "leaving",
+		Email:    "leaving@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+	playerID := auth.Player.ID
+
+	require.NoError(t, db.Create(&models.OwnedVehicle{PlayerID: playerID, VehicleType: "sedan"}).Error)
+	require.NoError(t, db.Create(&models.LevelProgress{PlayerID: playerID, LevelID: "level-1", BestScore: 900}).Error)
+	require.NoError(t, db.Create(&models.WalletTransaction{
+		PlayerID: playerID, Currency: models.CurrencySoft, Amount: 250, BalanceAfter: 1250, Reason: "level_reward",
+	}).Error)
+	promo := models.PromoCode{Code: "WELCOME", Rewards: models.PromoRewards{}, Active: true}
+	require.NoError(t, db.Create(&promo).Error)
+	require.NoError(t, db.Create(&models.PromoRedemption{
+		PromoCodeID: promo.ID, PlayerID: playerID, Code: "WELCOME", IPAddress: "203.0.113.7", RedeemedAt: time.Now(),
+	}).Error)
+	require.NoError(t, db.Exec("INSERT INTO game_sessions (id, player_id, level_id) VALUES (?, ?, ?)",
+		"8a4f6c2e-1b3d-4e5f-9a7b-0c1d2e3f4a5b", playerID, "level-1").Error)
+
+	return db, auth.Player
+}
+
+func TestPrivacyService_ExportPlayerData(t *testing.T) {
+	db, player := setupPrivacyTestDB(t)
+	service := NewPrivacyService(db, auth.NewJWTService(), time.Hour)
+
+	export, err := service.ExportPlayerData(player.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "leaving@example.com", export.Player.Email)
+	assert.Len(t, export.OwnedVehicles, 1)
+	assert.Len(t, export.GameSessions, 1)
+	assert.Len(t, export.LevelProgress, 1)
+	assert.Len(t, export.WalletTransactions, 1)
+	assert.Len(t, export.PromoRedemptions, 1)
+
+	data, err := json.Marshal(export)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), player.PasswordHash)
+
+	_, err = service.ExportPlayerData(9999)
+	assert.ErrorIs(t, err, ErrPlayerNotFound)
+}
+
+func TestPrivacyService_RequestAndCancelDeletion(t *testing.T) {
+	db, player := setupPrivacyTestDB(t)
+	service := NewPrivacyService(db, auth.NewJWTService(), 24*time.Hour)
+
+	_, err := service.RequestDeletion(player.ID, DeleteAccountRequest{Password: "wrong"}, "")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	dueAt, err := service.RequestDeletion(player.ID, DeleteAccountRequest{Password: "password123"}, "203.0.113.7")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), dueAt, time.Minute)
+
+	// Asking again does not push the date back
+	again, err := service.RequestDeletion(player.ID, DeleteAccountRequest{Password: "password123"}, "")
+	require.NoError(t, err)
+	assert.True(t, dueAt.Equal(again))
+
+	var notice int64
+	require.NoError(t, db.Model(&models.OutboxEmail{}).
+		Where("recipient = ? AND subject LIKE ?", "leaving@example.com", "%deleted%").Count(&notice).Error)
+	assert.Equal(t, int64(1), notice)
+
+	// Nothing is purged inside the grace period
+	purged, err := service.PurgeDueAccounts(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, purged)
+
+	require.NoError(t, service.CancelDeletion(player.ID, ""))
+	assert.ErrorIs(t, service.CancelDeletion(player.ID, ""), ErrDeletionNotScheduled)
+
+	var stored models.Player
+	require.NoError(t, db.First(&stored, player.ID).Error)
+	assert.Nil(t, stored.DeletionDueAt)
+}
+
+func TestPrivacyService_PurgeDueAccounts(t *testing.T) {
+	db, player := setupPrivacyTestDB(t)
+	service := NewPrivacyService(db, auth.NewJWTService(), 0)
+
+	guest, err := NewPlayerService(db, auth.NewJWTService(), testAccountConfig).LoginAsGuest(GuestLoginRequest{DeviceID: "device-0123456789abcdef"})
+	require.NoError(t, err)
+
+	_, err = service.RequestDeletion(player.ID, DeleteAccountRequest{Password: "password123"}, "")
+	require.NoError(t, err)
+	_, err = service.RequestDeletion(guest.Player.ID, DeleteAccountRequest{}, "")
+	require.NoError(t, err)
+
+	purged, err := service.PurgeDueAccounts(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+
+	// The account is gone for lookups and its identifiers are free again
+	var stored models.Player
+	assert.ErrorIs(t, db.First(&stored, player.ID).Error, gorm.ErrRecordNotFound)
+	require.NoError(t, db.Unscoped().First(&stored, player.ID).Error)
+	assert.Equal(t, fmt.Sprintf("deleted_%d", player.ID), stored.Username)
+	assert.NotContains(t, stored.Email, "leaving")
+	assert.Empty(t, stored.PasswordHash)
+
+	_, err = NewPlayerService(db, auth.NewJWTService(), testAccountConfig).CreatePlayer(CreatePlayerRequest{
+		Username: "leaving", Email: "leaving@example.com", Password: "password123",
+	})
+	assert.NoError(t, err)
+
+	// Personal data is purged
+	assert.Equal(t, int64(0), countRows(t, db, "refresh_tokens", player.ID))
+	assert.Equal(t, int64(0), countRows(t, db, "account_tokens", player.ID))
+	var redemption models.PromoRedemption
+	require.NoError(t, db.Where("player_id = ?", player.ID).First(&redemption).Error)
+	assert.Empty(t, redemption.IPAddress)
+
+	// Gameplay history stays for aggregate statistics
+	assert.Equal(t, int64(1), countRows(t, db, "game_sessions", player.ID))
+	assert.Equal(t, int64(1), countRows(t, db, "level_progress", player.ID))
+	assert.Equal(t, int64(1), countRows(t, db, "owned_vehicles", player.ID))
+	assert.Equal(t, int64(1), countRows(t, db, "wallet_transactions", player.ID))
+
+	var storedGuest models.Player
+	require.NoError(t, db.Unscoped().First(&storedGuest, guest.Player.ID).Error)
+	assert.Nil(t, storedGuest.DeviceIDHash)
+
+	var anonymized int64
+	require.NoError(t, db.Model(&models.AuditLog{}).
+		Where("action = ?", models.AuditActionAccountAnonymized).Count(&anonymized).Error)
+	assert.Equal(t, int64(2), anonymized)
+}
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"zombie-car-game-backend/internal/metrics"
+	"zombie-car-game-backend/internal/models"
+)
+
+var (
+	ErrPromoCodeNotFound        = errors.New("promo code not found")
+	ErrPromoCodeExists          = errors.New("promo code already exists")
+	ErrPromoCodeInactive        = errors.New("promo code is no longer active")
+	ErrPromoCodeExpired         = errors.New("promo code has expired")
+	ErrPromoCodeExhausted       = errors.New("promo code has reached its redemption limit")
+	ErrPromoCodeAlreadyRedeemed = errors.New("promo code already redeemed")
+	ErrInvalidPromoCode         = errors.New("invalid promo code format")
+	ErrInvalidPromoReward       = errors.New("invalid promo reward")
+)
+
+// promoCodePattern restricts codes to characters that are easy to type and share
+var promoCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{4,50}$`)
+
+// promoCodeAlphabet omits characters that are easily confused (0/O, 1/I)
+const promoCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const generatedPromoCodeLength = 10
+
+// PromoService handles promo code creation and redemption
+type PromoService struct {
+	db            *gorm.DB
+	playerService *PlayerService
+}
+
+// NewPromoService creates a new promo service
+func NewPromoService(db *gorm.DB, playerService *PlayerService) *PromoService {
+	return &PromoService{
+		db:            db,
+		playerService: playerService,
+	}
+}
+
+// CreatePromoCodeRequest represents the request to create a promo code
+type CreatePromoCodeRequest struct {
+	Code           string               `json:"code" binding:"max=50"`
+	Description    string               `json:"description" binding:"max=255"`
+	Rewards        []models.PromoReward `json:"rewards" binding:"required,min=1,dive"`
+	MaxRedemptions int                  `json:"max_redemptions" binding:"min=0"`
+	PerPlayerLimit *int                 `json:"per_player_limit" binding:"omitempty,min=0"`
+	ExpiresAt      *time.Time           `json:"expires_at"`
+}
+
+// RedeemPromoCodeRequest represents the request to redeem a promo code
+type RedeemPromoCodeRequest struct {
+	Code string `json:"code" binding:"required,max=50"`
+}
+
+// RedeemPromoCodeResult represents the outcome of a successful redemption
+type RedeemPromoCodeResult struct {
+	Code     string               `json:"code"`
+	Rewards  []models.PromoReward `json:"rewards"`
+	Currency int                  `json:"currency"`
+}
+
+// CreatePromoCode creates a new promo code. A code is generated when none is supplied.
+func (s *PromoService) CreatePromoCode(createdBy uint, req CreatePromoCodeRequest) (*models.PromoCode, error) {
+	code := normalizePromoCode(req.Code)
+	if code == "" {
+		generated, err := generatePromoCode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate promo code: %w", err)
+		}
+		code = generated
+	}
+	if !promoCodePattern.MatchString(code) {
+		return nil, ErrInvalidPromoCode
+	}
+
+	for _, reward := range req.Rewards {
+		if err := validatePromoReward(reward); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrPromoCodeExpired
+	}
+
+	// Codes are once per player unless stated otherwise
+	perPlayerLimit := 1
+	if req.PerPlayerLimit != nil {
+		perPlayerLimit = *req.PerPlayerLimit
+	}
+
+	var existing models.PromoCode
+	if err := s.db.Unscoped().Where("code = ?", code).First(&existing).Error; err == nil {
+		return nil, ErrPromoCodeExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	promo := models.PromoCode{
+		Code:           code,
+		Description:    req.Description,
+		Rewards:        req.Rewards,
+		MaxRedemptions: req.MaxRedemptions,
+		PerPlayerLimit: perPlayerLimit,
+		Active:         true,
+		ExpiresAt:      req.ExpiresAt,
+		CreatedBy:      createdBy,
+	}
+
+	if err := s.db.Create(&promo).Error; err != nil {
+		return nil, fmt.Errorf("failed to create promo code: %w", err)
+	}
+
+	return &promo, nil
+}
+
+// ListPromoCodes returns all promo codes, newest first
+func (s *PromoService) ListPromoCodes() ([]models.PromoCode, error) {
+	var codes []models.PromoCode
+	if err := s.db.Order("created_at DESC").Find(&codes).Error; err != nil {
+		return nil, fmt.Errorf("failed to list promo codes: %w", err)
+	}
+	return codes, nil
+}
+
+// DeactivatePromoCode stops a promo code from being redeemed
+func (s *PromoService) DeactivatePromoCode(code string) error {
+	result := s.db.Model(&models.PromoCode{}).
+		Where("code = ?", normalizePromoCode(code)).
+		Update("active", false)
+	if result.Error != nil {
+		return fmt.Errorf("failed to deactivate promo code: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrPromoCodeNotFound
+	}
+	return nil
+}
+
+// GetRedemptions returns the redemption audit trail for a promo code
+func (s *PromoService) GetRedemptions(code string) ([]models.PromoRedemption, error) {
+	var promo models.PromoCode
+	if err := s.db.Where("code = ?", normalizePromoCode(code)).First(&promo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPromoCodeNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	var redemptions []models.PromoRedemption
+	if err := s.db.Where("promo_code_id = ?", promo.ID).Order("redeemed_at DESC").Find(&redemptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get redemptions: %w", err)
+	}
+	return redemptions, nil
+}
+
+// RedeemPromoCode redeems a promo code for a player and grants its rewards.
+//
+// The promo code row is locked for the duration of the transaction so that
+// concurrent redemptions of the same code are serialized; the redemption
+// counter is additionally incremented with a conditional update so the
+// global limit can never be overshot.
+func (s *PromoService) RedeemPromoCode(playerID uint, code string, ipAddress string) (*RedeemPromoCodeResult, error) {
+	code = normalizePromoCode(code)
+	if !promoCodePattern.MatchString(code) {
+		return nil, ErrPromoCodeNotFound
+	}
+
+	var granted []models.PromoReward
+	var currency int
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var promo models.PromoCode
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code = ?", code).First(&promo).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPromoCodeNotFound
+			}
+			return fmt.Errorf("database error: %w", err)
+		}
+
+		if !promo.Active {
+			return ErrPromoCodeInactive
+		}
+		if promo.IsExpired(time.Now()) {
+			return ErrPromoCodeExpired
+		}
+
+		if promo.PerPlayerLimit > 0 {
+			var redeemed int64
+			if err := tx.Model(&models.PromoRedemption{}).
+				Where("promo_code_id = ? AND player_id = ?", promo.ID, playerID).
+				Count(&redeemed).Error; err != nil {
+				return fmt.Errorf("database error: %w", err)
+			}
+			if redeemed >= int64(promo.PerPlayerLimit) {
+				return ErrPromoCodeAlreadyRedeemed
+			}
+		}
+
+		result := tx.Model(&models.PromoCode{}).
+			Where("id = ? AND (max_redemptions = 0 OR redemption_count < max_redemptions)", promo.ID).
+			Update("redemption_count", gorm.Expr("redemption_count + 1"))
+		if result.Error != nil {
+			return fmt.Errorf("failed to update redemption count: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrPromoCodeExhausted
+		}
+
+		for _, reward := range promo.Rewards {
+			ok, err := s.grantReward(tx, playerID, promo.Code, reward)
+			if err != nil {
+				return err
+			}
+			if ok {
+				granted = append(granted, reward)
+			}
+		}
+
+		redemption := models.PromoRedemption{
+			PromoCodeID: promo.ID,
+			PlayerID:    playerID,
+			Code:        promo.Code,
+			Rewards:     granted,
+			IPAddress:   ipAddress,
+			RedeemedAt:  time.Now(),
+		}
+		if err := tx.Create(&redemption).Error; err != nil {
+			return fmt.Errorf("failed to record redemption: %w", err)
+		}
+
+		var player models.Player
+		if err := tx.Select("currency").First(&player, playerID).Error; err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		currency = player.Currency
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, reward := range granted {
+		if reward.Type == models.PromoRewardCurrency {
+			metrics.CurrencyEarned(string(models.CurrencySoft), "promo_redemption", reward.Quantity)
+		}
+	}
+
+	if granted == nil {
+		granted = []models.PromoReward{}
+	}
+
+	return &RedeemPromoCodeResult{
+		Code:     code,
+		Rewards:  granted,
+		Currency: currency,
+	}, nil
+}
+
+// grantReward applies a single reward inside the redemption transaction.
+// It reports false when the reward was skipped, e.g. a vehicle already owned.
+func (s *PromoService) grantReward(tx *gorm.DB, playerID uint, code string, reward models.PromoReward) (bool, error) {
+	switch reward.Type {
+	case models.PromoRewardCurrency:
+		if _, err := applyWalletChange(tx, playerID, models.CurrencySoft, reward.Quantity, "promo_redemption", code); err != nil {
+			return false, err
+		}
+		return true, nil
+
+	case models.PromoRewardVehicle:
+		var owned int64
+		if err := tx.Model(&models.OwnedVehicle{}).
+			Where("player_id = ? AND vehicle_type = ?", playerID, reward.ItemID).
+			Count(&owned).Error; err != nil {
+			return false, fmt.Errorf("database error: %w", err)
+		}
+		if owned > 0 {
+			return false, nil
+		}
+
+		vehicle := models.OwnedVehicle{
+			PlayerID:    playerID,
+			VehicleType: reward.ItemID,
+			PurchasedAt: time.Now(),
+		}
+		if err := tx.Create(&vehicle).Error; err != nil {
+			return false, fmt.Errorf("failed to grant vehicle: %w", err)
+		}
+		return true, nil
+
+	case models.PromoRewardCosmetic, models.PromoRewardItem:
+		item := models.InventoryItem{
+			PlayerID:   playerID,
+			ItemType:   models.InventoryItemType(reward.Type),
+			ItemID:     reward.ItemID,
+			Quantity:   reward.Quantity,
+			AcquiredAt: time.Now(),
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "player_id"}, {Name: "item_type"}, {Name: "item_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"quantity":   gorm.Expr("inventory_items.quantity + ?", reward.Quantity),
+				"updated_at": time.Now(),
+			}),
+		}).Create(&item).Error; err != nil {
+			return false, fmt.Errorf("failed to grant item: %w", err)
+		}
+		return true, nil
+
+	default:
+		return false, ErrInvalidPromoReward
+	}
+}
+
+// validatePromoReward checks that a reward can actually be granted
+func validatePromoReward(reward models.PromoReward) error {
+	switch reward.Type {
+	case models.PromoRewardCurrency:
+		if reward.Quantity <= 0 {
+			return ErrInvalidPromoReward
+		}
+	case models.PromoRewardVehicle:
+		if _, exists := vehicleConfigs[reward.ItemID]; !exists {
+			return ErrInvalidVehicleType
+		}
+	case models.PromoRewardCosmetic, models.PromoRewardItem:
+		if reward.ItemID == "" || reward.Quantity <= 0 {
+			return ErrInvalidPromoReward
+		}
+	default:
+		return ErrInvalidPromoReward
+	}
+	return nil
+}
+
+// normalizePromoCode makes code lookups case-insensitive
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// generatePromoCode creates a random, human-friendly promo code
+func generatePromoCode() (string, error) {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(promoCodeAlphabet)))
+	for i := 0; i < generatedPromoCodeLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(promoCodeAlphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+)
+
+func setupPromoTestDB(t *testing.T) *gorm.DB {
+	return openTestDB(t, &models.Player{}, &models.OwnedVehicle{}, &models.InventoryItem{},
+		&models.PromoCode{}, &models.PromoRedemption{}, &models.WalletTransaction{})
+}
+
+func createPromoTestPlayer(t *testing.T, db *gorm.DB, username string) *models.Player {
+	player := &models.Player{
+		Username:     username,
+		Email:        username + "@example.com",
+		PasswordHash: "hashedpassword",
+		Currency:     1000,
+		Level:        1,
+	}
+	require.NoError(t, db.Create(player).Error)
+	return player
+}
+
+func TestPromoService_CreatePromoCode(t *testing.T) {
+	db := setupPromoTestDB(t)
+	service := NewPromoService(db, NewPlayerService(db, auth.NewJWTService(), testAccountConfig))
+
+	promo, err := service.CreatePromoCode(1, CreatePromoCodeRequest{
+		Code:    " launch-2026 ",
+		Rewards: []models.PromoReward{{Type: models.PromoRewardCurrency, Quantity: 500}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "LAUNCH-2026", promo.Code)
+	assert.Equal(t, 1, promo.PerPlayerLimit)
+	assert.True(t, promo.Active)
+
+	_, err = service.CreatePromoCode(1, CreatePromoCodeRequest{
+		Code:    "launch-2026",
+		Rewards: []models.PromoReward{{Type: models.PromoRewardCurrency, Quantity: 500}},
+	})
+	assert.Equal(t, ErrPromoCodeExists, err)
+
+	generated, err := service.CreatePromoCode(1, CreatePromoCodeRequest{
+		Rewards: []models.PromoReward{{Type: models.PromoRewardItem, ItemID: "nitro", Quantity: 3}},
+	})
+	require.NoError(t, err)
+	assert.Len(t, generated.Code, generatedPromoCodeLength)
+
+	_, err = service.CreatePromoCode(1, CreatePromoCodeRequest{
+		Code:    "BADVEHICLE",
+		Rewards: []models.PromoReward{{Type: models.PromoRewardVehicle, ItemID: "hovercraft"}},
+	})
+	assert.Equal(t, ErrInvalidVehicleType, err)
+}
+
+func TestPromoService_RedeemPromoCode(t *testing.T) {
+	db := setupPromoTestDB(t)
+	service := NewPromoService(db, NewPlayerService(db, auth.NewJWTService(), testAccountConfig))
+	player := createPromoTestPlayer(t, db, "redeemer")
+
+	_, err := service.CreatePromoCode(1, CreatePromoCodeRequest{
+		Code: "WELCOME",
+		Rewards: []models.PromoReward{
+			{Type: models.PromoRewardCurrency, Quantity: 250},
+			{Type: models.PromoRewardVehicle, ItemID: "suv"},
+			{Type: models.PromoRewardCosmetic, ItemID: "flame_decal", Quantity: 1},
+		},
+	})
+	require.NoError(t, err)
+
+	result, err := service.RedeemPromoCode(player.ID, "welcome", "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, 1250, result.Currency)
+	assert.Len(t, result.Rewards, 3)
+
+	var vehicles int64
+	db.Model(&models.OwnedVehicle{}).Where("player_id = ? AND vehicle_type = ?", player.ID, "suv").Count(&vehicles)
+	assert.Equal(t, int64(1), vehicles)
+
+	var item models.InventoryItem
+	require.NoError(t, db.Where("player_id = ? AND item_id = ?", player.ID, "flame_decal").First(&item).Error)
+	assert.Equal(t, 1, item.Quantity)
+
+	var redemption models.PromoRedemption
+	require.NoError(t, db.Where("player_id = ?", player.ID).First(&redemption).Error)
+	assert.Equal(t, "WELCOME", redemption.Code)
+	assert.Equal(t, "127.0.0.1", redemption.IPAddress)
+
+	// Second redemption by the same player is rejected
+	_, err = service.RedeemPromoCode(player.ID, "WELCOME", "127.0.0.1")
+	assert.Equal(t, ErrPromoCodeAlreadyRedeemed, err)
+}
+
+func TestPromoService_RedeemPromoCode_Rejections(t *testing.T) {
+	db := setupPromoTestDB(t)
+	service := NewPromoService(db, NewPlayerService(db, auth.NewJWTService(), testAccountConfig))
+	player := createPromoTestPlayer(t, db, "rejected")
+
+	_, err := service.RedeemPromoCode(player.ID, "MISSING", "")
+	assert.Equal(t, ErrPromoCodeNotFound, err)
+
+	expired := time.Now().Add(-time.Hour)
+	require.NoError(t, db.Create(&models.PromoCode{
+		Code:           "EXPIRED",
+		Rewards:        models.PromoRewards{{Type: models.PromoRewardCurrency, Quantity: 10}},
+		PerPlayerLimit: 1,
+		Active:         true,
+		ExpiresAt:      &expired,
+	}).Error)
+	_, err = service.RedeemPromoCode(player.ID, "EXPIRED", "")
+	assert.Equal(t, ErrPromoCodeExpired, err)
+
+	_, err = service.CreatePromoCode(1, CreatePromoCodeRequest{
+		Code:    "DISABLED",
+		Rewards: []models.PromoReward{{Type: models.PromoRewardCurrency, Quantity: 10}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.DeactivatePromoCode("disabled"))
+	_, err = service.RedeemPromoCode(player.ID, "DISABLED", "")
+	assert.Equal(t, ErrPromoCodeInactive, err)
+
+	// Rejected redemptions leave no trace
+	var redemptions int64
+	db.Model(&models.PromoRedemption{}).Count(&redemptions)
+	assert.Equal(t, int64(0), redemptions)
+}
+
+func TestPromoService_RedeemPromoCode_Concurrent(t *testing.T) {
+	db := setupPromoTestDB(t)
+	service := NewPromoService(db, NewPlayerService(db, auth.NewJWTService(), testAccountConfig))
+
+	_, err := service.CreatePromoCode(1, CreatePromoCodeRequest{
+		Code:           "LIMITED",
+		Rewards:        []models.PromoReward{{Type: models.PromoRewardCurrency, Quantity: 100}},
+		MaxRedemptions: 5,
+	})
+	require.NoError(t, err)
+
+	var players []*models.Player
+	for i := 0; i < 20; i++ {
+		players = append(players, createPromoTestPlayer(t, db, fmt.Sprintf("racer%d", i)))
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for _, player := range players {
+		wg.Add(1)
+		go func(playerID uint) {
+			defer wg.Done()
+			_, err := service.RedeemPromoCode(playerID, "LIMITED", "")
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			} else {
+				assert.Equal(t, ErrPromoCodeExhausted, err)
+			}
+		}(player.ID)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 5, succeeded)
+
+	var promo models.PromoCode
+	require.NoError(t, db.Where("code = ?", "LIMITED").First(&promo).Error)
+	assert.Equal(t, 5, promo.RedemptionCount)
+
+	// The same player hammering a multi-use code only gets it once
+	_, err = service.CreatePromoCode(1, CreatePromoCodeRequest{
+		Code:    "COMMUNITY",
+		Rewards: []models.PromoReward{{Type: models.PromoRewardCurrency, Quantity: 100}},
+	})
+	require.NoError(t, err)
+
+	player := players[0]
+	succeeded = 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := service.RedeemPromoCode(player.ID, "COMMUNITY", ""); err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, succeeded)
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"zombie-car-game-backend/internal/metrics"
+	"zombie-car-game-backend/internal/models"
+	"zombie-car-game-backend/internal/payments"
+)
+
+var (
+	ErrUnknownProduct     = errors.New("unknown product")
+	ErrReceiptAlreadyUsed = errors.New("receipt already used by another player")
+	ErrPurchaseRefunded   = errors.New("purchase has been refunded")
+	ErrPurchaseNotFound   = errors.New("purchase not found")
+	// ErrPurchasePartiallyRefunded means the store returned part of the payment;
+	// such purchases are settled by support instead of automatically
+	ErrPurchasePartiallyRefunded = errors.New("purchase has been partially refunded")
+	ErrStoreAccountUnsupported   = errors.New("store does not use linked accounts")
+	ErrStoreAccountTaken         = errors.New("store account already linked to another player")
+)
+
+// PremiumProduct describes a store product that grants premium currency
+type PremiumProduct struct {
+	Name          string `json:"name"`
+	PremiumAmount int    `json:"premium_amount"`
+}
+
+// PurchaseService verifies store receipts and credits premium currency
+type PurchaseService struct {
+	db        *gorm.DB
+	verifiers *payments.Registry
+}
+
+// NewPurchaseService creates a new purchase service
+func NewPurchaseService(db *gorm.DB, verifiers *payments.Registry) *PurchaseService {
+	return &PurchaseService{
+		db:        db,
+		verifiers: verifiers,
+	}
+}
+
+// VerifyPurchaseRequest represents a client-submitted store receipt
+type VerifyPurchaseRequest struct {
+	Store   string `json:"store" binding:"required,max=20"`
+	Receipt string `json:"receipt" binding:"required,max=4096"`
+}
+
+// LinkStoreAccountRequest carries a store-issued proof that the player owns a
+// store account, such as a hex-encoded Steam auth session ticket
+type LinkStoreAccountRequest struct {
+	Store string `json:"store" binding:"required,max=20"`
+	Proof string `json:"proof" binding:"required,max=4096"`
+}
+
+// RefundPurchaseRequest represents a request to reverse a purchase
+type RefundPurchaseRequest struct {
+	Store         string `json:"store" binding:"required,max=20"`
+	TransactionID string `json:"transaction_id" binding:"required,max=128"`
+}
+
+// PurchaseResult represents the outcome of verifying a receipt
+type PurchaseResult struct {
+	Purchase       *models.Purchase `json:"purchase"`
+	PremiumBalance int              `json:"premium_balance"`
+	Duplicate      bool             `json:"duplicate"`
+}
+
+// GetProducts returns the premium currency products on sale
+func (s *PurchaseService) GetProducts() map[string]PremiumProduct {
+	return premiumProducts
+}
+
+// VerifyPurchase verifies a receipt with its store and credits the player's premium wallet.
+//
+// Crediting is idempotent: resubmitting a receipt that was already credited to the same
+// player returns the original purchase with Duplicate set. If the store now reports the
+// purchase as refunded, the earlier credit is reversed instead.
+func (s *PurchaseService) VerifyPurchase(ctx context.Context, playerID uint, req VerifyPurchaseRequest) (*PurchaseResult, error) {
+	verifier, err := s.verifiers.Get(req.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.storeAccount(playerID, req.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt, err := verifier.Verify(ctx, req.Receipt, account)
+	if err != nil {
+		return nil, err
+	}
+
+	if receipt.Refunded {
+		if _, err := s.RefundPurchase(receipt.Store, receipt.TransactionID); err != nil && !errors.Is(err, ErrPurchaseNotFound) {
+			return nil, err
+		}
+		return nil, ErrPurchaseRefunded
+	}
+
+	// Reversing the whole credit would overcharge the player, and keeping it
+	// unmarked would hide the refund, so flag it for support
+	if receipt.PartiallyRefunded {
+		if err := s.markPartiallyRefunded(receipt.Store, receipt.TransactionID); err != nil && !errors.Is(err, ErrPurchaseNotFound) {
+			return nil, err
+		}
+		return nil, ErrPurchasePartiallyRefunded
+	}
+
+	product, exists := premiumProducts[receipt.ProductID]
+	if !exists {
+		return nil, ErrUnknownProduct
+	}
+
+	quantity := receipt.Quantity
+	if quantity < 1 {
+		quantity = 1
+	}
+
+	purchasedAt := receipt.PurchasedAt
+	if purchasedAt.IsZero() {
+		purchasedAt = time.Now()
+	}
+
+	result := &PurchaseResult{}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		purchase := models.Purchase{
+			PlayerID:      playerID,
+			Store:         receipt.Store,
+			TransactionID: receipt.TransactionID,
+			ProductID:     receipt.ProductID,
+			Quantity:      quantity,
+			PremiumAmount: product.PremiumAmount * quantity,
+			Status:        models.PurchaseStatusCredited,
+			PurchasedAt:   purchasedAt,
+		}
+
+		// The unique store/transaction index makes concurrent submissions collide here
+		insert := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&purchase)
+		if insert.Error != nil {
+			return fmt.Errorf("failed to record purchase: %w", insert.Error)
+		}
+
+		if insert.RowsAffected == 0 {
+			var existing models.Purchase
+			if err := tx.Where("store = ? AND transaction_id = ?", receipt.Store, receipt.TransactionID).
+				First(&existing).Error; err != nil {
+				return fmt.Errorf("database error: %w", err)
+			}
+			if existing.PlayerID != playerID {
+				return ErrReceiptAlreadyUsed
+			}
+			if existing.IsRefunded() {
+				return ErrPurchaseRefunded
+			}
+
+			var player models.Player
+			if err := tx.Select("id", "premium_currency").First(&player, playerID).Error; err != nil {
+				return fmt.Errorf("database error: %w", err)
+			}
+
+			result.Purchase = &existing
+			result.PremiumBalance = player.PremiumCurrency
+			result.Duplicate = true
+			return nil
+		}
+
+		balance, err := applyWalletChange(tx, playerID, models.CurrencyPremium, purchase.PremiumAmount,
+			"purchase", purchaseReference(purchase.Store, purchase.TransactionID))
+		if err != nil {
+			return err
+		}
+
+		result.Purchase = &purchase
+		result.PremiumBalance = balance
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !result.Duplicate {
+		metrics.CurrencyEarned(string(models.CurrencyPremium), "purchase", result.Purchase.PremiumAmount)
+	}
+
+	return result, nil
+}
+
+// RefundPurchase reverses the premium credit of a purchase. Refunding an already
+// refunded purchase is a no-op. The balance may go negative if the currency was spent.
+func (s *PurchaseService) RefundPurchase(store, transactionID string) (*models.Purchase, error) {
+	var purchase models.Purchase
+	refunded := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("store = ? AND transaction_id = ?", store, transactionID).
+			First(&purchase).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPurchaseNotFound
+			}
+			return fmt.Errorf("database error: %w", err)
+		}
+
+		if purchase.IsRefunded() {
+			return nil
+		}
+
+		if _, err := applyWalletChange(tx, purchase.PlayerID, models.CurrencyPremium, -purchase.PremiumAmount,
+			"refund", purchaseReference(purchase.Store, purchase.TransactionID)); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		purchase.Status = models.PurchaseStatusRefunded
+		purchase.RefundedAt = &now
+		if err := tx.Save(&purchase).Error; err != nil {
+			return fmt.Errorf("failed to update purchase: %w", err)
+		}
+
+		refunded = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if refunded {
+		metrics.CurrencySpent(string(models.CurrencyPremium), "refund", purchase.PremiumAmount)
+	}
+
+	return &purchase, nil
+}
+
+// LinkStoreAccount links the store account proven by req to the player,
+// replacing any account they linked with that store before
+func (s *PurchaseService) LinkStoreAccount(ctx context.Context, playerID uint, req LinkStoreAccountRequest) (*models.StoreAccount, error) {
+	verifier, err := s.verifiers.Get(req.Store)
+	if err != nil {
+		return nil, err
+	}
+	accountVerifier, ok := verifier.(payments.AccountVerifier)
+	if !ok {
+		return nil, ErrStoreAccountUnsupported
+	}
+
+	accountID, err := accountVerifier.VerifyAccount(ctx, req.Proof)
+	if err != nil {
+		return nil, err
+	}
+
+	var account models.StoreAccount
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.StoreAccount
+		err := tx.Where("store = ? AND account_id = ?", req.Store, accountID).First(&existing).Error
+		if err == nil && existing.PlayerID != playerID {
+			return ErrStoreAccountTaken
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("database error: %w", err)
+		}
+
+		if err := tx.Where("player_id = ? AND store = ?", playerID, req.Store).
+			Assign(models.StoreAccount{AccountID: accountID}).
+			FirstOrCreate(&account, models.StoreAccount{PlayerID: playerID, Store: req.Store}).Error; err != nil {
+			return fmt.Errorf("failed to link store account: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// storeAccount returns the player's linked account with store, or "" if none is linked
+func (s *PurchaseService) storeAccount(playerID uint, store string) (string, error) {
+	var account models.StoreAccount
+	err := s.db.Where("player_id = ? AND store = ?", playerID, store).First(&account).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("database error: %w", err)
+	}
+	return account.AccountID, nil
+}
+
+// markPartiallyRefunded flags a credited purchase whose payment was partly
+// returned, leaving its credit in place for support to settle
+func (s *PurchaseService) markPartiallyRefunded(store, transactionID string) error {
+	result := s.db.Model(&models.Purchase{}).
+		Where("store = ? AND transaction_id = ? AND status = ?", store, transactionID, models.PurchaseStatusCredited).
+		Update("status", models.PurchaseStatusPartiallyRefunded)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update purchase: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrPurchaseNotFound
+	}
+	return nil
+}
+
+// purchaseReference builds the ledger reference for a store transaction
+func purchaseReference(store, transactionID string) string {
+	return store + ":" + transactionID
+}
+
+// premiumProducts maps store product IDs to the premium currency they grant
+var premiumProducts = map[string]PremiumProduct{
+	"1001": {
+		Name:          "Small Premium Pack",
+		PremiumAmount: 100,
+	},
+	"1002": {
+		Name:          "Medium Premium Pack",
+		PremiumAmount: 550,
+	},
+	"1003": {
+		Name:          "Large Premium Pack",
+		PremiumAmount: 1200,
+	},
+}
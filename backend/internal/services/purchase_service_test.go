@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/models"
+	"zombie-car-game-backend/internal/payments"
+)
+
+func setupPurchaseTestDB(t *testing.T) *gorm.DB {
+	return openTestDB(t, &models.Player{}, &models.WalletTransaction{}, &models.Purchase{}, &models.StoreAccount{})
+}
+
+func setupPurchaseService(t *testing.T) (*PurchaseService, *payments.FakeVerifier, *gorm.DB) {
+	db := setupPurchaseTestDB(t)
+	fake := payments.NewFakeVerifier()
+	return NewPurchaseService(db, payments.NewRegistry(fake)), fake, db
+}
+
+func TestPurchaseService_VerifyPurchase(t *testing.T) {
+	service, fake, db := setupPurchaseService(t)
+	player := createPromoTestPlayer(t, db, "buyer")
+	fake.AddReceipt("receipt-1", "txn-1", "1002", 2)
+
+	result, err := service.VerifyPurchase(context.Background(), player.ID, VerifyPurchaseRequest{
+		Store:   payments.FakeStore,
+		Receipt: "receipt-1",
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Duplicate)
+	assert.Equal(t, 1100, result.PremiumBalance)
+	assert.Equal(t, models.PurchaseStatusCredited, result.Purchase.Status)
+
+	// Resubmitting the same receipt does not credit again
+	result, err = service.VerifyPurchase(context.Background(), player.ID, VerifyPurchaseRequest{
+		Store:   payments.FakeStore,
+		Receipt: "receipt-1",
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Duplicate)
+	assert.Equal(t, 1100, result.PremiumBalance)
+
+	var entries []models.WalletTransaction
+	require.NoError(t, db.Where("player_id = ?", player.ID).Find(&entries).Error)
+	require.Len(t, entries, 1)
+	assert.Equal(t, models.CurrencyPremium, entries[0].Currency)
+	assert.Equal(t, 1100, entries[0].Amount)
+	assert.Equal(t, "fake:txn-1", entries[0].Reference)
+
+	// Another player cannot claim the same receipt
+	other := createPromoTestPlayer(t, db, "thief")
+	_, err = service.VerifyPurchase(context.Background(), other.ID, VerifyPurchaseRequest{
+		Store:   payments.FakeStore,
+		Receipt: "receipt-1",
+	})
+	assert.Equal(t, ErrReceiptAlreadyUsed, err)
+}
+
+func TestPurchaseService_VerifyPurchase_Invalid(t *testing.T) {
+	service, fake, db := setupPurchaseService(t)
+	player := createPromoTestPlayer(t, db, "invalid")
+
+	_, err := service.VerifyPurchase(context.Background(), player.ID, VerifyPurchaseRequest{
+		Store:   "unknown",
+		Receipt: "receipt",
+	})
+	assert.ErrorIs(t, err, payments.ErrUnknownStore)
+
+	_, err = service.VerifyPurchase(context.Background(), player.ID, VerifyPurchaseRequest{
+		Store:   payments.FakeStore,
+		Receipt: "missing",
+	})
+	assert.ErrorIs(t, err, payments.ErrInvalidReceipt)
+
+	fake.AddReceipt("receipt-2", "txn-2", "9999", 1)
+	_, err = service.VerifyPurchase(context.Background(), player.ID, VerifyPurchaseRequest{
+		Store:   payments.FakeStore,
+		Receipt: "receipt-2",
+	})
+	assert.Equal(t, ErrUnknownProduct, err)
+}
+
+func TestPurchaseService_VerifyPurchase_Concurrent(t *testing.T) {
+	service, fake, db := setupPurchaseService(t)
+	player := createPromoTestPlayer(t, db, "spammer")
+	fake.AddReceipt("receipt-3", "txn-3", "1001", 1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.VerifyPurchase(context.Background(), player.ID, VerifyPurchaseRequest{
+				Store:   payments.FakeStore,
+				Receipt: "receipt-3",
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	var loaded models.Player
+	require.NoError(t, db.First(&loaded, player.ID).Error)
+	assert.Equal(t, 100, loaded.PremiumCurrency)
+}
+
+func TestPurchaseService_RefundPurchase(t *testing.T) {
+	service, fake, db := setupPurchaseService(t)
+	player := createPromoTestPlayer(t, db, "refunder")
+	fake.AddReceipt("receipt-4", "txn-4", "1003", 1)
+
+	_, err := service.VerifyPurchase(context.Background(), player.ID, VerifyPurchaseRequest{
+		Store:   payments.FakeStore,
+		Receipt: "receipt-4",
+	})
+	require.NoError(t, err)
+
+	purchase, err := service.RefundPurchase(payments.FakeStore, "txn-4")
+	require.NoError(t, err)
+	assert.True(t, purchase.IsRefunded())
+	assert.NotNil(t, purchase.RefundedAt)
+
+	// Refunding twice is a no-op
+	_, err = service.RefundPurchase(payments.FakeStore, "txn-4")
+	require.NoError(t, err)
+
+	var loaded models.Player
+	require.NoError(t, db.First(&loaded, player.ID).Error)
+	assert.Equal(t, 0, loaded.PremiumCurrency)
+
+	var entries int64
+	db.Model(&models.WalletTransaction{}).Where("player_id = ?", player.ID).Count(&entries)
+	assert.Equal(t, int64(2), entries)
+
+	_, err = service.RefundPurchase(payments.FakeStore, "missing")
+	assert.Equal(t, ErrPurchaseNotFound, err)
+}
+
+func TestPurchaseService_VerifyPurchase_StoreReportsRefund(t *testing.T) {
+	service, fake, db := setupPurchaseService(t)
+	player := createPromoTestPlayer(t, db, "chargeback")
+	fake.AddReceipt("receipt-5", "txn-5", "1001", 1)
+
+	_, err := service.VerifyPurchase(context.Background(), player.ID, VerifyPurchaseRequest{
+		Store:   payments.FakeStore,
+		Receipt: "receipt-5",
+	})
+	require.NoError(t, err)
+
+	fake.MarkRefunded("receipt-5")
+	_, err = service.VerifyPurchase(context.Background(), player.ID, VerifyPurchaseRequest{
+		Store:   payments.FakeStore,
+		Receipt: "receipt-5",
+	})
+	assert.Equal(t, ErrPurchaseRefunded, err)
+
+	var loaded models.Player
+	require.NoError(t, db.First(&loaded, player.ID).Error)
+	assert.Equal(t, 0, loaded.PremiumCurrency)
+}
+
+func TestPurchaseService_LinkStoreAccount(t *testing.T) {
+	service, fake, db := setupPurchaseService(t)
+	player := createPromoTestPlayer(t, db, "buyer")
+	other := createPromoTestPlayer(t, db, "thief")
+	fake.AddAccount("ticket-buyer", "account-1")
+	fake.AddAccount("ticket-buyer-2", "account-2")
+	fake.AddReceipt("receipt-1", "txn-1", "1001", 1)
+	fake.SetBuyer("receipt-1", "account-1")
+
+	// Without a linked account the receipt's buyer cannot match
+	_, err := service.VerifyPurchase(context.Background(), player.ID, VerifyPurchaseRequest{
+		Store:   payments.FakeStore,
+		Receipt: "receipt-1",
+	})
+	assert.ErrorIs(t, err, payments.ErrAccountMismatch)
+
+	_, err = service.LinkStoreAccount(context.Background(), player.ID, LinkStoreAccountRequest{
+		Store: payments.FakeStore,
+		Proof: "forged",
+	})
+	assert.ErrorIs(t, err, payments.ErrInvalidProof)
+
+	account, err := service.LinkStoreAccount(context.Background(), player.ID, LinkStoreAccountRequest{
+		Store: payments.FakeStore,
+		Proof: "ticket-buyer",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "account-1", account.AccountID)
+
+	result, err := service.VerifyPurchase(context.Background(), player.ID, VerifyPurchaseRequest{
+		Store:   payments.FakeStore,
+		Receipt: "receipt-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 100, result.PremiumBalance)
+
+	// One store account belongs to one player
+	_, err = service.LinkStoreAccount(context.Background(), other.ID, LinkStoreAccountRequest{
+		Store: payments.FakeStore,
+		Proof: "ticket-buyer",
+	})
+	assert.ErrorIs(t, err, ErrStoreAccountTaken)
+
+	// Linking again replaces the player's account
+	account, err = service.LinkStoreAccount(context.Background(), player.ID, LinkStoreAccountRequest{
+		Store: payments.FakeStore,
+		Proof: "ticket-buyer-2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "account-2", account.AccountID)
+
+	var count int64
+	require.NoError(t, db.Model(&models.StoreAccount{}).Where("player_id = ?", player.ID).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestPurchaseService_VerifyPurchase_StoreReportsPartialRefund(t *testing.T) {
+	service, fake, db := setupPurchaseService(t)
+	player := createPromoTestPlayer(t, db, "buyer")
+	fake.AddReceipt("receipt-1", "txn-1", "1002", 1)
+
+	_, err := service.VerifyPurchase(context.Background(), player.ID, VerifyPurchaseRequest{
+		Store:   payments.FakeStore,
+		Receipt: "receipt-1",
+	})
+	require.NoError(t, err)
+
+	fake.MarkPartiallyRefunded("receipt-1")
+	_, err = service.VerifyPurchase(context.Background(), player.ID, VerifyPurchaseRequest{
+		Store:   payments.FakeStore,
+		Receipt: "receipt-1",
+	})
+	assert.ErrorIs(t, err, ErrPurchasePartiallyRefunded)
+
+	var purchase models.Purchase
+	require.NoError(t, db.Where("transaction_id = ?", "txn-1").First(&purchase).Error)
+	assert.Equal(t, models.PurchaseStatusPartiallyRefunded, purchase.Status)
+	assert.Nil(t, purchase.RefundedAt)
+
+	var updated models.Player
+	require.NoError(t, db.First(&updated, player.ID).Error)
+	assert.Equal(t, 550, updated.PremiumCurrency, "the credit is kept for support to settle")
+
+	// A new receipt that is already partially refunded is not credited
+	fake.AddReceipt("receipt-2", "txn-2", "1001", 1)
+	fake.MarkPartiallyRefunded("receipt-2")
+	_, err = service.VerifyPurchase(context.Background(), player.ID, VerifyPurchaseRequest{
+		Store:   payments.FakeStore,
+		Receipt: "receipt-2",
+	})
+	assert.ErrorIs(t, err, ErrPurchasePartiallyRefunded)
+	require.NoError(t, db.First(&updated, player.ID).Error)
+	assert.Equal(t, 550, updated.PremiumCurrency)
+}
@@ -0,0 +1,487 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+)
+
+var (
+	ErrSanctionNotFound       = errors.New("sanction not found")
+	ErrSanctionNotActive      = errors.New("sanction is not active")
+	ErrInvalidSanction        = errors.New("invalid sanction")
+	ErrAppealAlreadySubmitted = errors.New("sanction has already been appealed")
+	ErrNoPendingAppeal        = errors.New("sanction has no pending appeal")
+	ErrAccountSanctioned      = errors.New("account is sanctioned")
+)
+
+// AccountSanctionedError is returned when a banned or suspended player tries to sign in
+type AccountSanctionedError struct {
+	Sanction *models.Sanction
+}
+
+func (e *AccountSanctionedError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrAccountSanctioned, e.Sanction.Type)
+}
+
+// Unwrap lets callers match the error with errors.Is(err, ErrAccountSanctioned)
+func (e *AccountSanctionedError) Unwrap() error {
+	return ErrAccountSanctioned
+}
+
+// accessSanctionCacheTTL is how long ActiveAccessSanction results are reused.
+// Issuing a ban or suspension also revokes the player's refresh tokens and
+// denylists their access tokens, so only lifting one through another instance
+// waits this long to take effect.
+const accessSanctionCacheTTL = 15 * time.Second
+
+// SanctionService issues and lifts player sanctions and handles appeals
+type SanctionService struct {
+	db           *gorm.DB
+	jwtService   *auth.JWTService
+	tokenService *TokenService
+	accessCache  *sanctionCache
+}
+
+// NewSanctionService creates a new sanction service
+func NewSanctionService(db *gorm.DB, jwtService *auth.JWTService, refreshTTL time.Duration) *SanctionService {
+	return &SanctionService{
+		db:           db,
+		jwtService:   jwtService,
+		tokenService: NewTokenService(db, jwtService, refreshTTL),
+		accessCache:  newSanctionCache(accessSanctionCacheTTL),
+	}
+}
+
+// IssueSanctionRequest represents a staff member sanctioning a player
+type IssueSanctionRequest struct {
+	Type   models.SanctionType `json:"type" binding:"required,oneof=ban suspension leaderboard_exclusion mute"`
+	Reason string              `json:"reason" binding:"required,max=500"`
+	// ExpiresAt ends the sanction automatically; it is required for suspensions
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// LiftSanctionRequest represents a staff member ending a sanction early
+type LiftSanctionRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// ResolveAppealRequest represents a staff member rejecting an appeal
+type ResolveAppealRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// AppealSanctionRequest represents a player appealing a sanction. Banned players
+// cannot authenticate, so the appeal is authorised with the account credentials.
+type AppealSanctionRequest struct {
+	Identifier string `json:"identifier" binding:"required"`
+	Password   string `json:"password" binding:"required"`
+	SanctionID uint   `json:"sanction_id" binding:"required"`
+	Message    string `json:"message" binding:"required,max=2000"`
+	// IPAddress is filled in by the handler for brute-force protection
+	IPAddress string `json:"-"`
+}
+
+// IssueSanction records a sanction against a player. Bans and suspensions
+// also end every login of the player and revoke their access tokens.
+func (s *SanctionService) IssueSanction(actor AdminActor, playerID uint, req IssueSanctionRequest) (*models.Sanction, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidSanction
+	}
+	if req.Type == models.SanctionSuspension && req.ExpiresAt == nil {
+		return nil, ErrInvalidSanction
+	}
+	if actor.PlayerID == playerID {
+		return nil, ErrInvalidSanction
+	}
+
+	sanction := models.Sanction{
+		PlayerID:  playerID,
+		Type:      req.Type,
+		Reason:    strings.TrimSpace(req.Reason),
+		IssuedBy:  &actor.PlayerID,
+		ExpiresAt: req.ExpiresAt,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var player models.Player
+		if err := lockPlayer(tx, playerID, &player); err != nil {
+			return err
+		}
+
+		if err := tx.Create(&sanction).Error; err != nil {
+			return fmt.Errorf("failed to create sanction: %w", err)
+		}
+
+		return recordAudit(tx, AuditEntry{
+			Action:     models.AuditActionSanctionIssued,
+			ActorID:    &actor.PlayerID,
+			TargetType: "player",
+			TargetID:   strconv.FormatUint(uint64(playerID), 10),
+			Details: map[string]interface{}{
+				"sanction_id": sanction.ID,
+				"type":        sanction.Type,
+				"reason":      sanction.Reason,
+				"expires_at":  sanction.ExpiresAt,
+			},
+			RequestID: actor.RequestID,
+			IPAddress: actor.IPAddress,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The denylist reaches every instance at once, unlike the sanction cache
+	if sanction.BlocksAccess() {
+		s.accessCache.invalidate(playerID)
+		if err := s.tokenService.RevokeAllForPlayer(playerID); err != nil {
+			return nil, err
+		}
+		if err := s.jwtService.RevokeAllForPlayer(playerID); err != nil {
+			return nil, fmt.Errorf("failed to revoke access tokens: %w", err)
+		}
+	}
+	if sanction.HidesFromLeaderboards() {
+		s.refreshLeaderboards()
+	}
+
+	return &sanction, nil
+}
+
+// LiftSanction ends an active sanction early. A pending appeal is accepted with it.
+func (s *SanctionService) LiftSanction(actor AdminActor, sanctionID uint, req LiftSanctionRequest) (*models.Sanction, error) {
+	var sanction models.Sanction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockSanction(tx, sanctionID, &sanction); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if !sanction.IsActive(now) {
+			return ErrSanctionNotActive
+		}
+
+		sanction.LiftedAt = &now
+		sanction.LiftedBy = &actor.PlayerID
+		sanction.LiftReason = strings.TrimSpace(req.Reason)
+		if sanction.AppealStatus != nil && *sanction.AppealStatus == models.AppealStatusPending {
+			accepted := models.AppealStatusAccepted
+			sanction.AppealStatus = &accepted
+			sanction.AppealResolvedAt = &now
+		}
+		if err := tx.Save(&sanction).Error; err != nil {
+			return fmt.Errorf("failed to lift sanction: %w", err)
+		}
+
+		return recordAudit(tx, AuditEntry{
+			Action:     models.AuditActionSanctionLifted,
+			ActorID:    &actor.PlayerID,
+			TargetType: "player",
+			TargetID:   strconv.FormatUint(uint64(sanction.PlayerID), 10),
+			Details: map[string]interface{}{
+				"sanction_id":   sanction.ID,
+				"type":          sanction.Type,
+				"reason":        sanction.LiftReason,
+				"appeal_status": sanction.AppealStatus,
+			},
+			RequestID: actor.RequestID,
+			IPAddress: actor.IPAddress,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if sanction.BlocksAccess() {
+		s.accessCache.invalidate(sanction.PlayerID)
+	}
+	if sanction.HidesFromLeaderboards() {
+		s.refreshLeaderboards()
+	}
+
+	return &sanction, nil
+}
+
+// RejectAppeal turns down a pending appeal; the sanction stays in force
+func (s *SanctionService) RejectAppeal(actor AdminActor, sanctionID uint, req ResolveAppealRequest) (*models.Sanction, error) {
+	var sanction models.Sanction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockSanction(tx, sanctionID, &sanction); err != nil {
+			return err
+		}
+
+		if sanction.AppealStatus == nil || *sanction.AppealStatus != models.AppealStatusPending {
+			return ErrNoPendingAppeal
+		}
+
+		now := time.Now()
+		rejected := models.AppealStatusRejected
+		sanction.AppealStatus = &rejected
+		sanction.AppealResolvedAt = &now
+		if err := tx.Save(&sanction).Error; err != nil {
+			return fmt.Errorf("failed to reject appeal: %w", err)
+		}
+
+		return recordAudit(tx, AuditEntry{
+			Action:     models.AuditActionAppealRejected,
+			ActorID:    &actor.PlayerID,
+			TargetType: "player",
+			TargetID:   strconv.FormatUint(uint64(sanction.PlayerID), 10),
+			Details: map[string]interface{}{
+				"sanction_id": sanction.ID,
+				"reason":      strings.TrimSpace(req.Reason),
+			},
+			RequestID: actor.RequestID,
+			IPAddress: actor.IPAddress,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &sanction, nil
+}
+
+// ListPlayerSanctions returns every sanction ever issued against a player, newest first
+func (s *SanctionService) ListPlayerSanctions(playerID uint) ([]models.Sanction, error) {
+	if err := ensurePlayerExists(s.db, playerID); err != nil {
+		return nil, err
+	}
+
+	sanctions := []models.Sanction{}
+	if err := s.db.Where("player_id = ?", playerID).
+		Order("created_at DESC, id DESC").
+		Find(&sanctions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get sanctions: %w", err)
+	}
+	return sanctions, nil
+}
+
+// ListPendingAppeals returns appeals waiting for review, oldest first
+func (s *SanctionService) ListPendingAppeals() ([]models.Sanction, error) {
+	sanctions := []models.Sanction{}
+	if err := s.db.Where("appeal_status = ?", models.AppealStatusPending).
+		Order("appealed_at ASC").
+		Find(&sanctions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get appeals: %w", err)
+	}
+	return sanctions, nil
+}
+
+// ActiveAccessSanction returns the ban or suspension currently barring a player, or nil.
+// It is what AuthMiddleware consults on every authenticated request, so results
+// are cached briefly.
+func (s *SanctionService) ActiveAccessSanction(playerID uint) (*models.Sanction, error) {
+	now := time.Now()
+	sanction, generation, ok := s.accessCache.get(playerID, now)
+	if ok {
+		return sanction, nil
+	}
+
+	sanction, err := activeAccessSanction(s.db, playerID)
+	if err != nil {
+		return nil, err
+	}
+	s.accessCache.put(playerID, sanction, generation, now)
+	return sanction, nil
+}
+
+// refreshLeaderboards rebuilds the materialized leaderboard so a sanction shows
+// up immediately. Views only exist on Postgres; failures are logged because the
+// sanction itself is already in force.
+func (s *SanctionService) refreshLeaderboards() {
+	if s.db.Dialector.Name() != "postgres" {
+		return
+	}
+	if err := refreshLeaderboardView(s.db); err != nil {
+		slog.Warn("Failed to refresh leaderboards", "error", err)
+	}
+}
+
+// AppealSanction lets a player contest one of their active sanctions, once
+func (s *PlayerService) AppealSanction(req AppealSanctionRequest) (*models.Sanction, error) {
+	identifier := strings.TrimSpace(req.Identifier)
+	player, attempt, err := s.reserveLogin(identifier, req.IPAddress)
+	if err != nil {
+		return nil, err
+	}
+	if player == nil {
+		s.recordLoginFailure(attempt, identifier, req.IPAddress)
+		return nil, ErrInvalidCredentials
+	}
+	if err := s.passwordService.VerifyPassword(player.PasswordHash, req.Password); err != nil {
+		s.recordLoginFailure(attempt, identifier, req.IPAddress)
+		return nil, ErrInvalidCredentials
+	}
+	attempt.Release()
+
+	var sanction models.Sanction
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockSanction(tx, req.SanctionID, &sanction); err != nil {
+			return err
+		}
+		if sanction.PlayerID != player.ID {
+			return ErrSanctionNotFound
+		}
+
+		now := time.Now()
+		if !sanction.IsActive(now) {
+			return ErrSanctionNotActive
+		}
+		if sanction.AppealStatus != nil {
+			return ErrAppealAlreadySubmitted
+		}
+
+		pending := models.AppealStatusPending
+		sanction.AppealMessage = strings.TrimSpace(req.Message)
+		sanction.AppealStatus = &pending
+		sanction.AppealedAt = &now
+		if err := tx.Save(&sanction).Error; err != nil {
+			return fmt.Errorf("failed to record appeal: %w", err)
+		}
+
+		return recordAudit(tx, AuditEntry{
+			Action:     models.AuditActionSanctionAppealed,
+			ActorID:    &player.ID,
+			TargetType: "player",
+			TargetID:   strconv.FormatUint(uint64(player.ID), 10),
+			Details:    map[string]interface{}{"sanction_id": sanction.ID},
+			IPAddress:  req.IPAddress,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &sanction, nil
+}
+
+// checkAccessSanction refuses sign-in to players under an active ban or suspension
+func (s *PlayerService) checkAccessSanction(playerID uint) error {
+	sanction, err := activeAccessSanction(s.db, playerID)
+	if err != nil {
+		return err
+	}
+	if sanction != nil {
+		return &AccountSanctionedError{Sanction: sanction}
+	}
+	return nil
+}
+
+// activeAccessSanction finds the ban or suspension barring a player, preferring
+// a permanent one and then the one that lasts longest
+func activeAccessSanction(db *gorm.DB, playerID uint) (*models.Sanction, error) {
+	var sanctions []models.Sanction
+	if err := db.Where("player_id = ? AND type IN ? AND lifted_at IS NULL AND (expires_at IS NULL OR expires_at > ?)",
+		playerID, []models.SanctionType{models.SanctionBan, models.SanctionSuspension}, time.Now()).
+		Order("expires_at IS NOT NULL").
+		Order("expires_at DESC").
+		Limit(1).
+		Find(&sanctions).Error; err != nil {
+		return nil, fmt.Errorf("failed to check sanctions: %w", err)
+	}
+	if len(sanctions) == 0 {
+		return nil, nil
+	}
+	return &sanctions[0], nil
+}
+
+// lockSanction loads a sanction row for update inside tx
+func lockSanction(tx *gorm.DB, sanctionID uint, sanction *models.Sanction) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(sanction, sanctionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSanctionNotFound
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// sanctionCache remembers recent access sanction lookups, including the ones
+// that found nothing
+type sanctionCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	entries    map[uint]sanctionCacheEntry
+	generation uint64
+	lastSweep  time.Time
+}
+
+type sanctionCacheEntry struct {
+	sanction  *models.Sanction
+	expiresAt time.Time
+}
+
+func newSanctionCache(ttl time.Duration) *sanctionCache {
+	return &sanctionCache{ttl: ttl, entries: make(map[uint]sanctionCacheEntry)}
+}
+
+// get returns a cached lookup, or the generation to hand to put after a miss
+func (c *sanctionCache) get(playerID uint, now time.Time) (*models.Sanction, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[playerID]
+	if !ok || !now.Before(entry.expiresAt) {
+		return nil, c.generation, false
+	}
+	if entry.sanction == nil {
+		return nil, c.generation, true
+	}
+	sanction := *entry.sanction
+	return &sanction, c.generation, true
+}
+
+// put caches a lookup unless the cache was invalidated since it started, and
+// never past the end of the sanction
+func (c *sanctionCache) put(playerID uint, sanction *models.Sanction, generation uint64, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	c.sweep(now)
+
+	entry := sanctionCacheEntry{expiresAt: now.Add(c.ttl)}
+	if sanction != nil {
+		copied := *sanction
+		entry.sanction = &copied
+		if sanction.ExpiresAt != nil && sanction.ExpiresAt.Before(entry.expiresAt) {
+			entry.expiresAt = *sanction.ExpiresAt
+		}
+	}
+	c.entries[playerID] = entry
+}
+
+// invalidate drops a player's entry and any lookup still in flight
+func (c *sanctionCache) invalidate(playerID uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, playerID)
+	c.generation++
+}
+
+// sweep drops expired entries so the map cannot grow without bound
+func (c *sanctionCache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < time.Minute {
+		return
+	}
+	c.lastSweep = now
+
+	for playerID, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, playerID)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+)
+
+func TestSanctionService_BanBlocksLoginUntilLifted(t *testing.T) {
+	db := setupGuestTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AuditLog{}))
+	jwtService := auth.NewJWTService()
+	playerService := NewPlayerService(db, jwtService, testAccountConfig)
+	service := NewSanctionService(db, jwtService, testAccountConfig.RefreshTokenTTL)
+
+	moderator := createPromoTestPlayer(t, db, "moderator")
+	actor := AdminActor{PlayerID: moderator.ID}
+	registered, err := playerService.CreatePlayer(CreatePlayerRequest{
+		Username: "cheater",
+		Email:    "cheater@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+	playerID := registered.Player.ID
+
+	_, err = service.IssueSanction(actor, moderator.ID, IssueSanctionRequest{Type: models.SanctionBan, Reason: "self"})
+	assert.ErrorIs(t, err, ErrInvalidSanction)
+
+	ban, err := service.IssueSanction(actor, playerID, IssueSanctionRequest{
+		Type:   models.SanctionBan,
+		Reason: "speed hacking",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, moderator.ID, *ban.IssuedBy)
+
+	active, err := service.ActiveAccessSanction(playerID)
+	require.NoError(t, err)
+	require.NotNil(t, active)
+	assert.Equal(t, ban.ID, active.ID)
+
+	// Existing logins are ended, access tokens revoked and new logins refused
+	// with the sanction attached
+	_, err = jwtService.ValidateToken(registered.Token)
+	assert.ErrorIs(t, err, auth.ErrRevokedToken)
+	_, err = playerService.RefreshToken(registered.RefreshToken, DeviceInfo{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	_, err = playerService.Login(LoginRequest{Identifier: "cheater", Password: "password123"})
+	var sanctioned *AccountSanctionedError
+	require.True(t, errors.As(err, &sanctioned))
+	assert.Equal(t, ban.ID, sanctioned.Sanction.ID)
+	assert.Equal(t, "account_banned", sanctioned.Sanction.Notice().Code)
+
+	// A wrong password does not reveal the ban
+	_, err = playerService.Login(LoginRequest{Identifier: "cheater", Password: "wrong"})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	// The player appeals with their credentials, once
+	_, err = playerService.AppealSanction(AppealSanctionRequest{
+		Identifier: "cheater", Password: "wrong", SanctionID: ban.ID, Message: "it was lag",
+	})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	appealed, err := playerService.AppealSanction(AppealSanctionRequest{
+		Identifier: "cheater", Password: "password123", SanctionID: ban.ID, Message: "it was lag",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.AppealStatusPending, *appealed.AppealStatus)
+	_, err = playerService.AppealSanction(AppealSanctionRequest{
+		Identifier: "cheater", Password: "password123", SanctionID: ban.ID, Message: "please",
+	})
+	assert.ErrorIs(t, err, ErrAppealAlreadySubmitted)
+
+	appeals, err := service.ListPendingAppeals()
+	require.NoError(t, err)
+	require.Len(t, appeals, 1)
+
+	lifted, err := service.LiftSanction(actor, ban.ID, LiftSanctionRequest{Reason: "appeal accepted"})
+	require.NoError(t, err)
+	assert.NotNil(t, lifted.LiftedAt)
+	assert.Equal(t, models.AppealStatusAccepted, *lifted.AppealStatus)
+
+	_, err = service.LiftSanction(actor, ban.ID, LiftSanctionRequest{Reason: "again"})
+	assert.ErrorIs(t, err, ErrSanctionNotActive)
+
+	_, err = playerService.Login(LoginRequest{Identifier: "cheater", Password: "password123"})
+	require.NoError(t, err)
+
+	var actions []string
+	require.NoError(t, db.Model(&models.AuditLog{}).Order("id").Pluck("action", &actions).Error)
+	assert.Equal(t, []string{
+		models.AuditActionSanctionIssued,
+		models.AuditActionSanctionAppealed,
+		models.AuditActionSanctionLifted,
+	}, actions)
+}
+
+func TestSanctionService_ActiveAccessSanctionIsCached(t *testing.T) {
+	db := setupGuestTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AuditLog{}))
+	service := NewSanctionService(db, auth.NewJWTService(), testAccountConfig.RefreshTokenTTL)
+
+	moderator := createPromoTestPlayer(t, db, "moderator")
+	player := createPromoTestPlayer(t, db, "suspect")
+	actor := AdminActor{PlayerID: moderator.ID}
+
+	active, err := service.ActiveAccessSanction(player.ID)
+	require.NoError(t, err)
+	assert.Nil(t, active)
+
+	// Lookups are served from the cache for a while
+	require.NoError(t, db.Create(&models.Sanction{PlayerID: player.ID, Type: models.SanctionBan, Reason: "elsewhere"}).Error)
+	active, err = service.ActiveAccessSanction(player.ID)
+	require.NoError(t, err)
+	assert.Nil(t, active)
+
+	// Issuing and lifting a sanction takes effect immediately
+	suspension, err := service.IssueSanction(actor, player.ID, IssueSanctionRequest{
+		Type: models.SanctionSuspension, Reason: "griefing", ExpiresAt: timePtr(time.Now().Add(time.Hour)),
+	})
+	require.NoError(t, err)
+	active, err = service.ActiveAccessSanction(player.ID)
+	require.NoError(t, err)
+	require.NotNil(t, active)
+
+	require.NoError(t, db.Where("reason = ?", "elsewhere").Delete(&models.Sanction{}).Error)
+	_, err = service.LiftSanction(actor, suspension.ID, LiftSanctionRequest{Reason: "mistake"})
+	require.NoError(t, err)
+	active, err = service.ActiveAccessSanction(player.ID)
+	require.NoError(t, err)
+	assert.Nil(t, active)
+
+	// A cached sanction never outlives its expiry
+	cache := newSanctionCache(time.Hour)
+	now := time.Now()
+	generation := cache.generation
+	cache.put(player.ID, &models.Sanction{ExpiresAt: timePtr(now.Add(time.Minute))}, generation, now)
+	_, _, ok := cache.get(player.ID, now.Add(2*time.Minute))
+	assert.False(t, ok)
+
+	// A lookup that raced an invalidation is not cached
+	cache.invalidate(player.ID)
+	cache.put(player.ID, nil, generation, now)
+	_, _, ok = cache.get(player.ID, now)
+	assert.False(t, ok)
+}
+
+func TestSanctionService_SuspensionAndHistory(t *testing.T) {
+	db := setupGuestTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AuditLog{}))
+	service := NewSanctionService(db, auth.NewJWTService(), testAccountConfig.RefreshTokenTTL)
+
+	moderator := createPromoTestPlayer(t, db, "moderator")
+	player := createPromoTestPlayer(t, db, "griefer")
+	actor := AdminActor{PlayerID: moderator.ID}
+
+	// Suspensions must end
+	_, err := service.IssueSanction(actor, player.ID, IssueSanctionRequest{Type: models.SanctionSuspension, Reason: "griefing"})
+	assert.ErrorIs(t, err, ErrInvalidSanction)
+	_, err = service.IssueSanction(actor, player.ID, IssueSanctionRequest{
+		Type: models.SanctionSuspension, Reason: "griefing", ExpiresAt: timePtr(time.Now().Add(-time.Hour)),
+	})
+	assert.ErrorIs(t, err, ErrInvalidSanction)
+
+	// Leaderboard exclusion does not lock the player out
+	_, err = service.IssueSanction(actor, player.ID, IssueSanctionRequest{Type: models.SanctionLeaderboardExclusion, Reason: "impossible score"})
+	require.NoError(t, err)
+	active, err := service.ActiveAccessSanction(player.ID)
+	require.NoError(t, err)
+	assert.Nil(t, active)
+
+	// An expired suspension no longer applies
+	suspension, err := service.IssueSanction(actor, player.ID, IssueSanctionRequest{
+		Type: models.SanctionSuspension, Reason: "griefing", ExpiresAt: timePtr(time.Now().Add(time.Hour)),
+	})
+	require.NoError(t, err)
+	active, err = service.ActiveAccessSanction(player.ID)
+	require.NoError(t, err)
+	require.NotNil(t, active)
+	assert.Equal(t, "account_suspended", active.Notice().Code)
+
+	require.NoError(t, db.Model(suspension).Update("expires_at", time.Now().Add(-time.Minute)).Error)
+	// The row changed behind the service's back, so drop what it cached
+	service.accessCache.invalidate(player.ID)
+	active, err = service.ActiveAccessSanction(player.ID)
+	require.NoError(t, err)
+	assert.Nil(t, active)
+
+	_, err = service.RejectAppeal(actor, suspension.ID, ResolveAppealRequest{})
+	assert.ErrorIs(t, err, ErrNoPendingAppeal)
+
+	history, err := service.ListPlayerSanctions(player.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, suspension.ID, history[0].ID)
+
+	_, err = service.ListPlayerSanctions(9999)
+	assert.ErrorIs(t, err, ErrPlayerNotFound)
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+)
+
+var (
+	ErrDeviceSessionNotFound = errors.New("session not found")
+)
+
+// DeviceInfo describes the client a login comes from
+type DeviceInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// SessionService lists and revokes a player's device sessions
+type SessionService struct {
+	db           *gorm.DB
+	tokenService *TokenService
+}
+
+// NewSessionService creates a new session service
+func NewSessionService(db *gorm.DB, jwtService *auth.JWTService, refreshTTL time.Duration) *SessionService {
+	return &SessionService{
+		db:           db,
+		tokenService: NewTokenService(db, jwtService, refreshTTL),
+	}
+}
+
+// ListSessions returns the player's active sessions, most recently used first.
+// The session with currentSessionID is flagged as the current one.
+func (s *SessionService) ListSessions(playerID uint, currentSessionID string) ([]models.DeviceSession, error) {
+	var sessions []models.DeviceSession
+	if err := s.db.Where("player_id = ? AND revoked_at IS NULL AND expires_at > ?", playerID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs one of the player's devices out
+func (s *SessionService) RevokeSession(playerID uint, sessionID string) error {
+	var session models.DeviceSession
+	if err := s.db.Where("id = ? AND player_id = ? AND revoked_at IS NULL", sessionID, playerID).
+		First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDeviceSessionNotFound
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return s.tokenService.revokeFamily(session.ID)
+}
+
+// RevokeOtherSessions signs every device out except the current one and returns how many were revoked
+func (s *SessionService) RevokeOtherSessions(playerID uint, currentSessionID string) (int, error) {
+	var ids []string
+	if err := s.db.Model(&models.DeviceSession{}).
+		Where("player_id = ? AND id <> ? AND revoked_at IS NULL", playerID, currentSessionID).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+
+	for _, id := range ids {
+		if err := s.tokenService.revokeFamily(id); err != nil {
+			return 0, err
+		}
+	}
+
+	// Refresh tokens from before sessions were tracked have no session row
+	if err := s.db.Model(&models.RefreshToken{}).
+		Where("player_id = ? AND family_id <> ? AND revoked_at IS NULL", playerID, currentSessionID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return len(ids), nil
+}
+
+// detectPlatform classifies a client from its user agent
+func detectPlatform(userAgent string) string {
+	switch {
+	case strings.Contains(userAgent, "Electron/"):
+		return models.PlatformElectron
+	case strings.Contains(userAgent, "Android"):
+		return models.PlatformAndroid
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"):
+		return models.PlatformIOS
+	case strings.HasPrefix(userAgent, "Mozilla/"):
+		return models.PlatformWeb
+	default:
+		return models.PlatformUnknown
+	}
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+)
+
+const (
+	electronUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) zombie-car-game/1.2.0 Chrome/120.0.6099.109 Electron/28.1.0 Safari/537.36"
+	browserUserAgent  = "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0"
+)
+
+func TestDetectPlatform(t *testing.T) {
+	assert.Equal(t, models.PlatformElectron, detectPlatform(electronUserAgent))
+	assert.Equal(t, models.PlatformWeb, detectPlatform(browserUserAgent))
+	assert.Equal(t, models.PlatformAndroid, detectPlatform("Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36"))
+	assert.Equal(t, models.PlatformIOS, detectPlatform("Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X)"))
+	assert.Equal(t, models.PlatformUnknown, detectPlatform("curl/8.5.0"))
+	assert.Equal(t, models.PlatformUnknown, detectPlatform(""))
+}
+
+func TestSessionService_ListAndRevoke(t *testing.T) {
+	db := setupTokenTestDB(t)
+	jwtService := auth.NewJWTService()
+	tokenService := NewTokenService(db, jwtService, testAccountConfig.RefreshTokenTTL)
+	service := &SessionService{db: db, tokenService: tokenService}
+
+	player := createPromoTestPlayer(t, db, "traveller")
+
+	desktop, err := tokenService.IssueTokens(player, DeviceInfo{UserAgent: electronUserAgent, IPAddress: "198.51.100.4"})
+	require.NoError(t, err)
+	browser, err := tokenService.IssueTokens(player, DeviceInfo{UserAgent: browserUserAgent, IPAddress: "203.0.113.9"})
+	require.NoError(t, err)
+	tablet, err := tokenService.IssueTokens(player, DeviceInfo{UserAgent: browserUserAgent, IPAddress: "203.0.113.10"})
+	require.NoError(t, err)
+
+	desktopClaims, err := jwtService.ValidateToken(desktop.AccessToken)
+	require.NoError(t, err)
+	require.NotEmpty(t, desktopClaims.SessionID)
+
+	sessions, err := service.ListSessions(player.ID, desktopClaims.SessionID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 3)
+	for _, session := range sessions {
+		if session.ID == desktopClaims.SessionID {
+			assert.True(t, session.Current)
+			assert.Equal(t, models.PlatformElectron, session.Platform)
+			assert.Equal(t, "198.51.100.4", session.IPAddress)
+		} else {
+			assert.False(t, session.Current)
+			assert.Equal(t, models.PlatformWeb, session.Platform)
+		}
+	}
+
+	// Revoking a session ends its access and refresh tokens at once
+	browserClaims, err := jwtService.ValidateToken(browser.AccessToken)
+	require.NoError(t, err)
+	require.NoError(t, service.RevokeSession(player.ID, browserClaims.SessionID))
+
+	_, err = jwtService.ValidateToken(browser.AccessToken)
+	assert.ErrorIs(t, err, auth.ErrRevokedToken)
+	_, _, err = tokenService.RotateRefreshToken(browser.RefreshToken, DeviceInfo{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	assert.ErrorIs(t, service.RevokeSession(player.ID, browserClaims.SessionID), ErrDeviceSessionNotFound)
+	assert.ErrorIs(t, service.RevokeSession(player.ID+1, desktopClaims.SessionID), ErrDeviceSessionNotFound)
+
+	// Sign out everywhere else keeps only the current device
+	revoked, err := service.RevokeOtherSessions(player.ID, desktopClaims.SessionID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, revoked)
+
+	_, err = jwtService.ValidateToken(tablet.AccessToken)
+	assert.ErrorIs(t, err, auth.ErrRevokedToken)
+	_, err = jwtService.ValidateToken(desktop.AccessToken)
+	assert.NoError(t, err)
+
+	// The surviving session keeps refreshing and records where it was last seen
+	_, _, err = tokenService.RotateRefreshToken(desktop.RefreshToken, DeviceInfo{IPAddress: "198.51.100.77"})
+	require.NoError(t, err)
+
+	sessions, err = service.ListSessions(player.ID, desktopClaims.SessionID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "198.51.100.77", sessions[0].IPAddress)
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/models"
+)
+
+// importSessionsPath is the online server's endpoint for sessions played offline
+const importSessionsPath = "/api/v1/game/sessions/import"
+
+// syncBatchSize matches the most sessions the online server accepts per request
+const syncBatchSize = 100
+
+var (
+	ErrSyncNotConfigured = errors.New("no online server to sync with")
+	ErrSyncUnauthorized  = errors.New("online server rejected the access token")
+	ErrSyncUnavailable   = errors.New("online server unavailable")
+)
+
+// SyncService uploads the sessions played against an offline server to the
+// online server once the player has logged in there
+type SyncService struct {
+	db      *gorm.DB
+	baseURL string
+	client  *http.Client
+}
+
+// NewSyncService creates a sync service uploading to the online server at baseURL
+func NewSyncService(db *gorm.DB, baseURL string) *SyncService {
+	return &SyncService{
+		db:      db,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// SyncResult summarises an upload to the online server
+type SyncResult struct {
+	Uploaded       int               `json:"uploaded"`
+	Rejected       []RejectedSession `json:"rejected"`
+	CurrencyEarned int               `json:"currency_earned"`
+}
+
+// importSessionsResponse mirrors the online server's import response body
+type importSessionsResponse struct {
+	Result ImportResult `json:"result"`
+}
+
+// Sync uploads the player's finished sessions that have not been uploaded yet,
+// authenticating to the online server with accessToken
+func (s *SyncService) Sync(ctx context.Context, playerID uint, accessToken string) (*SyncResult, error) {
+	if s.baseURL == "" {
+		return nil, ErrSyncNotConfigured
+	}
+
+	result := &SyncResult{Rejected: []RejectedSession{}}
+	for {
+		sessions, err := s.unsynced(playerID, syncBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(sessions) == 0 {
+			return result, nil
+		}
+
+		imported, err := s.upload(ctx, sessions, accessToken)
+		if err != nil {
+			return nil, err
+		}
+
+		// Rejected sessions are marked too: they would be rejected again on every sync
+		synced := append(append(imported.Imported, imported.Duplicates...), rejectedIDs(imported.Rejected)...)
+		if err := s.markSynced(synced); err != nil {
+			return nil, err
+		}
+
+		result.Uploaded += len(imported.Imported) + len(imported.Duplicates)
+		result.Rejected = append(result.Rejected, imported.Rejected...)
+		result.CurrencyEarned += imported.CurrencyEarned
+
+		// Stop rather than loop forever if the server answered for none of the batch
+		if len(synced) == 0 || len(sessions) < syncBatchSize {
+			return result, nil
+		}
+	}
+}
+
+// Pending counts the player's finished sessions that have not been uploaded yet
+func (s *SyncService) Pending(playerID uint) (int64, error) {
+	var count int64
+	if err := s.unsyncedQuery(playerID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	return count, nil
+}
+
+// unsyncedQuery selects the player's finished sessions missing from synced_sessions
+func (s *SyncService) unsyncedQuery(playerID uint) *gorm.DB {
+	return s.db.Model(&models.GameSession{}).
+		Where("player_id = ? AND session_state <> ? AND ended_at IS NOT NULL", playerID, models.SessionStateActive).
+		Where("NOT EXISTS (SELECT 1 FROM synced_sessions WHERE synced_sessions.session_id = game_sessions.id)")
+}
+
+// unsynced returns up to limit of the player's sessions still to upload, oldest first
+func (s *SyncService) unsynced(playerID uint, limit int) ([]models.GameSession, error) {
+	var sessions []models.GameSession
+	if err := s.unsyncedQuery(playerID).Order("started_at").Limit(limit).Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return sessions, nil
+}
+
+// upload posts sessions to the online server's import endpoint
+func (s *SyncService) upload(ctx context.Context, sessions []models.GameSession, accessToken string) (*ImportResult, error) {
+	body := ImportSessionsRequest{Sessions: make([]ImportedSession, 0, len(sessions))}
+	for _, session := range sessions {
+		body.Sessions = append(body.Sessions, ImportedSession{
+			ID:               session.ID,
+			LevelID:          session.LevelID,
+			Score:            session.Score,
+			ZombiesKilled:    session.ZombiesKilled,
+			DistanceTraveled: session.DistanceTraveled,
+			SessionState:     string(session.SessionState),
+			StartedAt:        session.StartedAt,
+			EndedAt:          *session.EndedAt,
+		})
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode sessions: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+importSessionsPath, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build sync request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSyncUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, ErrSyncUnauthorized
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%w: online server returned status %d", ErrSyncUnavailable, resp.StatusCode)
+	}
+
+	var decoded importSessionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("%w: malformed import response", ErrSyncUnavailable)
+	}
+	return &decoded.Result, nil
+}
+
+// markSynced records that the online server has answered for ids
+func (s *SyncService) markSynced(ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	records := make([]models.SyncedSession, 0, len(ids))
+	for _, id := range ids {
+		records = append(records, models.SyncedSession{SessionID: id, SyncedAt: now})
+	}
+	if err := s.db.Create(&records).Error; err != nil {
+		return fmt.Errorf("failed to record synced sessions: %w", err)
+	}
+	return nil
+}
+
+// rejectedIDs returns the IDs of rejected sessions
+func rejectedIDs(rejected []RejectedSession) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(rejected))
+	for _, session := range rejected {
+		ids = append(ids, session.ID)
+	}
+	return ids
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+)
+
+// newOnlineServer serves the import endpoint from a database of its own,
+// importing for onlinePlayerID when the request carries token
+func newOnlineServer(t *testing.T, online *gorm.DB, onlinePlayerID uint, token string) *httptest.Server {
+	gameStateService := NewGameStateService(online, NewPlayerService(online, auth.NewJWTService(), testAccountConfig))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != importSessionsPath {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req ImportSessionsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		result, err := gameStateService.ImportSessions(onlinePlayerID, req)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": "Sessions imported successfully",
+			"result":  result,
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSyncService_Sync(t *testing.T) {
+	local := openTestDB(t, &models.Player{}, &models.GameSession{}, &models.SyncedSession{})
+	online := setupGameStateTestDB(t)
+
+	localPlayer := &models.Player{Username: "localplayer", Email: "local@example.com", PasswordHash: "hashedpassword"}
+	require.NoError(t, local.Create(localPlayer).Error)
+	onlinePlayer := createTestPlayerForGameState(t, online, 0)
+
+	server := newOnlineServer(t, online, onlinePlayer.ID, "online-token")
+	syncService := NewSyncService(local, server.URL+"/")
+
+	// Each session is played in the ten minutes after the previous one, as the
+	// online server rejects overlapping sessions
+	startedAt := time.Now().Add(-time.Hour)
+	finished := func(score int, state models.SessionState) *models.GameSession {
+		endedAt := startedAt.Add(5 * time.Minute)
+		session := &models.GameSession{
+			PlayerID:     localPlayer.ID,
+			LevelID:      "level_1",
+			Score:        score,
+			SessionState: state,
+			StartedAt:    startedAt,
+			EndedAt:      &endedAt,
+		}
+		startedAt = startedAt.Add(10 * time.Minute)
+		require.NoError(t, local.Create(session).Error)
+		return session
+	}
+	completed := finished(1000, models.SessionStateCompleted)
+	finished(500, models.SessionStateFailed)
+	cheated := finished(10000000, models.SessionStateCompleted)
+	require.NoError(t, local.Create(&models.GameSession{
+		PlayerID:     localPlayer.ID,
+		LevelID:      "level_2",
+		SessionState: models.SessionStateActive,
+		StartedAt:    time.Now(),
+	}).Error)
+
+	pending, err := syncService.Pending(localPlayer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), pending)
+
+	t.Run("rejected access token", func(t *testing.T) {
+		_, err := syncService.Sync(context.Background(), localPlayer.ID, "expired-token")
+
+		assert.ErrorIs(t, err, ErrSyncUnauthorized)
+
+		pending, err := syncService.Pending(localPlayer.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), pending)
+	})
+
+	t.Run("uploads finished sessions once", func(t *testing.T) {
+		result, err := syncService.Sync(context.Background(), localPlayer.ID, "online-token")
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Uploaded)
+		assert.Equal(t, 150, result.CurrencyEarned)
+		require.Len(t, result.Rejected, 1)
+		assert.Equal(t, cheated.ID, result.Rejected[0].ID)
+
+		var imported models.GameSession
+		require.NoError(t, online.First(&imported, "id = ?", completed.ID).Error)
+		assert.Equal(t, onlinePlayer.ID, imported.PlayerID)
+
+		pending, err := syncService.Pending(localPlayer.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), pending)
+
+		again, err := syncService.Sync(context.Background(), localPlayer.ID, "online-token")
+		require.NoError(t, err)
+		assert.Equal(t, 0, again.Uploaded)
+	})
+
+	t.Run("no online server configured", func(t *testing.T) {
+		_, err := NewSyncService(local, "").Sync(context.Background(), localPlayer.ID, "online-token")
+
+		assert.ErrorIs(t, err, ErrSyncNotConfigured)
+	})
+
+	t.Run("online server unavailable", func(t *testing.T) {
+		finished(100, models.SessionStateFailed)
+		unreachable := httptest.NewServer(http.NotFoundHandler())
+		unreachable.Close()
+
+		_, err := NewSyncService(local, unreachable.URL).Sync(context.Background(), localPlayer.ID, "online-token")
+
+		assert.ErrorIs(t, err, ErrSyncUnavailable)
+	})
+}
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/database/dbtest"
+)
+
+// testAccountConfig is the account configuration of services under test
+var testAccountConfig = AccountConfig{
+	RefreshTokenTTL: 30 * 24 * time.Hour,
+	AppBaseURL:      "http://localhost:3000",
+	TOTPIssuer:      "Zombie Car Game",
+}
+
+// openTestDB opens an empty database with the schema of the given models, on
+// SQLite or on the Postgres database TEST_DB_DRIVER and TEST_DB_DSN select
+func openTestDB(t *testing.T, schema ...interface{}) *gorm.DB {
+	t.Helper()
+	return dbtest.Open(t, schema...)
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+// opaqueTokenBytes is the entropy of refresh and account tokens
+const opaqueTokenBytes = 32
+
+// TokenService issues access tokens and rotates server-side refresh tokens
+type TokenService struct {
+	db         *gorm.DB
+	jwtService *auth.JWTService
+	refreshTTL time.Duration
+}
+
+// NewTokenService creates a new token service whose refresh tokens expire after
+// refreshTTL without use
+func NewTokenService(db *gorm.DB, jwtService *auth.JWTService, refreshTTL time.Duration) *TokenService {
+	return &TokenService{
+		db:         db,
+		jwtService: jwtService,
+		refreshTTL: refreshTTL,
+	}
+}
+
+// TokenPair represents an access token and the refresh token that renews it
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+}
+
+// IssueTokens starts a new device session and refresh token family for a fresh login
+func (s *TokenService) IssueTokens(player *models.Player, device DeviceInfo) (*TokenPair, error) {
+	now := time.Now()
+	session := models.DeviceSession{
+		ID:         uuid.NewString(),
+		PlayerID:   player.ID,
+		UserAgent:  truncate(device.UserAgent, 255),
+		IPAddress:  device.IPAddress,
+		Platform:   detectPlatform(device.UserAgent),
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(s.refreshTTL),
+	}
+	if err := s.db.Create(&session).Error; err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return s.issueTokens(player, session.ID)
+}
+
+// RotateRefreshToken exchanges a refresh token for a new token pair.
+//
+// Each refresh token can be used exactly once. Presenting a token that was
+// already rotated means it was copied, so the whole family is revoked and the
+// player has to log in again.
+func (s *TokenService) RotateRefreshToken(refreshToken string, device DeviceInfo) (*TokenPair, *models.Player, error) {
+	var stored models.RefreshToken
+	if err := s.db.Where("token_hash = ?", hashOpaqueToken(refreshToken)).First(&stored).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+		return nil, nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if stored.RevokedAt != nil || stored.IsExpired(time.Now()) {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	// Mark the token used; losing this race is treated the same as replaying it
+	result := s.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", stored.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return nil, nil, fmt.Errorf("failed to rotate refresh token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		if err := s.revokeFamily(stored.FamilyID); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, ErrRefreshTokenReused
+	}
+
+	var player models.Player
+	if err := s.db.First(&player, stored.PlayerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+		return nil, nil, fmt.Errorf("database error: %w", err)
+	}
+
+	pair, err := s.issueTokens(&player, stored.FamilyID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Access tokens are short-lived, so refreshes are a cheap and frequent enough "last seen"
+	seen := map[string]interface{}{
+		"last_seen_at": time.Now(),
+		"expires_at":   time.Now().Add(s.refreshTTL),
+	}
+	if device.IPAddress != "" {
+		seen["ip_address"] = device.IPAddress
+	}
+	if err := s.db.Model(&models.DeviceSession{}).Where("id = ?", stored.FamilyID).Updates(seen).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to update session: %w", err)
+	}
+
+	return pair, &player, nil
+}
+
+// RevokeRefreshToken revokes the family a refresh token belongs to
+func (s *TokenService) RevokeRefreshToken(refreshToken string) error {
+	var stored models.RefreshToken
+	if err := s.db.Where("token_hash = ?", hashOpaqueToken(refreshToken)).First(&stored).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return s.revokeFamily(stored.FamilyID)
+}
+
+// RevokeAllForPlayer revokes every outstanding refresh token and device session of a player
+func (s *TokenService) RevokeAllForPlayer(playerID uint) error {
+	if err := s.db.Model(&models.RefreshToken{}).
+		Where("player_id = ? AND revoked_at IS NULL", playerID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	if err := s.db.Model(&models.DeviceSession{}).
+		Where("player_id = ? AND revoked_at IS NULL", playerID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// RevokeAccessToken denylists an access token until it expires
+func (s *TokenService) RevokeAccessToken(claims *auth.Claims) error {
+	if err := s.jwtService.RevokeToken(claims); err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+	return nil
+}
+
+// issueTokens signs an access token and stores a new refresh token in the given family
+func (s *TokenService) issueTokens(player *models.Player, familyID string) (*TokenPair, error) {
+	accessToken, err := s.jwtService.GenerateSessionToken(player.ID, player.Username, player.Role, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	refreshToken, err := generateOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	stored := models.RefreshToken{
+		PlayerID:  player.ID,
+		FamilyID:  familyID,
+		TokenHash: hashOpaqueToken(refreshToken),
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}
+	if err := s.db.Create(&stored).Error; err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.jwtService.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+// revokeFamily ends a login: its refresh tokens, its device session and the access tokens issued for it
+func (s *TokenService) revokeFamily(familyID string) error {
+	if err := s.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to revoke token family: %w", err)
+	}
+	if err := s.db.Model(&models.DeviceSession{}).
+		Where("id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if err := s.jwtService.RevokeSession(familyID); err != nil {
+		return fmt.Errorf("failed to revoke session tokens: %w", err)
+	}
+	return nil
+}
+
+// generateOpaqueToken creates a random URL-safe token
+func generateOpaqueToken() (string, error) {
+	buf := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashOpaqueToken returns the stored form of an opaque token
+func hashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
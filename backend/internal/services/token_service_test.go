@@ -0,0 +1,111 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+)
+
+func setupTokenTestDB(t *testing.T) *gorm.DB {
+	return openTestDB(t, &models.Player{}, &models.RefreshToken{}, &models.DeviceSession{}, &models.Sanction{}, &models.AccountToken{}, &models.OutboxEmail{})
+}
+
+func TestTokenService_RotateRefreshToken(t *testing.T) {
+	db := setupTokenTestDB(t)
+	service := NewTokenService(db, auth.NewJWTService(), testAccountConfig.RefreshTokenTTL)
+	player := createPromoTestPlayer(t, db, "rotator")
+
+	pair, err := service.IssueTokens(player, DeviceInfo{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, pair.AccessToken)
+	assert.NotEmpty(t, pair.RefreshToken)
+	assert.Greater(t, pair.ExpiresIn, int64(0))
+
+	rotated, rotatedPlayer, err := service.RotateRefreshToken(pair.RefreshToken, DeviceInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, player.ID, rotatedPlayer.ID)
+	assert.NotEqual(t, pair.RefreshToken, rotated.RefreshToken)
+
+	// The new token stays in the same family as the original login
+	var tokens []models.RefreshToken
+	require.NoError(t, db.Order("id").Find(&tokens).Error)
+	require.Len(t, tokens, 2)
+	assert.Equal(t, tokens[0].FamilyID, tokens[1].FamilyID)
+	assert.NotNil(t, tokens[0].UsedAt)
+	assert.Nil(t, tokens[1].UsedAt)
+
+	_, _, err = service.RotateRefreshToken("not-a-token", DeviceInfo{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestTokenService_RotateRefreshToken_ReuseRevokesFamily(t *testing.T) {
+	db := setupTokenTestDB(t)
+	service := NewTokenService(db, auth.NewJWTService(), testAccountConfig.RefreshTokenTTL)
+	player := createPromoTestPlayer(t, db, "replayer")
+
+	pair, err := service.IssueTokens(player, DeviceInfo{})
+	require.NoError(t, err)
+	other, err := service.IssueTokens(player, DeviceInfo{})
+	require.NoError(t, err)
+
+	rotated, _, err := service.RotateRefreshToken(pair.RefreshToken, DeviceInfo{})
+	require.NoError(t, err)
+
+	// Replaying the already-rotated token kills the whole chain
+	_, _, err = service.RotateRefreshToken(pair.RefreshToken, DeviceInfo{})
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	_, _, err = service.RotateRefreshToken(rotated.RefreshToken, DeviceInfo{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	// Logins from other devices are unaffected
+	_, _, err = service.RotateRefreshToken(other.RefreshToken, DeviceInfo{})
+	assert.NoError(t, err)
+}
+
+func TestTokenService_RevokeRefreshToken(t *testing.T) {
+	db := setupTokenTestDB(t)
+	service := NewTokenService(db, auth.NewJWTService(), testAccountConfig.RefreshTokenTTL)
+	player := createPromoTestPlayer(t, db, "leaver")
+
+	pair, err := service.IssueTokens(player, DeviceInfo{})
+	require.NoError(t, err)
+
+	require.NoError(t, service.RevokeRefreshToken(pair.RefreshToken))
+
+	_, _, err = service.RotateRefreshToken(pair.RefreshToken, DeviceInfo{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	assert.ErrorIs(t, service.RevokeRefreshToken("unknown"), ErrInvalidRefreshToken)
+}
+
+func TestPlayerService_Logout_RevokesAccessToken(t *testing.T) {
+	db := setupTokenTestDB(t)
+	jwtService := auth.NewJWTService()
+	service := NewPlayerService(db, jwtService, testAccountConfig)
+
+	response, err := service.CreatePlayer(CreatePlayerRequest{
+		Username: "logoutuser",
+		Email:    "logout@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+
+	claims, err := jwtService.ValidateToken(response.Token)
+	require.NoError(t, err)
+
+	require.NoError(t, service.Logout(response.RefreshToken, claims))
+
+	_, err = jwtService.ValidateToken(response.Token)
+	assert.ErrorIs(t, err, auth.ErrRevokedToken)
+
+	_, err = service.RefreshToken(response.RefreshToken, DeviceInfo{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	// Logging out twice is harmless
+	assert.NoError(t, service.Logout(response.RefreshToken, claims))
+}
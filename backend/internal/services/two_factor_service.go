@@ -0,0 +1,357 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled       = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotEnrolled      = errors.New("no pending two-factor enrollment")
+	ErrInvalidTwoFactorCode      = errors.New("invalid two-factor code")
+	ErrInvalidTwoFactorChallenge = errors.New("invalid or expired two-factor challenge")
+)
+
+const (
+	// twoFactorChallengeTTL is how long a player has to enter their code after the password step
+	twoFactorChallengeTTL = 5 * time.Minute
+	// totpAllowedSkew accepts codes from one step either side to absorb clock drift
+	totpAllowedSkew = 1
+	// recoveryCodeCount is how many backup codes a player receives at a time
+	recoveryCodeCount = 10
+)
+
+// recoveryCodeEncoding avoids padding so codes are easy to read out and type
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TwoFactorEnrollment carries the secret to load into an authenticator app
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorCodeRequest carries a TOTP code or a recovery code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorLoginRequest completes a login that was answered with a challenge
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+	// IPAddress is filled in by the handler for brute-force protection and the device session
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// DisableTwoFactorRequest re-authenticates the player before 2FA is turned off
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// BeginTwoFactorEnrollment creates a new unconfirmed TOTP secret for the player.
+// Starting again replaces an enrollment that was never confirmed.
+func (s *PlayerService) BeginTwoFactorEnrollment(playerID uint) (*TwoFactorEnrollment, error) {
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	var player models.Player
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&player, playerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPlayerNotFound
+			}
+			return fmt.Errorf("database error: %w", err)
+		}
+
+		if player.IsGuest {
+			return ErrGuestAccount
+		}
+		if player.TwoFactorEnabled {
+			return ErrTwoFactorAlreadyEnabled
+		}
+
+		if err := tx.Where("player_id = ?", playerID).Delete(&models.TwoFactorCredential{}).Error; err != nil {
+			return fmt.Errorf("failed to reset enrollment: %w", err)
+		}
+
+		credential := models.TwoFactorCredential{PlayerID: playerID, Secret: secret}
+		if err := tx.Create(&credential).Error; err != nil {
+			return fmt.Errorf("failed to store secret: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(s.totpIssuer, player.Email, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor enables 2FA once the player enters a code from the new secret.
+// It returns the recovery codes, which are shown only this once.
+func (s *PlayerService) ConfirmTwoFactor(playerID uint, code string, ipAddress string) ([]string, error) {
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var credential models.TwoFactorCredential
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("player_id = ? AND confirmed_at IS NULL", playerID).
+			First(&credential).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTwoFactorNotEnrolled
+			}
+			return fmt.Errorf("database error: %w", err)
+		}
+
+		step, ok := auth.ValidateTOTP(credential.Secret, normalizeTwoFactorCode(code), time.Now(), totpAllowedSkew)
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+
+		if err := tx.Model(&credential).Updates(map[string]interface{}{
+			"confirmed_at":   time.Now(),
+			"last_used_step": step,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to confirm enrollment: %w", err)
+		}
+		if err := tx.Model(&models.Player{}).Where("id = ?", playerID).
+			Update("two_factor_enabled", true).Error; err != nil {
+			return fmt.Errorf("failed to enable two-factor authentication: %w", err)
+		}
+
+		var err error
+		if codes, err = replaceRecoveryCodes(tx, playerID); err != nil {
+			return err
+		}
+
+		return recordAudit(tx, AuditEntry{
+			Action:     models.AuditActionTwoFactorEnabled,
+			ActorID:    &playerID,
+			TargetType: "player",
+			TargetID:   strconv.FormatUint(uint64(playerID), 10),
+			IPAddress:  ipAddress,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes; the old ones stop working
+func (s *PlayerService) RegenerateRecoveryCodes(playerID uint, code string) ([]string, error) {
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := verifyTwoFactorCode(tx, playerID, code); err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(tx, playerID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableTwoFactor turns 2FA off. Both the password and a current code are required
+// so a stolen session alone cannot strip the protection.
+func (s *PlayerService) DisableTwoFactor(playerID uint, req DisableTwoFactorRequest, ipAddress string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var player models.Player
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&player, playerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPlayerNotFound
+			}
+			return fmt.Errorf("database error: %w", err)
+		}
+
+		if !player.TwoFactorEnabled {
+			return ErrTwoFactorNotEnabled
+		}
+		if err := s.passwordService.VerifyPassword(player.PasswordHash, req.Password); err != nil {
+			return ErrInvalidCredentials
+		}
+		if err := verifyTwoFactorCode(tx, playerID, req.Code); err != nil {
+			return err
+		}
+
+		if err := deleteTwoFactorData(tx, playerID); err != nil {
+			return err
+		}
+
+		return recordAudit(tx, AuditEntry{
+			Action:     models.AuditActionTwoFactorDisabled,
+			ActorID:    &playerID,
+			TargetType: "player",
+			TargetID:   strconv.FormatUint(uint64(playerID), 10),
+			IPAddress:  ipAddress,
+		})
+	})
+}
+
+// CompleteTwoFactorLogin exchanges a login challenge and a valid code for tokens.
+// Wrong codes count as failed logins, so the login throttle also limits code guessing.
+func (s *PlayerService) CompleteTwoFactorLogin(req TwoFactorLoginRequest) (*AuthResponse, error) {
+	var player models.Player
+	var attempt *LoginAttempt
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		challenge, err := redeemAccountToken(tx, req.ChallengeToken, models.AccountTokenTwoFactorLogin)
+		if err != nil {
+			if errors.Is(err, ErrInvalidAccountToken) {
+				return ErrInvalidTwoFactorChallenge
+			}
+			return err
+		}
+
+		if err := tx.First(&player, challenge.PlayerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidTwoFactorChallenge
+			}
+			return fmt.Errorf("database error: %w", err)
+		}
+
+		if attempt, err = s.loginThrottle.Reserve(player.Username, req.IPAddress); err != nil {
+			return err
+		}
+
+		// A rejected code rolls the transaction back, leaving the challenge usable for a retry
+		return verifyTwoFactorCode(tx, player.ID, req.Code)
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			s.recordLoginFailure(attempt, player.Username, req.IPAddress)
+		} else if attempt != nil {
+			attempt.Release()
+		}
+		return nil, err
+	}
+
+	attempt.Release()
+	s.loginThrottle.RecordSuccess(player.Username)
+
+	return s.newAuthResponse(&player, DeviceInfo{UserAgent: req.UserAgent, IPAddress: req.IPAddress})
+}
+
+// newTwoFactorChallenge answers a correct password with a challenge instead of tokens
+func (s *PlayerService) newTwoFactorChallenge(player *models.Player) (*AuthResponse, error) {
+	// A banned player is told so before being asked for a code
+	if err := s.checkAccessSanction(player.ID); err != nil {
+		return nil, err
+	}
+
+	token, err := createAccountToken(s.db, player.ID, models.AccountTokenTwoFactorLogin, twoFactorChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+		ExpiresIn:         int64(twoFactorChallengeTTL.Seconds()),
+	}, nil
+}
+
+// verifyTwoFactorCode accepts a current TOTP code or consumes an unused recovery code.
+// A TOTP code is accepted only once, so an observed code cannot be replayed.
+func verifyTwoFactorCode(tx *gorm.DB, playerID uint, code string) error {
+	code = normalizeTwoFactorCode(code)
+
+	var credential models.TwoFactorCredential
+	if err := tx.Where("player_id = ? AND confirmed_at IS NOT NULL", playerID).First(&credential).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTwoFactorNotEnabled
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	if step, ok := auth.ValidateTOTP(credential.Secret, code, time.Now(), totpAllowedSkew); ok {
+		result := tx.Model(&models.TwoFactorCredential{}).
+			Where("id = ? AND last_used_step < ?", credential.ID, step).
+			Update("last_used_step", step)
+		if result.Error != nil {
+			return fmt.Errorf("failed to record code use: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	result := tx.Model(&models.RecoveryCode{}).
+		Where("player_id = ? AND code_hash = ? AND used_at IS NULL", playerID, hashOpaqueToken(code)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to redeem recovery code: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// replaceRecoveryCodes discards a player's recovery codes and stores a fresh set
+func replaceRecoveryCodes(tx *gorm.DB, playerID uint) ([]string, error) {
+	if err := tx.Where("player_id = ?", playerID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	rows := make([]models.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		encoded := strings.ToLower(recoveryCodeEncoding.EncodeToString(raw))
+		codes[i] = encoded[:4] + "-" + encoded[4:]
+		rows[i] = models.RecoveryCode{PlayerID: playerID, CodeHash: hashOpaqueToken(encoded)}
+	}
+
+	if err := tx.Create(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+// deleteTwoFactorData removes the player's TOTP secret and recovery codes and clears the flag
+func deleteTwoFactorData(tx *gorm.DB, playerID uint) error {
+	for _, model := range []interface{}{&models.TwoFactorCredential{}, &models.RecoveryCode{}} {
+		if err := tx.Where("player_id = ?", playerID).Delete(model).Error; err != nil {
+			return fmt.Errorf("failed to delete two-factor data: %w", err)
+		}
+	}
+
+	if err := tx.Model(&models.Player{}).Where("id = ?", playerID).
+		Update("two_factor_enabled", false).Error; err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+	return nil
+}
+
+// normalizeTwoFactorCode strips the separators people type or paste along with a code
+func normalizeTwoFactorCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer(" ", "", "-", "").Replace(code)
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"zombie-car-game-backend/internal/auth"
+	"zombie-car-game-backend/internal/models"
+)
+
+func TestPlayerService_TwoFactorLogin(t *testing.T) {
+	db := setupAccountTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.TwoFactorCredential{}, &models.RecoveryCode{}, &models.AuditLog{}))
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
+
+	registered, err := service.CreatePlayer(CreatePlayerRequest{
+		Username: "collector",
+		Email:    "collector@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+	playerID := registered.Player.ID
+
+	enrollment, err := service.BeginTwoFactorEnrollment(playerID)
+	require.NoError(t, err)
+	uri, err := url.Parse(enrollment.ProvisioningURI)
+	require.NoError(t, err)
+	assert.Equal(t, enrollment.Secret, uri.Query().Get("secret"))
+
+	_, err = service.ConfirmTwoFactor(playerID, "000000", "")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+
+	step := auth.TOTPStep(time.Now())
+	code, err := auth.TOTPCode(enrollment.Secret, step)
+	require.NoError(t, err)
+	recoveryCodes, err := service.ConfirmTwoFactor(playerID, code, "")
+	require.NoError(t, err)
+	require.Len(t, recoveryCodes, recoveryCodeCount)
+
+	_, err = service.BeginTwoFactorEnrollment(playerID)
+	assert.ErrorIs(t, err, ErrTwoFactorAlreadyEnabled)
+
+	// The password alone only yields a challenge
+	challenge, err := service.Login(LoginRequest{Identifier: "collector", Password: "password123"})
+	require.NoError(t, err)
+	assert.True(t, challenge.TwoFactorRequired)
+	assert.NotEmpty(t, challenge.ChallengeToken)
+	assert.Empty(t, challenge.Token)
+	assert.Empty(t, challenge.RefreshToken)
+
+	// A code that was already used is refused, the challenge survives the failure
+	_, err = service.CompleteTwoFactorLogin(TwoFactorLoginRequest{ChallengeToken: challenge.ChallengeToken, Code: code})
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+
+	next, err := auth.TOTPCode(enrollment.Secret, step+1)
+	require.NoError(t, err)
+	loggedIn, err := service.CompleteTwoFactorLogin(TwoFactorLoginRequest{ChallengeToken: challenge.ChallengeToken, Code: next})
+	require.NoError(t, err)
+	assert.NotEmpty(t, loggedIn.Token)
+	assert.Equal(t, playerID, loggedIn.Player.ID)
+
+	_, err = service.CompleteTwoFactorLogin(TwoFactorLoginRequest{ChallengeToken: challenge.ChallengeToken, Code: next})
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorChallenge)
+
+	// Recovery codes work once, with or without the dash
+	challenge, err = service.Login(LoginRequest{Identifier: "collector", Password: "password123"})
+	require.NoError(t, err)
+	_, err = service.CompleteTwoFactorLogin(TwoFactorLoginRequest{ChallengeToken: challenge.ChallengeToken, Code: recoveryCodes[0]})
+	require.NoError(t, err)
+
+	challenge, err = service.Login(LoginRequest{Identifier: "collector", Password: "password123"})
+	require.NoError(t, err)
+	_, err = service.CompleteTwoFactorLogin(TwoFactorLoginRequest{ChallengeToken: challenge.ChallengeToken, Code: recoveryCodes[0]})
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+
+	// Disabling needs both the password and a code
+	err = service.DisableTwoFactor(playerID, DisableTwoFactorRequest{Password: "wrong", Code: recoveryCodes[1]}, "")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	err = service.DisableTwoFactor(playerID, DisableTwoFactorRequest{Password: "password123", Code: "zzzz-zzzz"}, "")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	require.NoError(t, service.DisableTwoFactor(playerID, DisableTwoFactorRequest{
+		Password: "password123",
+		Code:     strings.ReplaceAll(recoveryCodes[1], "-", ""),
+	}, ""))
+
+	direct, err := service.Login(LoginRequest{Identifier: "collector", Password: "password123"})
+	require.NoError(t, err)
+	assert.False(t, direct.TwoFactorRequired)
+	assert.NotEmpty(t, direct.Token)
+
+	var audits int64
+	require.NoError(t, db.Model(&models.AuditLog{}).
+		Where("action IN ?", []string{models.AuditActionTwoFactorEnabled, models.AuditActionTwoFactorDisabled}).
+		Count(&audits).Error)
+	assert.Equal(t, int64(2), audits)
+}
+
+func TestPlayerService_TwoFactorCodeGuessingIsThrottled(t *testing.T) {
+	db := setupAccountTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.TwoFactorCredential{}, &models.RecoveryCode{}, &models.AuditLog{}))
+	service := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
+	service.loginThrottle.username.FreeAttempts = 2
+
+	registered, err := service.CreatePlayer(CreatePlayerRequest{
+		Username: "target",
+		Email:    "target@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+
+	enrollment, err := service.BeginTwoFactorEnrollment(registered.Player.ID)
+	require.NoError(t, err)
+	code, err := auth.TOTPCode(enrollment.Secret, auth.TOTPStep(time.Now()))
+	require.NoError(t, err)
+	_, err = service.ConfirmTwoFactor(registered.Player.ID, code, "")
+	require.NoError(t, err)
+
+	challenge, err := service.Login(LoginRequest{Identifier: "target", Password: "password123"})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = service.CompleteTwoFactorLogin(TwoFactorLoginRequest{ChallengeToken: challenge.ChallengeToken, Code: "abcd-efgh"})
+		assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	}
+
+	_, err = service.CompleteTwoFactorLogin(TwoFactorLoginRequest{ChallengeToken: challenge.ChallengeToken, Code: "abcd-efgh"})
+	assert.ErrorIs(t, err, ErrTooManyLoginAttempts)
+}
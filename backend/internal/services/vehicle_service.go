@@ -3,9 +3,11 @@ package services
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/metrics"
 	"zombie-car-game-backend/internal/models"
 )
 
@@ -56,12 +58,18 @@ type VehicleStats struct {
 // PurchaseVehicleRequest represents the request to purchase a vehicle
 type PurchaseVehicleRequest struct {
 	VehicleType string `json:"vehicle_type" binding:"required"`
+	// IPAddress and RequestID are filled in by the handler for the audit log
+	IPAddress string `json:"-"`
+	RequestID string `json:"-"`
 }
 
 // UpgradeVehicleRequest represents the request to upgrade a vehicle
 type UpgradeVehicleRequest struct {
 	VehicleID   uint   `json:"vehicle_id" binding:"required"`
 	UpgradeType string `json:"upgrade_type" binding:"required,oneof=engine armor weapons fuel tires"`
+	// IPAddress and RequestID are filled in by the handler for the audit log
+	IPAddress string `json:"-"`
+	RequestID string `json:"-"`
 }
 
 // VehicleResponse represents a vehicle with calculated stats
@@ -84,7 +92,8 @@ func (s *VehicleService) GetPlayerVehicles(playerID uint) ([]VehicleResponse, er
 		return nil, fmt.Errorf("failed to get player vehicles: %w", err)
 	}
 
-	var response []VehicleResponse
+	// Empty rather than nil, so a player without vehicles gets [] rather than null
+	response := make([]VehicleResponse, 0, len(ownedVehicles))
 	for _, vehicle := range ownedVehicles {
 		config, exists := vehicleConfigs[vehicle.VehicleType]
 		if !exists {
@@ -134,20 +143,6 @@ func (s *VehicleService) PurchaseVehicle(playerID uint, req PurchaseVehicleReque
 		return nil, fmt.Errorf("player level %d required, current level %d", config.UnlockLevel, player.Level)
 	}
 
-	// Start transaction
-	tx := s.db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
-	// Deduct currency
-	if err := s.playerService.UpdatePlayerCurrency(playerID, -config.Cost); err != nil {
-		tx.Rollback()
-		return nil, err
-	}
-
 	// Create owned vehicle
 	ownedVehicle := models.OwnedVehicle{
 		PlayerID:    playerID,
@@ -162,15 +157,38 @@ func (s *VehicleService) PurchaseVehicle(playerID uint, req PurchaseVehicleReque
 		PurchasedAt: time.Now(),
 	}
 
-	if err := tx.Create(&ownedVehicle).Error; err != nil {
-		tx.Rollback()
-		return nil, fmt.Errorf("failed to create owned vehicle: %w", err)
-	}
+	// Deduct currency, hand over the vehicle and audit the purchase together
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		before, after, err := changeCurrency(tx, playerID, -config.Cost, "vehicle_purchase", req.VehicleType)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Create(&ownedVehicle).Error; err != nil {
+			return fmt.Errorf("failed to create owned vehicle: %w", err)
+		}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return recordAudit(tx, AuditEntry{
+			Action:     models.AuditActionVehiclePurchased,
+			ActorID:    &playerID,
+			TargetType: "player",
+			TargetID:   strconv.FormatUint(uint64(playerID), 10),
+			Before:     map[string]interface{}{"currency": before},
+			After: map[string]interface{}{
+				"currency":     after,
+				"vehicle_id":   ownedVehicle.ID,
+				"vehicle_type": ownedVehicle.VehicleType,
+			},
+			Details:   map[string]interface{}{"cost": config.Cost},
+			RequestID: req.RequestID,
+			IPAddress: req.IPAddress,
+		})
+	})
+	if err != nil {
+		return nil, err
 	}
+	metrics.VehiclePurchased(req.VehicleType)
+	metrics.CurrencySpent(string(models.CurrencySoft), "vehicle_purchase", config.Cost)
 
 	// Return vehicle response
 	response := &VehicleResponse{
@@ -224,32 +242,41 @@ func (s *VehicleService) UpgradeVehicle(playerID uint, req UpgradeVehicleRequest
 		return nil, ErrInsufficientFunds
 	}
 
-	// Start transaction
-	tx := s.db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
-	// Deduct currency
-	if err := s.playerService.UpdatePlayerCurrency(playerID, -cost); err != nil {
-		tx.Rollback()
-		return nil, err
-	}
-
-	// Update vehicle upgrades
+	// Deduct currency, apply the upgrade and audit it together
+	upgradesBefore := ownedVehicle.Upgrades
 	s.incrementUpgradeLevel(&ownedVehicle.Upgrades, req.UpgradeType)
 
-	if err := tx.Save(&ownedVehicle).Error; err != nil {
-		tx.Rollback()
-		return nil, fmt.Errorf("failed to update vehicle: %w", err)
-	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		before, after, err := changeCurrency(tx, playerID, -cost,
+			"vehicle_upgrade", "vehicle:"+strconv.FormatUint(uint64(ownedVehicle.ID), 10))
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Save(&ownedVehicle).Error; err != nil {
+			return fmt.Errorf("failed to update vehicle: %w", err)
+		}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return recordAudit(tx, AuditEntry{
+			Action:     models.AuditActionVehicleUpgraded,
+			ActorID:    &playerID,
+			TargetType: "player",
+			TargetID:   strconv.FormatUint(uint64(playerID), 10),
+			Before:     map[string]interface{}{"currency": before, "upgrades": upgradesBefore},
+			After:      map[string]interface{}{"currency": after, "upgrades": ownedVehicle.Upgrades},
+			Details: map[string]interface{}{
+				"vehicle_id":   ownedVehicle.ID,
+				"upgrade_type": req.UpgradeType,
+				"cost":         cost,
+			},
+			RequestID: req.RequestID,
+			IPAddress: req.IPAddress,
+		})
+	})
+	if err != nil {
+		return nil, err
 	}
+	metrics.CurrencySpent(string(models.CurrencySoft), "vehicle_upgrade", cost)
 
 	// Return updated vehicle response
 	response := &VehicleResponse{
@@ -1,48 +1,41 @@
 package services
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/auth"
 	"zombie-car-game-backend/internal/models"
 )
 
 func setupVehicleTestDB(t *testing.T) *gorm.DB {
-	// Skip tests if CGO is not available
-	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
-	if err != nil {
-		t.Skip("SQLite requires CGO, skipping database tests")
-		return nil
-	}
-
-	// Auto migrate the schema
-	err = db.AutoMigrate(&models.Player{}, &models.OwnedVehicle{}, &models.GameSession{}, &models.LevelProgress{})
-	if err != nil {
-		t.Fatalf("Failed to migrate database: %v", err)
-	}
-
-	return db
+	return openTestDB(t, &models.Player{}, &models.OwnedVehicle{}, &models.GameSession{}, &models.LevelProgress{}, &models.AuditLog{}, &models.WalletTransaction{})
 }
 
 func createTestPlayerForVehicle(t *testing.T, db *gorm.DB, currency int, level int) *models.Player {
+	// Usernames are unique, so each player created in a test gets its own
+	var existing int64
+	require.NoError(t, db.Unscoped().Model(&models.Player{}).Count(&existing).Error)
+	username := fmt.Sprintf("testplayer%d", existing+1)
+
 	player := &models.Player{
-		Username:     "testplayer",
-		Email:        "test@example.com",
+		Username:     username,
+		Email:        username + "@example.com",
 		PasswordHash: "hashedpassword",
 		Currency:     currency,
 		Level:        level,
 		TotalScore:   0,
 	}
-	db.Create(player)
+	require.NoError(t, db.Create(player).Error)
 	return player
 }
 
 func TestVehicleService_GetAvailableVehicles(t *testing.T) {
 	db := setupVehicleTestDB(t)
-	playerService := NewPlayerService(db)
+	playerService := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 	vehicleService := NewVehicleService(db, playerService)
 
 	vehicles := vehicleService.GetAvailableVehicles()
@@ -62,7 +55,7 @@ func TestVehicleService_GetAvailableVehicles(t *testing.T) {
 
 func TestVehicleService_PurchaseVehicle(t *testing.T) {
 	db := setupVehicleTestDB(t)
-	playerService := NewPlayerService(db)
+	playerService := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 	vehicleService := NewVehicleService(db, playerService)
 
 	t.Run("successful vehicle purchase", func(t *testing.T) {
@@ -84,6 +77,15 @@ func TestVehicleService_PurchaseVehicle(t *testing.T) {
 		updatedPlayer, err := playerService.GetPlayer(player.ID)
 		require.NoError(t, err)
 		assert.Equal(t, 500, updatedPlayer.Currency) // 2000 - 1500
+
+		// Check that the purchase is in the wallet ledger
+		var entry models.WalletTransaction
+		require.NoError(t, db.Where("player_id = ?", player.ID).First(&entry).Error)
+		assert.Equal(t, models.CurrencySoft, entry.Currency)
+		assert.Equal(t, -1500, entry.Amount)
+		assert.Equal(t, 500, entry.BalanceAfter)
+		assert.Equal(t, "vehicle_purchase", entry.Reason)
+		assert.Equal(t, "suv", entry.Reference)
 	})
 
 	t.Run("purchase invalid vehicle type", func(t *testing.T) {
@@ -168,7 +170,7 @@ func TestVehicleService_PurchaseVehicle(t *testing.T) {
 
 func TestVehicleService_GetPlayerVehicles(t *testing.T) {
 	db := setupVehicleTestDB(t)
-	playerService := NewPlayerService(db)
+	playerService := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 	vehicleService := NewVehicleService(db, playerService)
 
 	player := createTestPlayerForVehicle(t, db, 5000, 5)
@@ -211,7 +213,7 @@ func TestVehicleService_GetPlayerVehicles(t *testing.T) {
 
 func TestVehicleService_UpgradeVehicle(t *testing.T) {
 	db := setupVehicleTestDB(t)
-	playerService := NewPlayerService(db)
+	playerService := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 	vehicleService := NewVehicleService(db, playerService)
 
 	player := createTestPlayerForVehicle(t, db, 5000, 5)
@@ -243,6 +245,14 @@ func TestVehicleService_UpgradeVehicle(t *testing.T) {
 		require.NoError(t, err)
 		expectedCurrency := 5000 - 100 // Cost of first engine upgrade for sedan
 		assert.Equal(t, expectedCurrency, updatedPlayer.Currency)
+
+		// Check that the upgrade is in the wallet ledger; the free sedan is not
+		var entries []models.WalletTransaction
+		require.NoError(t, db.Where("player_id = ?", player.ID).Find(&entries).Error)
+		require.Len(t, entries, 1)
+		assert.Equal(t, -100, entries[0].Amount)
+		assert.Equal(t, expectedCurrency, entries[0].BalanceAfter)
+		assert.Equal(t, "vehicle_upgrade", entries[0].Reason)
 	})
 
 	t.Run("upgrade non-owned vehicle", func(t *testing.T) {
@@ -366,7 +376,7 @@ func TestVehicleService_UpgradeVehicle(t *testing.T) {
 
 func TestVehicleService_GetVehicle(t *testing.T) {
 	db := setupVehicleTestDB(t)
-	playerService := NewPlayerService(db)
+	playerService := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 	vehicleService := NewVehicleService(db, playerService)
 
 	player := createTestPlayerForVehicle(t, db, 5000, 5)
@@ -398,7 +408,7 @@ func TestVehicleService_GetVehicle(t *testing.T) {
 
 func TestVehicleService_CalculateCurrentStats(t *testing.T) {
 	db := setupVehicleTestDB(t)
-	playerService := NewPlayerService(db)
+	playerService := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 	vehicleService := NewVehicleService(db, playerService)
 
 	baseStats := VehicleStats{
@@ -439,7 +449,7 @@ func TestVehicleService_CalculateCurrentStats(t *testing.T) {
 
 func TestVehicleService_CalculateUpgradeCosts(t *testing.T) {
 	db := setupVehicleTestDB(t)
-	playerService := NewPlayerService(db)
+	playerService := NewPlayerService(db, auth.NewJWTService(), testAccountConfig)
 	vehicleService := NewVehicleService(db, playerService)
 
 	config := vehicleConfigs["sedan"]
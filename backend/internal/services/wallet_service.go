@@ -0,0 +1,106 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"zombie-car-game-backend/internal/models"
+)
+
+var (
+	ErrInvalidCurrency = errors.New("invalid currency type")
+)
+
+const walletHistoryLimit = 50
+
+// WalletService exposes player balances and the wallet ledger
+type WalletService struct {
+	db *gorm.DB
+}
+
+// NewWalletService creates a new wallet service
+func NewWalletService(db *gorm.DB) *WalletService {
+	return &WalletService{
+		db: db,
+	}
+}
+
+// Wallet represents a player's balances and recent ledger entries
+type Wallet struct {
+	Currency        int                        `json:"currency"`
+	PremiumCurrency int                        `json:"premium_currency"`
+	Transactions    []models.WalletTransaction `json:"transactions"`
+}
+
+// GetWallet retrieves a player's balances with the most recent ledger entries
+func (s *WalletService) GetWallet(playerID uint) (*Wallet, error) {
+	var player models.Player
+	if err := s.db.Select("id", "currency", "premium_currency").First(&player, playerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPlayerNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	var transactions []models.WalletTransaction
+	if err := s.db.Where("player_id = ?", playerID).
+		Order("created_at DESC, id DESC").
+		Limit(walletHistoryLimit).
+		Find(&transactions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get wallet transactions: %w", err)
+	}
+
+	return &Wallet{
+		Currency:        player.Currency,
+		PremiumCurrency: player.PremiumCurrency,
+		Transactions:    transactions,
+	}, nil
+}
+
+// applyWalletChange adjusts a balance inside tx and appends the matching ledger entry.
+// It returns the balance after the change.
+func applyWalletChange(tx *gorm.DB, playerID uint, currency models.CurrencyType, amount int, reason, reference string) (int, error) {
+	var column string
+	switch currency {
+	case models.CurrencySoft:
+		column = "currency"
+	case models.CurrencyPremium:
+		column = "premium_currency"
+	default:
+		return 0, ErrInvalidCurrency
+	}
+
+	result := tx.Model(&models.Player{}).Where("id = ?", playerID).
+		Update(column, gorm.Expr(column+" + ?", amount))
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to update %s: %w", column, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return 0, ErrPlayerNotFound
+	}
+
+	var player models.Player
+	if err := tx.Select("id", column).First(&player, playerID).Error; err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+
+	balance := player.Currency
+	if currency == models.CurrencyPremium {
+		balance = player.PremiumCurrency
+	}
+
+	entry := models.WalletTransaction{
+		PlayerID:     playerID,
+		Currency:     currency,
+		Amount:       amount,
+		BalanceAfter: balance,
+		Reason:       reason,
+		Reference:    reference,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return 0, fmt.Errorf("failed to record wallet transaction: %w", err)
+	}
+
+	return balance, nil
+}
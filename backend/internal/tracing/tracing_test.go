@@ -0,0 +1,111 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	// Setup with no exporter still installs the W3C propagators
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterNone})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+	return recorder
+}
+
+func TestTracing_PropagatesThroughRequestAndQueries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := setupRecorder(t)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, InstrumentGORM(db))
+
+	r := gin.New()
+	r.Use(Middleware("test"))
+	r.GET("/players/:id", func(c *gin.Context) {
+		ctx, span := Start(c.Request.Context(), "PlayerService.GetPlayer")
+		defer span.End()
+
+		var one int
+		db.WithContext(ctx).Raw("SELECT 1").Scan(&one)
+		db.WithContext(ctx).Exec("SELECT * FROM missing_table")
+		c.Status(http.StatusOK)
+	})
+	r.GET("/health", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req, _ := http.NewRequest("GET", "/players/7", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	req, _ = http.NewRequest("GET", "/health", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+	byName := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range spans {
+		// Every span continues the caller's trace
+		assert.Equal(t, traceID, span.SpanContext().TraceID().String())
+		byName[span.Name()] = span
+	}
+
+	server := byName["/players/:id"]
+	service := byName["PlayerService.GetPlayer"]
+	require.NotNil(t, server)
+	require.NotNil(t, service)
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Equal(t, server.SpanContext().SpanID(), service.Parent().SpanID())
+
+	var queries []sdktrace.ReadOnlySpan
+	for _, span := range spans {
+		if span.Parent().SpanID() == service.SpanContext().SpanID() {
+			queries = append(queries, span)
+		}
+	}
+	require.Len(t, queries, 2)
+	attrs := map[string]string{}
+	for _, attr := range queries[0].Attributes() {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	assert.Equal(t, "sqlite", attrs["db.system"])
+	assert.Equal(t, "SELECT 1", attrs["db.statement"])
+	assert.Equal(t, "Error", queries[1].Status().Code.String())
+}
+
+func TestSetup_FileExporter(t *testing.T) {
+	path := t.TempDir() + "/traces.jsonl"
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterFile, File: path, ServiceName: "test", SampleRatio: 1})
+	require.NoError(t, err)
+	_, span := Start(context.Background(), "offline")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), `"Name":"offline"`)
+}
@@ -1,114 +1,221 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"zombie-car-game-backend/internal/auth"
 	"zombie-car-game-backend/internal/cache"
+	"zombie-car-game-backend/internal/config"
 	"zombie-car-game-backend/internal/database"
+	"zombie-car-game-backend/internal/health"
+	"zombie-car-game-backend/internal/lifecycle"
+	"zombie-car-game-backend/internal/logging"
+	"zombie-car-game-backend/internal/mail"
+	"zombie-car-game-backend/internal/metrics"
+	"zombie-car-game-backend/internal/middleware"
+	"zombie-car-game-backend/internal/offline"
 	"zombie-car-game-backend/internal/routes"
+	"zombie-car-game-backend/internal/services"
+	"zombie-car-game-backend/internal/tracing"
+	"zombie-car-game-backend/migrations"
 )
 
 func main() {
 	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found")
+	envErr := godotenv.Load()
+
+	// Defaults, then CONFIG_FILE, then the environment; refuse to start with
+	// anything invalid rather than falling back to defaults
+	cfg, err := config.Load(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		fatal("Invalid configuration", err)
+	}
+
+	// Structured logs go to stdout, where the log shipper picks them up
+	logging.Setup(cfg.LoggingConfig(), os.Stdout)
+	if envErr != nil {
+		slog.Info("No .env file found")
 	}
 
-	// Set Gin mode
-	if os.Getenv("GIN_MODE") == "release" {
-		gin.SetMode(gin.ReleaseMode)
+	gin.SetMode(cfg.Server.Mode)
+
+	// Refuse to start with a broken or insecure signing key configuration
+	keys, err := auth.LoadKeySet(cfg.KeyConfig())
+	if err == nil {
+		err = auth.CheckKeySet(keys, cfg.ReleaseMode())
+	}
+	if err != nil {
+		fatal("Invalid JWT key configuration", err)
+	}
+
+	// Tracing is set up before any client is created so every client is instrumented
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TracingConfig())
+	if err != nil {
+		fatal("Invalid tracing configuration", err)
 	}
 
 	// Initialize database connection
-	if err := database.Connect(); err != nil {
-		log.Fatal("Failed to connect to database:", err)
+	if err := database.Connect(cfg.DatabaseConfig()); err != nil {
+		fatal("Failed to connect to database", err)
+	}
+	if err := tracing.InstrumentGORM(database.GetDB()); err != nil {
+		slog.Warn("Failed to instrument database for tracing", "error", err)
 	}
-	defer database.Close()
 
-	// Run database migrations
+	// Apply the SQL migrations, then let AutoMigrate add what the models need on
+	// top. The migrations are written for Postgres; SQLite gets the models' schema.
+	if database.GetDB().Dialector.Name() == "postgres" {
+		if _, err := database.NewMigrator(database.GetDB(), migrations.Files).Up(); err != nil {
+			fatal("Failed to apply SQL migrations", err)
+		}
+	}
 	if err := database.AutoMigrate(); err != nil {
-		log.Fatal("Failed to run database migrations:", err)
+		fatal("Failed to run database migrations", err)
+	}
+
+	// Initialize Redis connection; the server embedded in the desktop build has
+	// a single player and does without
+	if cfg.Offline.Enabled {
+		slog.Info("Offline mode, running without Redis")
+	} else if err := cache.Connect(cfg.CacheConfig()); err != nil {
+		slog.Warn("Failed to connect to Redis, continuing without Redis cache", "error", err)
+	}
+	if redisClient := cache.GetClient(); redisClient != nil {
+		metrics.InstrumentRedis(redisClient)
+		tracing.InstrumentRedis(redisClient)
+	}
+	// Revoked tokens are shared through Redis when it is up, otherwise they only
+	// reach this instance. Every service uses this one JWT service, so they all
+	// see each other's revocations.
+	jwtService := auth.NewJWTServiceWithKeys(keys, cfg.Auth.AccessTokenTTL, auth.NewDenylist(cache.GetClient()))
+	if err := metrics.RegisterDatabase(database.GetDB()); err != nil {
+		slog.Warn("Failed to register database metrics", "error", err)
 	}
 
-	// Initialize Redis connection
-	if err := cache.Connect(); err != nil {
-		log.Println("Warning: Failed to connect to Redis:", err)
-		log.Println("Continuing without Redis cache...")
-	} else {
-		defer cache.Close()
+	// Background workers are stopped in reverse order, so the mail outbox stops
+	// last and still delivers anything queued by the others
+	mailSender, err := mail.NewSender(cfg.MailConfig())
+	if err != nil {
+		fatal("Invalid mail configuration", err)
 	}
+	var workers lifecycle.Workers
+	workers.Go("mail_outbox", func(ctx context.Context) {
+		services.NewMailOutbox(database.GetDB(), mailSender).Run(ctx, 10*time.Second)
+	})
+	workers.Go("guest_cleanup", func(ctx context.Context) {
+		services.NewGuestCleanup(database.GetDB(), cfg.Retention.GuestInactivity).Run(ctx, time.Hour)
+	})
+	workers.Go("account_purge", func(ctx context.Context) {
+		services.NewPrivacyService(database.GetDB(), jwtService, cfg.Retention.DeletionGracePeriod).Run(ctx, time.Hour)
+	})
+	workers.Go("audit_retention", func(ctx context.Context) {
+		services.NewAuditService(database.GetDB(), cfg.Retention.Audit).Run(ctx, 24*time.Hour)
+	})
 
 	// Initialize router
-	r := gin.Default()
-
-	// Basic health check endpoint
-	r.GET("/health", func(c *gin.Context) {
-		// Check database connection
-		db := database.GetDB()
-		sqlDB, err := db.DB()
-		dbStatus := "ok"
-		if err != nil || sqlDB.Ping() != nil {
-			dbStatus = "error"
-		}
+	r := gin.New()
+	// The client IP keys rate limits and login lockouts, so X-Forwarded-For is
+	// only believed from the configured load balancers
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		fatal("Invalid trusted proxies", err)
+	}
+	// Every request gets an ID that shows up in its logs and audit entries
+	r.Use(middleware.RequestID(), tracing.Middleware(cfg.Tracing.ServiceName))
+	r.Use(middleware.RequestLogger(), middleware.Recovery())
+	r.Use(metrics.Middleware())
 
-		// Check Redis connection
-		redisStatus := "ok"
-		if redisClient := cache.GetClient(); redisClient != nil {
-			if _, err := redisClient.Ping(c.Request.Context()).Result(); err != nil {
-				redisStatus = "error"
-			}
-		} else {
-			redisStatus = "not_connected"
-		}
+	// Prometheus scrape endpoint
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
 
-		c.JSON(200, gin.H{
-			"status":   "ok",
-			"message":  "Zombie Car Game Backend is running",
-			"database": dbStatus,
-			"redis":    redisStatus,
-		})
-	})
+	// Liveness and readiness probes. /health predates them and is kept for
+	// existing health checks; it answers like /readyz.
+	checker := health.NewChecker(health.DefaultCacheTTL,
+		health.DatabaseCheck(database.GetDB()),
+		health.RedisCheck(cache.GetClient()),
+	)
+	r.GET("/livez", health.LivezHandler)
+	r.GET("/readyz", health.ReadyzHandler(checker))
+	r.GET("/health", health.ReadyzHandler(checker))
 
 	// Setup API routes
 	setupStatusRoutes(r)
-	routes.SetupRoutes(r, database.GetDB())
+	routes.SetupRoutes(r, database.GetDB(), cfg, jwtService)
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// Offline, the built frontend is served alongside the API
+	if cfg.Offline.Enabled && cfg.Offline.StaticDir != "" {
+		r.NoRoute(offline.Frontend(cfg.Offline.StaticDir))
 	}
 
+	serverConfig := cfg.ServerConfig()
+	server := lifecycle.NewServer(cfg.Addr(), r, serverConfig)
+
 	// Set up graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
-		log.Printf("Server starting on port %s", port)
-		if err := r.Run(":" + port); err != nil {
-			log.Fatal("Failed to start server:", err)
+		slog.Info("Server starting", "addr", cfg.Addr(), "offline", cfg.Offline.Enabled)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal("Failed to start server", err)
 		}
 	}()
 
 	// Wait for interrupt signal to gracefully shutdown
 	<-quit
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server")
+
+	// Fail readiness first and keep serving while load balancers notice
+	checker.SetShuttingDown()
+	time.Sleep(serverConfig.DrainDelay)
+
+	// Stop accepting connections and let in-flight requests finish
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), serverConfig.ShutdownTimeout)
+	defer cancelShutdown()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Timed out draining requests, closing remaining connections", "error", err)
+		server.Close()
+	}
+
+	// Then stop the workers, before the connections they use are closed
+	workersCtx, cancelWorkers := context.WithTimeout(context.Background(), serverConfig.ShutdownTimeout)
+	defer cancelWorkers()
+	if err := workers.Stop(workersCtx); err != nil {
+		slog.Error("Error stopping background workers", "error", err)
+	}
 
 	// Close database connection
 	if err := database.Close(); err != nil {
-		log.Printf("Error closing database: %v", err)
+		slog.Error("Error closing database", "error", err)
 	}
 
 	// Close Redis connection
 	if err := cache.Close(); err != nil {
-		log.Printf("Error closing Redis: %v", err)
+		slog.Error("Error closing Redis", "error", err)
 	}
 
-	log.Println("Server shutdown complete")
+	// Flush spans still buffered for export
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFlush()
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Error("Error flushing traces", "error", err)
+	}
+
+	slog.Info("Server shutdown complete")
+}
+
+// fatal logs a startup failure and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
 
 // setupStatusRoutes sets up health check and status endpoints
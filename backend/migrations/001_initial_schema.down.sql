@@ -0,0 +1,16 @@
+-- Drops the initial schema and every row in it
+
+DROP VIEW IF EXISTS global_leaderboard;
+DROP VIEW IF EXISTS player_summary;
+
+DROP TRIGGER IF EXISTS update_players_updated_at ON players;
+DROP FUNCTION IF EXISTS update_updated_at_column();
+
+-- Tables created for the models, such as sanctions, keep their rows but lose
+-- their foreign keys to these
+DROP TABLE IF EXISTS player_statistics;
+DROP TABLE IF EXISTS leaderboard;
+DROP TABLE IF EXISTS level_progress;
+DROP TABLE IF EXISTS game_sessions CASCADE;
+DROP TABLE IF EXISTS owned_vehicles;
+DROP TABLE IF EXISTS players CASCADE;
@@ -13,10 +13,10 @@ CREATE TABLE IF NOT EXISTS players (
 );
 
 -- Indexes for players table
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_players_username ON players(username);
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_players_email ON players(email);
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_players_level ON players(level);
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_players_created_at ON players(created_at);
+CREATE INDEX IF NOT EXISTS idx_players_username ON players(username);
+CREATE INDEX IF NOT EXISTS idx_players_email ON players(email);
+CREATE INDEX IF NOT EXISTS idx_players_level ON players(level);
+CREATE INDEX IF NOT EXISTS idx_players_created_at ON players(created_at);
 
 -- Owned vehicles table
 CREATE TABLE IF NOT EXISTS owned_vehicles (
@@ -28,9 +28,9 @@ CREATE TABLE IF NOT EXISTS owned_vehicles (
 );
 
 -- Indexes for owned_vehicles table
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_owned_vehicles_player_id ON owned_vehicles(player_id);
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_owned_vehicles_type ON owned_vehicles(vehicle_type);
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_owned_vehicles_upgrades ON owned_vehicles USING GIN(upgrades);
+CREATE INDEX IF NOT EXISTS idx_owned_vehicles_player_id ON owned_vehicles(player_id);
+CREATE INDEX IF NOT EXISTS idx_owned_vehicles_type ON owned_vehicles(vehicle_type);
+CREATE INDEX IF NOT EXISTS idx_owned_vehicles_upgrades ON owned_vehicles USING GIN(upgrades);
 
 -- Game sessions table
 CREATE TABLE IF NOT EXISTS game_sessions (
@@ -46,11 +46,11 @@ CREATE TABLE IF NOT EXISTS game_sessions (
 );
 
 -- Indexes for game_sessions table
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_game_sessions_player_id ON game_sessions(player_id);
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_game_sessions_level_id ON game_sessions(level_id);
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_game_sessions_state ON game_sessions(session_state);
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_game_sessions_started_at ON game_sessions(started_at);
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_game_sessions_score ON game_sessions(score DESC);
+CREATE INDEX IF NOT EXISTS idx_game_sessions_player_id ON game_sessions(player_id);
+CREATE INDEX IF NOT EXISTS idx_game_sessions_level_id ON game_sessions(level_id);
+CREATE INDEX IF NOT EXISTS idx_game_sessions_state ON game_sessions(session_state);
+CREATE INDEX IF NOT EXISTS idx_game_sessions_started_at ON game_sessions(started_at);
+CREATE INDEX IF NOT EXISTS idx_game_sessions_score ON game_sessions(score DESC);
 
 -- Level progress table
 CREATE TABLE IF NOT EXISTS level_progress (
@@ -65,10 +65,10 @@ CREATE TABLE IF NOT EXISTS level_progress (
 );
 
 -- Indexes for level_progress table
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_level_progress_player_id ON level_progress(player_id);
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_level_progress_level_id ON level_progress(level_id);
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_level_progress_completed ON level_progress(completed);
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_level_progress_best_score ON level_progress(best_score DESC);
+CREATE INDEX IF NOT EXISTS idx_level_progress_player_id ON level_progress(player_id);
+CREATE INDEX IF NOT EXISTS idx_level_progress_level_id ON level_progress(level_id);
+CREATE INDEX IF NOT EXISTS idx_level_progress_completed ON level_progress(completed);
+CREATE INDEX IF NOT EXISTS idx_level_progress_best_score ON level_progress(best_score DESC);
 
 -- Leaderboard table for high scores
 CREATE TABLE IF NOT EXISTS leaderboard (
@@ -81,9 +81,9 @@ CREATE TABLE IF NOT EXISTS leaderboard (
 );
 
 -- Indexes for leaderboard table
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_leaderboard_level_score ON leaderboard(level_id, score DESC);
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_leaderboard_player_id ON leaderboard(player_id);
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_leaderboard_achieved_at ON leaderboard(achieved_at);
+CREATE INDEX IF NOT EXISTS idx_leaderboard_level_score ON leaderboard(level_id, score DESC);
+CREATE INDEX IF NOT EXISTS idx_leaderboard_player_id ON leaderboard(player_id);
+CREATE INDEX IF NOT EXISTS idx_leaderboard_achieved_at ON leaderboard(achieved_at);
 
 -- Player statistics table
 CREATE TABLE IF NOT EXISTS player_statistics (
@@ -98,8 +98,8 @@ CREATE TABLE IF NOT EXISTS player_statistics (
 );
 
 -- Indexes for player_statistics table
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_player_statistics_player_id ON player_statistics(player_id);
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_player_statistics_last_played ON player_statistics(last_played);
+CREATE INDEX IF NOT EXISTS idx_player_statistics_player_id ON player_statistics(player_id);
+CREATE INDEX IF NOT EXISTS idx_player_statistics_last_played ON player_statistics(last_played);
 
 -- Function to update updated_at timestamp
 CREATE OR REPLACE FUNCTION update_updated_at_column()
@@ -111,6 +111,7 @@ END;
 $$ language 'plpgsql';
 
 -- Trigger for players table
+DROP TRIGGER IF EXISTS update_players_updated_at ON players;
 CREATE TRIGGER update_players_updated_at BEFORE UPDATE ON players
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
@@ -0,0 +1,20 @@
+-- Reverts the performance optimizations
+
+ALTER TABLE level_progress ALTER COLUMN best_score SET STATISTICS -1;
+ALTER TABLE game_sessions ALTER COLUMN score SET STATISTICS -1;
+ALTER TABLE players ALTER COLUMN total_score SET STATISTICS -1;
+ALTER TABLE players ALTER COLUMN level SET STATISTICS -1;
+
+ALTER TABLE game_sessions RESET (autovacuum_vacuum_scale_factor, autovacuum_analyze_scale_factor);
+ALTER TABLE players RESET (autovacuum_vacuum_scale_factor, autovacuum_analyze_scale_factor);
+
+DROP FUNCTION IF EXISTS create_monthly_partition(text, date);
+DROP TABLE IF EXISTS game_sessions_template;
+
+DROP FUNCTION IF EXISTS refresh_leaderboard();
+DROP MATERIALIZED VIEW IF EXISTS top_players_by_level;
+
+DROP INDEX IF EXISTS idx_game_sessions_recent;
+DROP INDEX IF EXISTS idx_level_progress_player_completed;
+DROP INDEX IF EXISTS idx_players_level_score;
+DROP INDEX IF EXISTS idx_game_sessions_active;
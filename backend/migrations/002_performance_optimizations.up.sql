@@ -1,24 +1,20 @@
 -- Performance optimizations and additional indexes
 
--- Enable pg_stat_statements extension for query performance monitoring
-CREATE EXTENSION IF NOT EXISTS pg_stat_statements;
-
 -- Partial indexes for active sessions
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_game_sessions_active 
+CREATE INDEX IF NOT EXISTS idx_game_sessions_active 
 ON game_sessions(player_id, started_at) 
 WHERE session_state = 'active';
 
 -- Composite indexes for common query patterns
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_players_level_score 
+CREATE INDEX IF NOT EXISTS idx_players_level_score 
 ON players(level, total_score DESC);
 
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_level_progress_player_completed 
+CREATE INDEX IF NOT EXISTS idx_level_progress_player_completed 
 ON level_progress(player_id, completed, best_score DESC);
 
--- Index for recent game sessions
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_game_sessions_recent 
-ON game_sessions(player_id, started_at DESC) 
-WHERE started_at > NOW() - INTERVAL '30 days';
+-- Index for a player's recent game sessions
+CREATE INDEX IF NOT EXISTS idx_game_sessions_recent
+ON game_sessions(player_id, started_at DESC);
 
 -- Materialized view for leaderboard performance
 CREATE MATERIALIZED VIEW IF NOT EXISTS top_players_by_level AS
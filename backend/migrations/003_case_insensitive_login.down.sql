@@ -0,0 +1,4 @@
+-- Drops the case-insensitive login indexes
+
+DROP INDEX IF EXISTS idx_players_email_lower;
+DROP INDEX IF EXISTS idx_players_username_lower;
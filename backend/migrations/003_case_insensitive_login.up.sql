@@ -0,0 +1,8 @@
+-- Case-insensitive login by username or email
+
+-- Login and registration match LOWER(username) / LOWER(email)
+CREATE INDEX IF NOT EXISTS idx_players_username_lower
+ON players(LOWER(username));
+
+CREATE INDEX IF NOT EXISTS idx_players_email_lower
+ON players(LOWER(email));
@@ -0,0 +1,33 @@
+-- Puts sanctioned players back on the leaderboards. The sanctions table itself
+-- belongs to models.Sanction and is kept.
+
+DROP MATERIALIZED VIEW IF EXISTS top_players_by_level;
+
+CREATE MATERIALIZED VIEW top_players_by_level AS
+SELECT
+    level_id,
+    player_id,
+    username,
+    score,
+    achieved_at,
+    ROW_NUMBER() OVER (PARTITION BY level_id ORDER BY score DESC) as rank
+FROM leaderboard l
+JOIN players p ON l.player_id = p.id
+WHERE score > 0;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_top_players_by_level_unique
+ON top_players_by_level(level_id, rank);
+
+CREATE OR REPLACE VIEW global_leaderboard AS
+SELECT
+    p.username,
+    l.level_id,
+    l.score,
+    l.achieved_at,
+    ROW_NUMBER() OVER (PARTITION BY l.level_id ORDER BY l.score DESC) as rank
+FROM leaderboard l
+JOIN players p ON l.player_id = p.id
+ORDER BY l.level_id, l.score DESC;
+
+DROP VIEW IF EXISTS leaderboard_hidden_players;
+DROP INDEX IF EXISTS idx_sanctions_active;
@@ -0,0 +1,69 @@
+-- Player sanctions and leaderboard exclusion
+
+-- Mirrors models.Sanction so the leaderboard views below can reference it
+-- before the application's auto migration has run
+CREATE TABLE IF NOT EXISTS sanctions (
+    id BIGSERIAL PRIMARY KEY,
+    player_id BIGINT NOT NULL REFERENCES players(id),
+    type VARCHAR(32) NOT NULL,
+    reason VARCHAR(500) NOT NULL,
+    issued_by BIGINT,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    lifted_at TIMESTAMP WITH TIME ZONE,
+    lifted_by BIGINT,
+    lift_reason VARCHAR(500),
+    appeal_message TEXT,
+    appeal_status VARCHAR(20),
+    appealed_at TIMESTAMP WITH TIME ZONE,
+    appeal_resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sanctions_player_id ON sanctions(player_id);
+CREATE INDEX IF NOT EXISTS idx_sanctions_type ON sanctions(type);
+CREATE INDEX IF NOT EXISTS idx_sanctions_expires_at ON sanctions(expires_at);
+CREATE INDEX IF NOT EXISTS idx_sanctions_appeal_status ON sanctions(appeal_status);
+
+-- Active sanctions that hide a player from leaderboards
+CREATE INDEX IF NOT EXISTS idx_sanctions_active
+ON sanctions(player_id)
+WHERE lifted_at IS NULL;
+
+CREATE OR REPLACE VIEW leaderboard_hidden_players AS
+SELECT DISTINCT player_id
+FROM sanctions
+WHERE type IN ('ban', 'suspension', 'leaderboard_exclusion')
+  AND lifted_at IS NULL
+  AND (expires_at IS NULL OR expires_at > NOW());
+
+-- Global leaderboard view without sanctioned players
+CREATE OR REPLACE VIEW global_leaderboard AS
+SELECT
+    p.username,
+    l.level_id,
+    l.score,
+    l.achieved_at,
+    ROW_NUMBER() OVER (PARTITION BY l.level_id ORDER BY l.score DESC) as rank
+FROM leaderboard l
+JOIN players p ON l.player_id = p.id
+WHERE l.player_id NOT IN (SELECT player_id FROM leaderboard_hidden_players)
+ORDER BY l.level_id, l.score DESC;
+
+-- Materialized leaderboard without sanctioned players; refresh_leaderboard() keeps working
+DROP MATERIALIZED VIEW IF EXISTS top_players_by_level;
+
+CREATE MATERIALIZED VIEW top_players_by_level AS
+SELECT
+    level_id,
+    player_id,
+    username,
+    score,
+    achieved_at,
+    ROW_NUMBER() OVER (PARTITION BY level_id ORDER BY score DESC) as rank
+FROM leaderboard l
+JOIN players p ON l.player_id = p.id
+WHERE score > 0
+  AND l.player_id NOT IN (SELECT player_id FROM leaderboard_hidden_players);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_top_players_by_level_unique
+ON top_players_by_level(level_id, rank);
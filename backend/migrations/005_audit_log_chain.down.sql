@@ -0,0 +1,5 @@
+-- Allows audit log entries to be modified again. The hash chain columns belong
+-- to models.AuditLog and are kept.
+
+DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs;
+DROP FUNCTION IF EXISTS reject_audit_log_update();
@@ -0,0 +1,6 @@
+-- Stops new sessions being abandoned. Existing abandoned sessions are kept, so
+-- the narrower check is not validated against them.
+
+ALTER TABLE game_sessions DROP CONSTRAINT IF EXISTS game_sessions_session_state_check;
+ALTER TABLE game_sessions ADD CONSTRAINT game_sessions_session_state_check
+CHECK (session_state IN ('active', 'completed', 'failed')) NOT VALID;
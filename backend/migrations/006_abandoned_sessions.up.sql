@@ -0,0 +1,5 @@
+-- Sessions left unfinished are closed as abandoned
+
+ALTER TABLE game_sessions DROP CONSTRAINT IF EXISTS game_sessions_session_state_check;
+ALTER TABLE game_sessions ADD CONSTRAINT game_sessions_session_state_check
+CHECK (session_state IN ('active', 'completed', 'failed', 'abandoned'));
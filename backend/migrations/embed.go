@@ -0,0 +1,15 @@
+// Package migrations holds the SQL schema migrations, embedded in the server
+// binary so they are applied without shipping the files alongside it.
+//
+// Each migration is a NNN_name.up.sql file with a matching NNN_name.down.sql
+// that undoes it. Both run inside a transaction, so statements that cannot,
+// such as CREATE INDEX CONCURRENTLY, are not allowed. Never edit a migration
+// once it has been applied anywhere; the runner rejects changed checksums.
+package migrations
+
+import "embed"
+
+// Files holds the migration files
+//
+//go:embed *.sql
+var Files embed.FS
@@ -1,5 +1,9 @@
 const { app, BrowserWindow, Menu, ipcMain, dialog, shell } = require('electron');
 const path = require('path');
+const fs = require('fs');
+const http = require('http');
+const net = require('net');
+const { spawn } = require('child_process');
 const isDev = process.env.NODE_ENV === 'development';
 
 // Import database manager for main process
@@ -13,6 +17,116 @@ try {
 // Keep a global reference of the window object
 let mainWindow;
 
+// The embedded game server and the URL it serves the app and API on
+let localServer = null;
+let localServerURL = null;
+
+/**
+ * Path of the game server bundled with the app
+ */
+function localServerBinary() {
+    const name = process.platform === 'win32' ? 'zombie-car-game-server.exe' : 'zombie-car-game-server';
+    return path.join(process.resourcesPath, 'server', name);
+}
+
+/**
+ * Find a free port on the loopback interface
+ */
+function findFreePort() {
+    return new Promise((resolve, reject) => {
+        const probe = net.createServer();
+        probe.unref();
+        probe.on('error', reject);
+        probe.listen(0, '127.0.0.1', () => {
+            const { port } = probe.address();
+            probe.close(() => resolve(port));
+        });
+    });
+}
+
+/**
+ * Poll the server's liveness probe until it answers or the timeout passes
+ */
+function waitForServer(url, timeoutMs) {
+    const deadline = Date.now() + timeoutMs;
+
+    return new Promise((resolve, reject) => {
+        const attempt = () => {
+            const req = http.get(`${url}/livez`, (res) => {
+                res.resume();
+                if (res.statusCode === 200) {
+                    resolve();
+                } else {
+                    retry();
+                }
+            });
+            req.on('error', retry);
+            req.setTimeout(1000, () => req.destroy());
+        };
+        const retry = () => {
+            if (Date.now() > deadline) {
+                reject(new Error('Local game server did not start in time'));
+            } else {
+                setTimeout(attempt, 200);
+            }
+        };
+        attempt();
+    });
+}
+
+/**
+ * Start the bundled game server in offline mode, bound to localhost, keeping its
+ * data in the app's user data directory and serving the built frontend.
+ * Resolves to the server's URL, or null when no server is bundled or it fails
+ * to start, in which case the app falls back to the remote backend.
+ */
+async function startLocalServer() {
+    const binary = localServerBinary();
+    if (isDev || !fs.existsSync(binary)) {
+        return null;
+    }
+
+    try {
+        const port = await findFreePort();
+        const url = `http://127.0.0.1:${port}`;
+
+        localServer = spawn(binary, [], {
+            env: {
+                ...process.env,
+                OFFLINE_MODE: 'true',
+                OFFLINE_DATA_DIR: app.getPath('userData'),
+                OFFLINE_STATIC_DIR: path.join(__dirname, '../dist'),
+                HOST: '127.0.0.1',
+                PORT: String(port),
+                GIN_MODE: 'release'
+            },
+            stdio: 'ignore',
+            windowsHide: true
+        });
+        localServer.on('exit', (code) => {
+            console.warn('Local game server exited with code', code);
+            localServer = null;
+        });
+
+        await waitForServer(url, 15000);
+        return url;
+    } catch (error) {
+        console.error('Failed to start local game server:', error.message);
+        stopLocalServer();
+        return null;
+    }
+}
+
+/**
+ * Stop the bundled game server
+ */
+function stopLocalServer() {
+    if (localServer) {
+        localServer.kill();
+        localServer = null;
+    }
+}
+
 /**
  * Create the main application window
  */
@@ -28,6 +142,7 @@ function createMainWindow() {
             contextIsolation: true,
             enableRemoteModule: false,
             preload: path.join(__dirname, 'preload.js'),
+            additionalArguments: localServerURL ? [`--local-server-url=${localServerURL}`] : [],
             webSecurity: !isDev
         },
         icon: path.join(__dirname, '../assets/icon.png'),
@@ -44,6 +159,8 @@ function createMainWindow() {
         mainWindow.loadURL('http://localhost:3000');
         // Open DevTools in development
         mainWindow.webContents.openDevTools();
+    } else if (localServerURL) {
+        mainWindow.loadURL(localServerURL);
     } else {
         mainWindow.loadFile(path.join(__dirname, '../dist/index.html'));
     }
@@ -73,7 +190,7 @@ function createMainWindow() {
     mainWindow.webContents.on('will-navigate', (event, navigationUrl) => {
         const parsedUrl = new URL(navigationUrl);
         
-        if (parsedUrl.origin !== 'http://localhost:3000' && !isDev) {
+        if (parsedUrl.origin !== 'http://localhost:3000' && parsedUrl.origin !== localServerURL && !isDev) {
             event.preventDefault();
         }
     });
@@ -372,7 +489,8 @@ function setupIPC() {
 }
 
 // App event handlers
-app.whenReady().then(() => {
+app.whenReady().then(async () => {
+    localServerURL = await startLocalServer();
     createMainWindow();
     createMenu();
     setupIPC();
@@ -393,6 +511,11 @@ app.on('window-all-closed', () => {
     }
 });
 
+// Stop the local game server with the app
+app.on('will-quit', () => {
+    stopLocalServer();
+});
+
 // Security: Prevent new window creation
 app.on('web-contents-created', (event, contents) => {
     contents.on('new-window', (event, navigationUrl) => {
@@ -422,7 +545,7 @@ app.on('web-contents-created', (event, contents) => {
             return;
         }
         
-        if (!isDev && !navigationUrl.startsWith('file://')) {
+        if (!isDev && !navigationUrl.startsWith('file://') && parsedUrl.origin !== localServerURL) {
             event.preventDefault();
         }
     });
@@ -42,6 +42,9 @@ contextBridge.exposeInMainWorld('electronAPI', {
     // Platform information
     platform: process.platform,
     
+    // URL of the game server embedded in the app, when it is running
+    localServerURL: (process.argv.find((arg) => arg.startsWith('--local-server-url=')) || '').split('=')[1] || null,
+    
     // Development mode check
     isDev: process.env.NODE_ENV === 'development'
 });
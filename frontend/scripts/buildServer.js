@@ -0,0 +1,23 @@
+/**
+ * Build the Game Server for the Desktop App
+ * Compiles the Go backend into server/, which electron-builder bundles as an
+ * extra resource so the app can run it as an embedded offline server
+ */
+
+const { execFileSync } = require('child_process');
+const path = require('path');
+
+const backendDir = path.join(__dirname, '..', '..', 'backend');
+const binaryName = process.platform === 'win32' ? 'zombie-car-game-server.exe' : 'zombie-car-game-server';
+const output = path.join(__dirname, '..', 'server', binaryName);
+
+console.log(`🔧 Building game server into ${output}...`);
+
+// SQLite is compiled in through cgo, so a C compiler is required
+execFileSync('go', ['build', '-trimpath', '-ldflags', '-s -w', '-o', output, '.'], {
+    cwd: backendDir,
+    env: { ...process.env, CGO_ENABLED: '1' },
+    stdio: 'inherit'
+});
+
+console.log('✅ Game server built');
@@ -202,7 +202,7 @@ export class ZombieCarGame extends React.Component {
         
         // Initialize save system
         // Create real API client for backend connection
-        const apiClient = new ApiClient(process.env.REACT_APP_API_URL || 'http://localhost:8080');
+        const apiClient = new ApiClient(window.electronAPI?.localServerURL || process.env.REACT_APP_API_URL || 'http://localhost:8080');
         
         this.saveManager = new SaveManager(apiClient);
         await this.saveManager.initialize();